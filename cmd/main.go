@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	_ "time/tzdata" // quiet hours resolve user timezones without system zoneinfo
 
 	"github.com/yourusername/online-library/internal/config"
 	"github.com/yourusername/online-library/internal/infrastructure/logger"
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/yourusername/online-library/internal/config"
+	"github.com/yourusername/online-library/internal/infrastructure/db/postgres"
+	"github.com/yourusername/online-library/internal/repository"
+	"github.com/yourusername/online-library/internal/successscore"
+	"go.uber.org/zap"
+)
+
+func main() {
+	fix := flag.Bool("fix", false, "record an adjustment in history for each drifted score")
+	flag.Parse()
+
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("failed to load config:", err)
+	}
+
+	// Initialize logger
+	logger, err := zap.NewProduction()
+	if err != nil {
+		log.Fatal("failed to initialize logger:", err)
+	}
+	defer logger.Sync()
+
+	// Connect to database
+	conn, err := postgres.NewConnection(context.Background(), cfg.Database.ConnectionString())
+	if err != nil {
+		log.Fatal("failed to connect to database:", err)
+	}
+	defer conn.Close()
+
+	scoreRepo := repository.NewSuccessScoreRepository(conn.DB, logger)
+	uow := repository.NewUnitOfWork(conn.DB, logger)
+	successScoreSvc := successscore.NewService(scoreRepo, uow, logger)
+
+	report, err := successScoreSvc.Reconcile(context.Background(), *fix)
+	if err != nil {
+		log.Fatal("reconciliation failed:", err)
+	}
+
+	fmt.Printf("✓ Checked %d users\n", report.UsersChecked)
+	if len(report.Drifted) == 0 {
+		fmt.Println("✓ All success scores match their history")
+		return
+	}
+
+	fmt.Printf("⚠️  %d users have drifted scores:\n", len(report.Drifted))
+	for _, d := range report.Drifted {
+		fmt.Printf("  %-20s stored=%-5d computed=%-5d drift=%+d\n", d.Username, d.StoredScore, d.ComputedScore, d.Drift)
+	}
+
+	if report.Fixed {
+		fmt.Println("✓ Adjustments were recorded so history matches the stored scores")
+	} else {
+		fmt.Println("\nRun again with -fix to correct them.")
+	}
+}
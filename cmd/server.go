@@ -10,15 +10,22 @@ import (
 	"github.com/yourusername/online-library/internal/admin"
 	"github.com/yourusername/online-library/internal/auth"
 	"github.com/yourusername/online-library/internal/book"
+	"github.com/yourusername/online-library/internal/booklifecycle"
 	"github.com/yourusername/online-library/internal/bookmark"
 	"github.com/yourusername/online-library/internal/config"
+	"github.com/yourusername/online-library/internal/content"
 	"github.com/yourusername/online-library/internal/donation"
 	"github.com/yourusername/online-library/internal/handover"
 	"github.com/yourusername/online-library/internal/idea"
 	"github.com/yourusername/online-library/internal/infrastructure/db/postgres"
+	"github.com/yourusername/online-library/internal/infrastructure/notifier"
+	"github.com/yourusername/online-library/internal/infrastructure/payment"
+	"github.com/yourusername/online-library/internal/infrastructure/pdf"
+	"github.com/yourusername/online-library/internal/infrastructure/scheduler"
 	"github.com/yourusername/online-library/internal/notification"
 	"github.com/yourusername/online-library/internal/repository"
 	"github.com/yourusername/online-library/internal/review"
+	"github.com/yourusername/online-library/internal/shelf"
 	"github.com/yourusername/online-library/internal/successscore"
 	"github.com/yourusername/online-library/internal/user"
 
@@ -31,6 +38,8 @@ import (
 	ideahandler "github.com/yourusername/online-library/internal/rest/handler/idea"
 	notificationhandler "github.com/yourusername/online-library/internal/rest/handler/notification"
 	reviewhandler "github.com/yourusername/online-library/internal/rest/handler/review"
+	shelfhandler "github.com/yourusername/online-library/internal/rest/handler/shelf"
+	successscorehandler "github.com/yourusername/online-library/internal/rest/handler/successscore"
 	swaggerhandler "github.com/yourusername/online-library/internal/rest/handler/swagger"
 	userhandler "github.com/yourusername/online-library/internal/rest/handler/user"
 	"github.com/yourusername/online-library/internal/rest/middleware"
@@ -38,6 +47,29 @@ import (
 	"go.uber.org/zap"
 )
 
+const (
+	// notificationDeliveryInterval is how often queued email, SMS and push
+	// notifications are sent
+	notificationDeliveryInterval = 30 * time.Second
+
+	// notificationDigestInterval is how often due daily and weekly digests
+	// are looked for; each period is sent at most once however often this
+	// runs
+	notificationDigestInterval = 15 * time.Minute
+
+	// notificationRetentionInterval is how often old read notifications
+	// are pruned
+	notificationRetentionInterval = 6 * time.Hour
+
+	// wishlistWatchInterval is how often watchers of books that freed up
+	// are alerted
+	wishlistWatchInterval = time.Minute
+
+	// contentBackfillInterval is how often texts stored before Markdown
+	// rendering are rendered, a batch at a time
+	contentBackfillInterval = 10 * time.Minute
+)
+
 func run(ctx context.Context, cfg *config.Config, log *zap.Logger) error {
 	// Connect to database
 	conn, err := postgres.NewConnection(ctx, cfg.Database.ConnectionString())
@@ -54,24 +86,50 @@ func run(ctx context.Context, cfg *config.Config, log *zap.Logger) error {
 	ideaRepo := repository.NewIdeaRepository(conn.DB, log)
 	reviewRepo := repository.NewReviewRepository(conn.DB, log)
 	donationRepo := repository.NewDonationRepository(conn.DB, log)
+	campaignRepo := repository.NewCampaignRepository(conn.DB, log)
+	receiptRepo := repository.NewReceiptRepository(conn.DB, log)
+	ledgerRepo := repository.NewLedgerRepository(conn.DB, log)
 	bookmarkRepo := repository.NewBookmarkRepository(conn.DB, log)
+	shelfRepo := repository.NewShelfRepository(conn.DB, log)
 	scoreRepo := repository.NewSuccessScoreRepository(conn.DB, log)
 	notificationRepo := repository.NewNotificationRepository(conn.DB, log)
 	adminRepo := repository.NewAdminRepository(conn.DB, log)
 	handoverRepo := repository.NewHandoverRepository(conn.DB, log)
+	lifecycleRepo := repository.NewBookLifecycleRepository(conn.DB, log)
+	contentRepo := repository.NewContentRepository(conn.DB, log)
+	uow := repository.NewUnitOfWork(conn.DB, log)
+
+	// Postgres LISTEN/NOTIFY fan-out for streaming endpoints
+	pubsub := postgres.NewPubSub(cfg.Database.ConnectionString(), log)
+	defer pubsub.Close()
+
+	// Email, SMS and push adapters for the notification outbox
+	senders, err := notifier.NewSenders(cfg.Notify, log)
+	if err != nil {
+		return fmt.Errorf("failed to configure notification channels: %w", err)
+	}
+
+	// Payment gateway for money donations
+	payments, err := payment.NewProvider(cfg.Payment, log)
+	if err != nil {
+		return fmt.Errorf("failed to configure payment provider: %w", err)
+	}
 
 	// Initialize services
-	successScoreSvc := successscore.NewService(scoreRepo, log)
-	notificationSvc := notification.NewService(notificationRepo, log)
+	successScoreSvc := successscore.NewService(scoreRepo, uow, log)
+	lifecycleSvc := booklifecycle.NewService(lifecycleRepo, uow, log)
+	notificationSvc := notification.NewService(notificationRepo, pubsub, senders, log)
+	contentSvc := content.NewService(contentRepo, log)
 	authSvc := auth.NewService(userRepo, cfg.JWT.Secret, log)
-	userSvc := user.NewService(userRepo, log)
-	bookSvc := book.NewService(bookRepo, log)
-	ideaSvc := idea.NewService(ideaRepo, successScoreSvc, notificationSvc, log)
-	reviewSvc := review.NewService(reviewRepo, successScoreSvc, notificationSvc, log)
-	donationSvc := donation.NewService(donationRepo, successScoreSvc, log)
-	bookmarkSvc := bookmark.NewService(bookmarkRepo, log)
-	handoverSvc := handover.NewService(handoverRepo, notificationSvc, log)
-	adminSvc := admin.NewService(adminRepo, successScoreSvc, notificationSvc, handoverRepo, log)
+	userSvc := user.NewService(userRepo, contentSvc, notificationSvc, log)
+	bookSvc := book.NewService(bookRepo, lifecycleSvc, uow, log)
+	ideaSvc := idea.NewService(ideaRepo, successScoreSvc, notificationSvc, contentSvc, uow, cfg.Idea.CommentsReadersOnly, log)
+	reviewSvc := review.NewService(reviewRepo, handoverRepo, successScoreSvc, notificationSvc, contentSvc, uow, log)
+	donationSvc := donation.NewService(donationRepo, campaignRepo, receiptRepo, ledgerRepo, successScoreSvc, notificationSvc, bookSvc, payments, pdf.NewDonationRenderer(), uow, log)
+	bookmarkSvc := bookmark.NewService(bookmarkRepo, bookSvc, notificationSvc, log)
+	shelfSvc := shelf.NewService(shelfRepo, uow, log)
+	handoverSvc := handover.NewService(handoverRepo, notificationSvc, successScoreSvc, lifecycleSvc, contentSvc, uow, pubsub, log)
+	adminSvc := admin.NewService(adminRepo, successScoreSvc, notificationSvc, handoverRepo, lifecycleSvc, uow, log)
 
 	// Initialize handlers
 	authHandler := authhandler.NewHandler(authSvc, log)
@@ -81,9 +139,11 @@ func run(ctx context.Context, cfg *config.Config, log *zap.Logger) error {
 	reviewHandler := reviewhandler.NewHandler(reviewSvc, log)
 	donationHandler := donationhandler.NewHandler(donationSvc, log)
 	bookmarkHandler := bookmarkhandler.NewHandler(bookmarkSvc, log)
+	shelfHandler := shelfhandler.NewHandler(shelfSvc, log)
 	adminHandler := adminhandler.NewHandler(adminSvc, log)
 	notificationHandler := notificationhandler.NewHandler(notificationSvc, log)
 	handoverHandler := handoverhandler.NewHandler(handoverSvc, log)
+	successScoreHandler := successscorehandler.NewHandler(successScoreSvc, log)
 
 	// Setup router
 	if cfg.Server.Mode == "release" {
@@ -109,6 +169,8 @@ func run(ctx context.Context, cfg *config.Config, log *zap.Logger) error {
 	{
 		// Public routes
 		authhandler.RegisterPublicRoutes(api, authHandler)
+		donationhandler.RegisterPublicRoutes(api, donationHandler)
+		shelfhandler.RegisterPublicRoutes(api, shelfHandler)
 
 		// Protected routes
 		protected := api.Group("")
@@ -121,8 +183,10 @@ func run(ctx context.Context, cfg *config.Config, log *zap.Logger) error {
 			reviewhandler.RegisterRoutes(protected, reviewHandler)
 			donationhandler.RegisterRoutes(protected, donationHandler)
 			bookmarkhandler.RegisterRoutes(protected, bookmarkHandler)
+			shelfhandler.RegisterRoutes(protected, shelfHandler)
 			notificationhandler.RegisterRoutes(protected, notificationHandler)
 			handoverhandler.RegisterRoutes(protected, handoverHandler)
+			successscorehandler.RegisterRoutes(protected, successScoreHandler)
 		}
 
 		// Admin routes (requires admin role)
@@ -131,9 +195,45 @@ func run(ctx context.Context, cfg *config.Config, log *zap.Logger) error {
 		adminRoutes.Use(middleware.AdminMiddleware())
 		{
 			adminhandler.RegisterRoutes(adminRoutes, adminHandler)
+			successscorehandler.RegisterAdminRoutes(adminRoutes, successScoreHandler)
+			ideahandler.RegisterAdminRoutes(adminRoutes, ideaHandler)
+			reviewhandler.RegisterAdminRoutes(adminRoutes, reviewHandler)
+			notificationhandler.RegisterAdminRoutes(adminRoutes, notificationHandler)
+			handoverhandler.RegisterAdminRoutes(adminRoutes, handoverHandler)
+			donationhandler.RegisterAdminRoutes(adminRoutes, donationHandler)
+		}
+
+		// Streaming routes (token may also be passed as ?access_token=)
+		streams := api.Group("")
+		streams.Use(middleware.StreamAuthMiddleware(authSvc, log))
+		{
+			handoverhandler.RegisterStreamRoutes(streams, handoverHandler)
+			notificationhandler.RegisterStreamRoutes(streams, notificationHandler)
 		}
 	}
 
+	// Background jobs
+	go scheduler.Every(ctx, "notification_delivery", notificationDeliveryInterval, func(ctx context.Context) error {
+		_, err := notificationSvc.DeliverPending(ctx)
+		return err
+	}, log)
+	go scheduler.Every(ctx, "notification_digests", notificationDigestInterval, func(ctx context.Context) error {
+		_, err := notificationSvc.SendDigests(ctx)
+		return err
+	}, log)
+	go scheduler.Every(ctx, "wishlist_watches", wishlistWatchInterval, func(ctx context.Context) error {
+		_, err := bookmarkSvc.AlertWatchers(ctx)
+		return err
+	}, log)
+	go scheduler.Every(ctx, "content_backfill", contentBackfillInterval, func(ctx context.Context) error {
+		_, err := contentSvc.Backfill(ctx)
+		return err
+	}, log)
+	go scheduler.Every(ctx, "notification_retention", notificationRetentionInterval, func(ctx context.Context) error {
+		_, err := notificationSvc.PruneRead(ctx)
+		return err
+	}, log)
+
 	// Start server
 	srv := &http.Server{
 		Addr:    ":" + cfg.Server.Port,
@@ -151,7 +251,8 @@ func run(ctx context.Context, cfg *config.Config, log *zap.Logger) error {
 	<-ctx.Done()
 	log.Info("shutting down server")
 
-	// Graceful shutdown
+	// Graceful shutdown; closing pubsub ends open streams
+	pubsub.Close()
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -9,7 +9,7 @@ import (
 type Service interface {
 	// Book Request Management
 	GetPendingRequests(ctx context.Context, limit, offset int) ([]*domain.BookRequest, error)
-	ApproveBookRequest(ctx context.Context, requestID string, dueDate string) error
+	ApproveBookRequest(ctx context.Context, requestID, adminID string, dueDate string) error
 	RejectBookRequest(ctx context.Context, requestID string, reason string) error
 	GetRequestsByBook(ctx context.Context, bookID string) ([]*domain.BookRequest, error)
 
@@ -24,14 +24,15 @@ type Service interface {
 
 	// Book Management
 	GetAllBooks(ctx context.Context, limit, offset int, filters BookFilters) ([]*domain.Book, error)
-	UpdateBookStatus(ctx context.Context, bookID string, status domain.BookStatus) error
+	// UpdateBookStatus is an admin override; it can park, reserve or
+	// release a book but not move it into a handover
+	UpdateBookStatus(ctx context.Context, bookID, adminID string, status domain.BookStatus, note string) error
 }
 
 type AdminRepo interface {
 	GetPendingRequests(ctx context.Context, limit, offset int) ([]*domain.BookRequest, error)
 	GetRequestsByBook(ctx context.Context, bookID string) ([]*domain.BookRequest, error)
 	UpdateRequestStatus(ctx context.Context, requestID string, status string, processedAt string, dueDate *string) error
-	AssignBookToUser(ctx context.Context, bookID, userID string) error
 	CreateReadingHistory(ctx context.Context, bookID, userID, dueDate string) error
 	IncrementUserBooksReceived(ctx context.Context, userID string) error
 	GetAllUsers(ctx context.Context, limit, offset int) ([]*domain.User, error)
@@ -40,8 +41,10 @@ type AdminRepo interface {
 	GetAuditLogs(ctx context.Context, limit, offset int) ([]*AuditLog, error)
 	CreateAuditLog(ctx context.Context, log *AuditLog) error
 	GetAllBooks(ctx context.Context, limit, offset int, filters BookFilters) ([]*domain.Book, error)
-	UpdateBookStatus(ctx context.Context, bookID string, status string) error
 	GetBookByID(ctx context.Context, bookID string) (*domain.Book, error)
+	// LockBook serializes changes to a book; call it first inside
+	// UnitOfWork.Do
+	LockBook(ctx context.Context, bookID string) error
 }
 
 type HandoverRepo interface {
@@ -50,6 +53,12 @@ type HandoverRepo interface {
 	GetLastCompletedReadingHistory(ctx context.Context, bookID string) (*domain.ReadingHistoryExtended, error)
 }
 
+// UnitOfWork runs fn in one database transaction. Repository calls made
+// with the context passed to fn take part in it.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
 type SystemStats struct {
 	TotalUsers         int     `json:"total_users"`
 	TotalBooks         int     `json:"total_books"`
@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/yourusername/online-library/internal/booklifecycle"
 	"github.com/yourusername/online-library/internal/domain"
+	"github.com/yourusername/online-library/internal/handover"
+	"github.com/yourusername/online-library/internal/i18n"
 	"github.com/yourusername/online-library/internal/notification"
 	"github.com/yourusername/online-library/internal/successscore"
 	"go.uber.org/zap"
@@ -16,15 +19,19 @@ type service struct {
 	successScoreSvc successscore.Service
 	notificationSvc notification.Service
 	handoverRepo    HandoverRepo
+	lifecycle       booklifecycle.Service
+	uow             UnitOfWork
 	log             *zap.Logger
 }
 
-func NewService(adminRepo AdminRepo, successScoreSvc successscore.Service, notificationSvc notification.Service, handoverRepo HandoverRepo, log *zap.Logger) Service {
+func NewService(adminRepo AdminRepo, successScoreSvc successscore.Service, notificationSvc notification.Service, handoverRepo HandoverRepo, lifecycle booklifecycle.Service, uow UnitOfWork, log *zap.Logger) Service {
 	return &service{
 		adminRepo:       adminRepo,
 		successScoreSvc: successScoreSvc,
 		notificationSvc: notificationSvc,
 		handoverRepo:    handoverRepo,
+		lifecycle:       lifecycle,
+		uow:             uow,
 		log:             log,
 	}
 }
@@ -33,7 +40,7 @@ func (s *service) GetPendingRequests(ctx context.Context, limit, offset int) ([]
 	return s.adminRepo.GetPendingRequests(ctx, limit, offset)
 }
 
-func (s *service) ApproveBookRequest(ctx context.Context, requestID string, dueDate string) error {
+func (s *service) ApproveBookRequest(ctx context.Context, requestID, adminID string, dueDate string) error {
 	processedAt := time.Now().Format(time.RFC3339)
 
 	// Get the request details first
@@ -54,102 +61,120 @@ func (s *service) ApproveBookRequest(ctx context.Context, requestID string, dueD
 		return fmt.Errorf("request not found")
 	}
 
-	// Update request status to approved
-	if err := s.adminRepo.UpdateRequestStatus(ctx, requestID, "approved", processedAt, &dueDate); err != nil {
-		s.log.Error("failed to approve request", zap.String("request_id", requestID), zap.Error(err))
-		return err
+	parsedDueDate, parseErr := time.Parse(time.RFC3339, dueDate)
+	if parseErr != nil {
+		s.log.Error("failed to parse due date", zap.Error(parseErr))
+		parsedDueDate = time.Now().AddDate(0, 0, 14) // Default 14 days
 	}
 
-	// Reject all other pending requests for this book
-	otherRequests, err := s.adminRepo.GetRequestsByBook(ctx, targetRequest.BookID)
-	if err != nil {
-		s.log.Error("failed to get other requests", zap.Error(err))
-	} else {
-		for _, req := range otherRequests {
-			if req.ID != requestID && req.Status == "pending" {
-				if err := s.adminRepo.UpdateRequestStatus(ctx, req.ID, "rejected", processedAt, nil); err != nil {
-					s.log.Error("failed to reject other request", zap.String("request_id", req.ID), zap.Error(err))
-				}
-			}
+	var book *domain.Book
+	var thread *domain.HandoverThread
+	var currentHolderID string
+
+	// Approving, rejecting the rest and opening the handover happen
+	// together or not at all; the book lock makes concurrent approvals for
+	// the same book queue up behind each other
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.adminRepo.LockBook(ctx, targetRequest.BookID); err != nil {
+			return err
 		}
-	}
 
-	// Get book details
-	book, err := s.adminRepo.GetBookByID(ctx, targetRequest.BookID)
-	if err != nil {
-		s.log.Error("failed to get book details", zap.Error(err))
-		return err
-	}
+		// Re-read under the lock: another approval may have won the race
+		pending, err := s.adminRepo.GetRequestsByBook(ctx, targetRequest.BookID)
+		if err != nil {
+			return err
+		}
+		stillPending := false
+		for _, req := range pending {
+			if req.ID == requestID {
+				stillPending = true
+				break
+			}
+		}
+		if !stillPending {
+			return domain.ErrRequestNotPending
+		}
 
-	// Update book status to "requested"
-	if err := s.adminRepo.UpdateBookStatus(ctx, targetRequest.BookID, "requested"); err != nil {
-		s.log.Error("failed to update book status", zap.Error(err))
-		return err
-	}
+		book, err = s.adminRepo.GetBookByID(ctx, targetRequest.BookID)
+		if err != nil {
+			return err
+		}
 
-	// Determine who is the current holder
-	var currentHolderID string
+		// Fails unless the book is available or on hold
+		if err := s.lifecycle.Transition(ctx, booklifecycle.Change{
+			BookID:  targetRequest.BookID,
+			Action:  domain.BookApproved,
+			To:      domain.StatusRequested,
+			Actor:   domain.BookActorAdmin,
+			ActorID: adminID,
+		}); err != nil {
+			return err
+		}
 
-	if book.Status == domain.StatusAvailable || book.Status == domain.StatusOnHold {
-		// Book is available or on_hold - check if someone read it before
+		// Determine who is the current holder
 		lastHistory, err := s.handoverRepo.GetLastCompletedReadingHistory(ctx, targetRequest.BookID)
-		if err != nil || lastHistory == nil {
+		if err != nil {
+			return err
+		}
+		if lastHistory == nil {
 			// No one has read it yet, use book creator
-			if book.CreatedBy != nil && *book.CreatedBy != "" {
-				currentHolderID = *book.CreatedBy
-			} else {
+			if book.CreatedBy == nil || *book.CreatedBy == "" {
 				return fmt.Errorf("cannot determine current holder")
 			}
+			currentHolderID = *book.CreatedBy
 		} else {
 			// Someone read it before, they still have the physical book
 			currentHolderID = lastHistory.ReaderID
 		}
-	} else {
-		return fmt.Errorf("book is not available for request (status: %s)", book.Status)
-	}
 
-	// Create handover thread between current holder and new requester
-	parsedDueDate, parseErr := time.Parse(time.RFC3339, dueDate)
-	if parseErr != nil {
-		s.log.Error("failed to parse due date", zap.Error(parseErr))
-		parsedDueDate = time.Now().AddDate(0, 0, 14) // Default 14 days
-	}
+		// Update request status to approved
+		if err := s.adminRepo.UpdateRequestStatus(ctx, requestID, "approved", processedAt, &dueDate); err != nil {
+			return err
+		}
 
-	thread := &domain.HandoverThread{
-		BookID:          targetRequest.BookID,
-		CurrentHolderID: currentHolderID,
-		NextHolderID:    targetRequest.UserID,
-		Status:          string(domain.HandoverActive),
-		HandoverDueDate: parsedDueDate,
-		IsPublic:        true,
-		CreatedAt:       time.Now(),
-		UpdatedAt:       time.Now(),
-	}
+		// Reject all other pending requests for this book
+		for _, req := range pending {
+			if req.ID != requestID {
+				if err := s.adminRepo.UpdateRequestStatus(ctx, req.ID, "rejected", processedAt, nil); err != nil {
+					return err
+				}
+			}
+		}
 
-	if err := s.handoverRepo.CreateHandoverThread(ctx, thread); err != nil {
-		s.log.Error("failed to create handover thread", zap.Error(err))
-	} else {
-		// Create system message
-		systemMsg := &domain.HandoverMessage{
-			ThreadID:        thread.ID,
-			UserID:          currentHolderID,
-			Message:         fmt.Sprintf("📚 Book handover thread created. Please coordinate delivery of \"%s\" to the reader. Due date: %s", book.Title, parsedDueDate.Format("Jan 2, 2006")),
-			IsSystemMessage: true,
+		// Create handover thread between current holder and new requester
+		thread = &domain.HandoverThread{
+			BookID:          targetRequest.BookID,
+			CurrentHolderID: currentHolderID,
+			NextHolderID:    targetRequest.UserID,
+			Status:          string(domain.HandoverActive),
+			HandoverDueDate: parsedDueDate,
+			IsPublic:        true,
 			CreatedAt:       time.Now(),
+			UpdatedAt:       time.Now(),
 		}
-		if err := s.handoverRepo.CreateHandoverMessage(ctx, systemMsg); err != nil {
-			s.log.Error("failed to create system message", zap.Error(err))
+		if err := s.handoverRepo.CreateHandoverThread(ctx, thread); err != nil {
+			return err
 		}
 
-		// Notify both users
-		if err := s.notificationSvc.NotifyHandoverThreadCreated(ctx, currentHolderID, targetRequest.UserID, targetRequest.BookID, book.Title); err != nil {
-			s.log.Error("failed to send notifications", zap.Error(err))
+		systemMsg := handover.SystemMessage(thread.ID, currentHolderID, "handover.system.thread_created_by_admin", map[string]string{
+			"book":     book.Title,
+			"due_date": parsedDueDate.Format(i18n.DateLayout),
+		})
+		if err := s.handoverRepo.CreateHandoverMessage(ctx, systemMsg); err != nil {
+			return err
 		}
+
+		// Increment user's books_received counter
+		return s.adminRepo.IncrementUserBooksReceived(ctx, targetRequest.UserID)
+	})
+	if err != nil {
+		s.log.Error("failed to approve request", zap.String("request_id", requestID), zap.Error(err))
+		return err
 	}
 
-	// Increment user's books_received counter
-	if err := s.adminRepo.IncrementUserBooksReceived(ctx, targetRequest.UserID); err != nil {
-		s.log.Error("failed to increment books received", zap.Error(err))
+	// Notify both users
+	if err := s.notificationSvc.NotifyHandoverThreadCreated(ctx, thread.ID, currentHolderID, targetRequest.UserID, targetRequest.BookID, book.Title); err != nil {
+		s.log.Error("failed to send notifications", zap.Error(err))
 	}
 
 	// Send notification to user
@@ -157,7 +182,7 @@ func (s *service) ApproveBookRequest(ctx context.Context, requestID string, dueD
 		s.log.Error("failed to send notification", zap.Error(err))
 	}
 
-	s.log.Info("book request approved", zap.String("request_id", requestID), zap.String("book_status", string(book.Status)))
+	s.log.Info("book request approved", zap.String("request_id", requestID), zap.String("book_id", targetRequest.BookID))
 	return nil
 }
 
@@ -217,6 +242,13 @@ func (s *service) GetAllBooks(ctx context.Context, limit, offset int, filters Bo
 	return s.adminRepo.GetAllBooks(ctx, limit, offset, filters)
 }
 
-func (s *service) UpdateBookStatus(ctx context.Context, bookID string, status domain.BookStatus) error {
-	return s.adminRepo.UpdateBookStatus(ctx, bookID, string(status))
+func (s *service) UpdateBookStatus(ctx context.Context, bookID, adminID string, status domain.BookStatus, note string) error {
+	return s.lifecycle.Transition(ctx, booklifecycle.Change{
+		BookID:  bookID,
+		Action:  domain.BookOverridden,
+		To:      status,
+		Actor:   domain.BookActorAdmin,
+		ActorID: adminID,
+		Note:    note,
+	})
 }
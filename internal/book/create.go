@@ -11,11 +11,21 @@ import (
 
 func (s *service) Create(ctx context.Context, book *domain.Book) (*domain.Book, error) {
 	book.ID = uuid.New().String()
-	book.Status = domain.StatusAvailable
+	book.Status = domain.InitialBookStatus
 	book.CreatedAt = time.Now()
 	book.UpdatedAt = time.Now()
 
-	if err := s.bookRepo.Create(ctx, book); err != nil {
+	var createdBy string
+	if book.CreatedBy != nil {
+		createdBy = *book.CreatedBy
+	}
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.bookRepo.Create(ctx, book); err != nil {
+			return err
+		}
+		return s.lifecycle.Created(ctx, book.ID, createdBy)
+	})
+	if err != nil {
 		s.log.Error("failed to create book", zap.Error(err))
 		return nil, err
 	}
@@ -21,6 +21,9 @@ type Service interface {
 	ReturnBook(ctx context.Context, bookID, userID string) error
 	GetReadingHistory(ctx context.Context, userID string) ([]*domain.ReadingHistory, error)
 	GetBooksOnHold(ctx context.Context, userID string) ([]*domain.Book, error)
+	// GetTimeline returns the book's custody history, oldest first. Only
+	// admins and the current holder see who was involved.
+	GetTimeline(ctx context.Context, bookID, viewerID string, isAdmin bool) ([]*domain.BookTimelineEvent, error)
 }
 
 // BookRepo defines the book repository interface
@@ -35,9 +38,18 @@ type BookRepo interface {
 	CreateRequest(ctx context.Context, request *domain.BookRequest) error
 	FindRequestsByUserID(ctx context.Context, userID string) ([]*domain.BookRequest, error)
 	FindRequestByBookAndUser(ctx context.Context, bookID, userID string) (*domain.BookRequest, error)
+	// GetWishlistPriority returns the priority_level of the user's wishlist
+	// entry for the book, or 0 if they haven't wishlisted it
+	GetWishlistPriority(ctx context.Context, bookID, userID string) (int, error)
 	CancelRequest(ctx context.Context, bookID, userID string) error
-	ReturnBook(ctx context.Context, bookID string) error
 	CompleteReadingHistory(ctx context.Context, bookID, userID string) error
 	GetReadingHistoryByUser(ctx context.Context, userID string) ([]*domain.ReadingHistory, error)
 	GetBooksOnHoldByUser(ctx context.Context, userID string) ([]*domain.Book, error)
+	GetTimeline(ctx context.Context, bookID string) ([]*domain.BookTimelineEvent, error)
+}
+
+// UnitOfWork runs fn in one database transaction. Repository calls made
+// with the context passed to fn take part in it.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
 }
@@ -28,8 +28,14 @@ func (s *service) RequestBook(ctx context.Context, bookID, userID string) (*doma
 		return nil, domain.ErrAlreadyRequested
 	}
 
-	// Calculate priority score (success score + interest match + distance)
-	priorityScore := calculatePriorityScore(book, userID)
+	// Calculate priority score (success score + interest match + distance),
+	// raised by how much the reader wants the book
+	wishlistPriority, err := s.bookRepo.GetWishlistPriority(ctx, bookID, userID)
+	if err != nil {
+		s.log.Error("failed to get wishlist priority", zap.Error(err))
+		return nil, err
+	}
+	priorityScore := calculatePriorityScore(book, userID) + float64(wishlistPriority)*wishlistPriorityWeight
 
 	request := &domain.BookRequest{
 		ID:                 uuid.New().String(),
@@ -76,6 +82,10 @@ func (s *service) CancelRequest(ctx context.Context, bookID, userID string) erro
 	return nil
 }
 
+// wishlistPriorityWeight is what each wishlist priority_level adds to a
+// request's priority score
+const wishlistPriorityWeight = 2.0
+
 // calculatePriorityScore calculates the priority score for a book request
 // Based on: success score (weight: 0.5) + interest match (weight: 0.3) + distance (weight: 0.2)
 func calculatePriorityScore(book *domain.Book, userID string) float64 {
@@ -3,6 +3,7 @@ package book
 import (
 	"context"
 
+	"github.com/yourusername/online-library/internal/booklifecycle"
 	"github.com/yourusername/online-library/internal/domain"
 	"go.uber.org/zap"
 )
@@ -20,17 +21,24 @@ func (s *service) ReturnBook(ctx context.Context, bookID, userID string) error {
 		return domain.ErrUnauthorized
 	}
 
-	// Update book status
-	if err := s.bookRepo.ReturnBook(ctx, bookID); err != nil {
+	// The copy goes back on the shelf and the reading ends together
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.lifecycle.Transition(ctx, booklifecycle.Change{
+			BookID:  bookID,
+			Action:  domain.BookReturned,
+			To:      domain.StatusAvailable,
+			Actor:   domain.BookActorHolder,
+			ActorID: userID,
+		}); err != nil {
+			return err
+		}
+		return s.bookRepo.CompleteReadingHistory(ctx, bookID, userID)
+	})
+	if err != nil {
 		s.log.Error("failed to return book", zap.String("book_id", bookID), zap.Error(err))
 		return err
 	}
 
-	// Update reading history
-	if err := s.bookRepo.CompleteReadingHistory(ctx, bookID, userID); err != nil {
-		s.log.Error("failed to complete reading history", zap.Error(err))
-	}
-
 	s.log.Info("book returned successfully", zap.String("book_id", bookID), zap.String("user_id", userID))
 	return nil
 }
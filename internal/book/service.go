@@ -1,18 +1,23 @@
 package book
 
 import (
+	"github.com/yourusername/online-library/internal/booklifecycle"
 	"go.uber.org/zap"
 )
 
 type service struct {
-	bookRepo BookRepo
-	log      *zap.Logger
+	bookRepo  BookRepo
+	lifecycle booklifecycle.Service
+	uow       UnitOfWork
+	log       *zap.Logger
 }
 
 // NewService creates a new book service
-func NewService(bookRepo BookRepo, log *zap.Logger) Service {
+func NewService(bookRepo BookRepo, lifecycle booklifecycle.Service, uow UnitOfWork, log *zap.Logger) Service {
 	return &service{
-		bookRepo: bookRepo,
-		log:      log,
+		bookRepo:  bookRepo,
+		lifecycle: lifecycle,
+		uow:       uow,
+		log:       log,
 	}
 }
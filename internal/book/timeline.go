@@ -0,0 +1,48 @@
+package book
+
+import (
+	"context"
+	"errors"
+
+	"github.com/yourusername/online-library/internal/domain"
+	"go.uber.org/zap"
+)
+
+func (s *service) GetTimeline(ctx context.Context, bookID, viewerID string, isAdmin bool) ([]*domain.BookTimelineEvent, error) {
+	book, err := s.bookRepo.FindByID(ctx, bookID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		s.log.Error("failed to get book", zap.String("book_id", bookID), zap.Error(err))
+		return nil, err
+	}
+
+	events, err := s.bookRepo.GetTimeline(ctx, bookID)
+	if err != nil {
+		s.log.Error("failed to get book timeline", zap.String("book_id", bookID), zap.Error(err))
+		return nil, err
+	}
+
+	isHolder := book.CurrentHolderID != nil && *book.CurrentHolderID == viewerID
+	if !isAdmin && !isHolder {
+		anonymize(events, viewerID)
+	}
+	return events, nil
+}
+
+// anonymize hides everyone but the viewer and drops notes and references,
+// keeping what happened and when
+func anonymize(events []*domain.BookTimelineEvent, viewerID string) {
+	for _, e := range events {
+		for i := range e.Actors {
+			if e.Actors[i].UserID != nil && *e.Actors[i].UserID == viewerID {
+				continue
+			}
+			e.Actors[i].UserID = nil
+			e.Actors[i].Username = ""
+		}
+		e.Detail = ""
+		e.RefID = ""
+	}
+}
@@ -0,0 +1,46 @@
+package booklifecycle
+
+import (
+	"context"
+
+	"github.com/yourusername/online-library/internal/domain"
+)
+
+// Service is the only writer of books.status. Call Transition with the
+// context of the surrounding UnitOfWork.Do so the status change commits
+// with the rest of the operation.
+type Service interface {
+	// Transition moves a book to change.To. It returns a
+	// *domain.BookTransitionError if the book's current status doesn't
+	// allow it and domain.ErrForbidden if the actor may not trigger it.
+	Transition(ctx context.Context, change Change) error
+	// Created records the first event for a book inserted with
+	// domain.InitialBookStatus
+	Created(ctx context.Context, bookID, actorID string) error
+}
+
+// Change describes one status change
+type Change struct {
+	BookID string
+	Action domain.BookAction
+	To     domain.BookStatus
+	Actor  domain.BookActor
+	// ActorID is the user triggering the change; empty for system jobs.
+	// Transitions with domain.HolderSet make them the holder.
+	ActorID string
+	Note    string
+}
+
+type LifecycleRepo interface {
+	// GetBookState returns the book's status and holder and locks the row
+	// until the surrounding transaction ends
+	GetBookState(ctx context.Context, bookID string) (domain.BookStatus, *string, error)
+	SetBookState(ctx context.Context, bookID string, status domain.BookStatus, holderID *string) error
+	CreateBookEvent(ctx context.Context, event *domain.BookEvent) error
+}
+
+// UnitOfWork runs fn in one database transaction. Repository calls made
+// with the context passed to fn take part in it.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,109 @@
+package booklifecycle
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/online-library/internal/domain"
+	"go.uber.org/zap"
+)
+
+type service struct {
+	repo LifecycleRepo
+	uow  UnitOfWork
+	log  *zap.Logger
+}
+
+func NewService(repo LifecycleRepo, uow UnitOfWork, log *zap.Logger) Service {
+	return &service{
+		repo: repo,
+		uow:  uow,
+		log:  log,
+	}
+}
+
+func (s *service) Transition(ctx context.Context, change Change) error {
+	if !change.To.Valid() {
+		return domain.ErrInvalidBookStatus
+	}
+
+	var from domain.BookStatus
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		var holderID *string
+		var err error
+		from, holderID, err = s.repo.GetBookState(ctx, change.BookID)
+		if err != nil {
+			return err
+		}
+
+		t, err := domain.CheckBookTransition(change.Action, from, change.To, change.Actor)
+		if err != nil {
+			return err
+		}
+
+		switch t.Holder {
+		case domain.HolderSet:
+			holderID = stringPtr(change.ActorID)
+		case domain.HolderClear:
+			holderID = nil
+		}
+		// A book on the shelf has nobody holding it
+		if change.To == domain.StatusAvailable {
+			holderID = nil
+		}
+
+		if err := s.repo.SetBookState(ctx, change.BookID, change.To, holderID); err != nil {
+			return err
+		}
+		return s.repo.CreateBookEvent(ctx, &domain.BookEvent{
+			BookID:     change.BookID,
+			Action:     change.Action,
+			FromStatus: from,
+			ToStatus:   change.To,
+			Actor:      change.Actor,
+			ActorID:    stringPtr(change.ActorID),
+			HolderID:   holderID,
+			Note:       change.Note,
+			CreatedAt:  time.Now(),
+		})
+	})
+	if err != nil {
+		s.log.Warn("book transition rejected",
+			zap.String("book_id", change.BookID),
+			zap.String("action", string(change.Action)),
+			zap.String("from", string(from)),
+			zap.String("to", string(change.To)),
+			zap.Error(err))
+		return err
+	}
+
+	s.log.Info("book status changed",
+		zap.String("book_id", change.BookID),
+		zap.String("action", string(change.Action)),
+		zap.String("from", string(from)),
+		zap.String("to", string(change.To)))
+	return nil
+}
+
+func (s *service) Created(ctx context.Context, bookID, actorID string) error {
+	// Whoever adds a book has the copy until its first handover
+	actor := domain.BookActorSystem
+	if actorID != "" {
+		actor = domain.BookActorHolder
+	}
+	return s.repo.CreateBookEvent(ctx, &domain.BookEvent{
+		BookID:    bookID,
+		Action:    domain.BookCreated,
+		ToStatus:  domain.InitialBookStatus,
+		Actor:     actor,
+		ActorID:   stringPtr(actorID),
+		CreatedAt: time.Now(),
+	})
+}
+
+func stringPtr(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
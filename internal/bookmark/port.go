@@ -10,10 +10,44 @@ type Service interface {
 	Create(ctx context.Context, bookmark *domain.UserBookmark) (*domain.UserBookmark, error)
 	Delete(ctx context.Context, userID, bookID, bookmarkType string) error
 	GetByUser(ctx context.Context, userID string) ([]*domain.UserBookmark, error)
+	// UpdateWatch changes the priority and auto-request setting of the
+	// user's wishlist entry for a book
+	UpdateWatch(ctx context.Context, userID, bookID string, priorityLevel int, autoRequest bool) (*domain.UserBookmark, error)
+	// AlertWatchers tells users whose wishlisted book has become available
+	// or on hold since they were last alerted, requesting it for those who
+	// opted in. It returns how many watchers were alerted.
+	AlertWatchers(ctx context.Context) (int, error)
 }
 
 type BookmarkRepo interface {
 	Create(ctx context.Context, bookmark *domain.UserBookmark) error
 	Delete(ctx context.Context, userID, bookID, bookmarkType string) error
 	FindByUserID(ctx context.Context, userID string) ([]*domain.UserBookmark, error)
+	// UpdateWatch saves a wishlist entry's priority and auto-request flag
+	// and fills in the rest of it
+	UpdateWatch(ctx context.Context, bookmark *domain.UserBookmark) error
+	// ClaimWatchAlerts marks up to limit wishlist entries alerted and
+	// returns them. An entry is due when its book is available or on hold
+	// with someone else, and got there after the entry was created or last
+	// alerted. Claiming is atomic, so each change is alerted once even with
+	// several servers running the job.
+	ClaimWatchAlerts(ctx context.Context, limit int) ([]*WatchAlert, error)
+}
+
+// WatchAlert is a wishlist entry whose book has freed up
+type WatchAlert struct {
+	BookmarkID  string
+	UserID      string
+	BookID      string
+	BookTitle   string
+	AutoRequest bool
+}
+
+type BookRequester interface {
+	RequestBook(ctx context.Context, bookID, userID string) (*domain.BookRequest, error)
+}
+
+type NotificationSvc interface {
+	NotifyBookAvailable(ctx context.Context, userID, bookID, bookTitle string) error
+	NotifyBookAutoRequested(ctx context.Context, userID, bookID, bookTitle string) error
 }
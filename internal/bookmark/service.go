@@ -2,6 +2,7 @@ package bookmark
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,19 +10,33 @@ import (
 	"go.uber.org/zap"
 )
 
+// watchAlertBatch is how many watchers one AlertWatchers run handles
+const watchAlertBatch = 100
+
 type service struct {
-	bookmarkRepo BookmarkRepo
-	log          *zap.Logger
+	bookmarkRepo    BookmarkRepo
+	books           BookRequester
+	notificationSvc NotificationSvc
+	log             *zap.Logger
 }
 
-func NewService(bookmarkRepo BookmarkRepo, log *zap.Logger) Service {
+func NewService(bookmarkRepo BookmarkRepo, books BookRequester, notificationSvc NotificationSvc, log *zap.Logger) Service {
 	return &service{
-		bookmarkRepo: bookmarkRepo,
-		log:          log,
+		bookmarkRepo:    bookmarkRepo,
+		books:           books,
+		notificationSvc: notificationSvc,
+		log:             log,
 	}
 }
 
 func (s *service) Create(ctx context.Context, bookmark *domain.UserBookmark) (*domain.UserBookmark, error) {
+	if !bookmark.BookmarkType.Valid() || bookmark.PriorityLevel < 0 || bookmark.PriorityLevel > domain.MaxBookmarkPriority {
+		return nil, domain.ErrInvalidInput
+	}
+	// Only wishlist entries are watches
+	if bookmark.BookmarkType != domain.BookmarkTypeWishlist {
+		bookmark.AutoRequest = false
+	}
 	bookmark.ID = uuid.New().String()
 	bookmark.CreatedAt = time.Now()
 
@@ -35,6 +50,9 @@ func (s *service) Create(ctx context.Context, bookmark *domain.UserBookmark) (*d
 }
 
 func (s *service) Delete(ctx context.Context, userID, bookID, bookmarkType string) error {
+	if !domain.BookmarkType(bookmarkType).Valid() {
+		return domain.ErrInvalidInput
+	}
 	if err := s.bookmarkRepo.Delete(ctx, userID, bookID, bookmarkType); err != nil {
 		s.log.Error("failed to delete bookmark", zap.String("user_id", userID), zap.String("book_id", bookID), zap.String("bookmark_type", bookmarkType), zap.String("error", err.Error()))
 		return err
@@ -52,3 +70,61 @@ func (s *service) GetByUser(ctx context.Context, userID string) ([]*domain.UserB
 	}
 	return bookmarks, nil
 }
+
+func (s *service) UpdateWatch(ctx context.Context, userID, bookID string, priorityLevel int, autoRequest bool) (*domain.UserBookmark, error) {
+	if priorityLevel < 0 || priorityLevel > domain.MaxBookmarkPriority {
+		return nil, domain.ErrInvalidInput
+	}
+	bookmark := &domain.UserBookmark{
+		UserID:        userID,
+		BookID:        bookID,
+		BookmarkType:  domain.BookmarkTypeWishlist,
+		PriorityLevel: priorityLevel,
+		AutoRequest:   autoRequest,
+	}
+	if err := s.bookmarkRepo.UpdateWatch(ctx, bookmark); err != nil {
+		return nil, err
+	}
+	return bookmark, nil
+}
+
+func (s *service) AlertWatchers(ctx context.Context) (int, error) {
+	alerts, err := s.bookmarkRepo.ClaimWatchAlerts(ctx, watchAlertBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, a := range alerts {
+		if a.AutoRequest && s.autoRequest(ctx, a) {
+			continue
+		}
+		if err := s.notificationSvc.NotifyBookAvailable(ctx, a.UserID, a.BookID, a.BookTitle); err != nil {
+			s.log.Warn("failed to notify watcher", zap.String("bookmark_id", a.BookmarkID), zap.Error(err))
+		}
+	}
+
+	if len(alerts) > 0 {
+		s.log.Info("wishlist watchers alerted", zap.Int("count", len(alerts)))
+	}
+	return len(alerts), nil
+}
+
+// autoRequest files the watcher's request and tells them. It reports false
+// if no request was made, in which case the watcher is only notified.
+func (s *service) autoRequest(ctx context.Context, a *WatchAlert) bool {
+	if _, err := s.books.RequestBook(ctx, a.BookID, a.UserID); err != nil {
+		if !errors.Is(err, domain.ErrAlreadyRequested) {
+			s.log.Warn("failed to auto-request watched book",
+				zap.String("bookmark_id", a.BookmarkID),
+				zap.String("book_id", a.BookID),
+				zap.Error(err))
+		}
+		return false
+	}
+
+	s.log.Info("watched book auto-requested", zap.String("book_id", a.BookID), zap.String("user_id", a.UserID))
+	if err := s.notificationSvc.NotifyBookAutoRequested(ctx, a.UserID, a.BookID, a.BookTitle); err != nil {
+		s.log.Warn("failed to notify watcher", zap.String("bookmark_id", a.BookmarkID), zap.Error(err))
+	}
+	return true
+}
@@ -3,6 +3,8 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -11,6 +13,9 @@ type Config struct {
 	Database DatabaseConfig
 	Server   ServerConfig
 	JWT      JWTConfig
+	Notify   NotifyConfig
+	Payment  PaymentConfig
+	Idea     IdeaConfig
 }
 
 type DatabaseConfig struct {
@@ -32,6 +37,56 @@ type JWTConfig struct {
 	RefreshTokenTTL int // hours
 }
 
+// NotifyConfig selects an adapter per external notification channel. The
+// "log" and "file" drivers are for development: they record what would be
+// sent instead of sending it.
+type NotifyConfig struct {
+	EmailDriver string // "log", "file" or "smtp"
+	SMSDriver   string // "log", "file" or "http"
+	PushDriver  string // "log", "file" or "http"
+	FileDir     string // where the file driver writes
+	PublicURL   string // prefix for notification links in emails and SMS
+	SMTP        SMTPConfig
+	SMSGateway  GatewayConfig
+	PushGateway GatewayConfig
+}
+
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// GatewayConfig points the http driver at an SMS or push relay
+type GatewayConfig struct {
+	URL   string
+	Token string
+}
+
+// PaymentConfig selects the gateway for money donations. The "mock"
+// provider confirms or fails payments itself by calling WebhookURL.
+type PaymentConfig struct {
+	Provider      string // "mock"
+	WebhookSecret string
+	WebhookURL    string // where the mock posts its callbacks
+	PublicURL     string // prefix for checkout page links
+	MockOutcome   string // "success", "failure" or "delayed"
+	MockDelay     time.Duration
+}
+
+// IdeaConfig holds community settings for reading ideas
+type IdeaConfig struct {
+	// CommentsReadersOnly limits commenting on a book's ideas to members
+	// who have borrowed the book
+	CommentsReadersOnly bool
+}
+
+// devWebhookSecret signs mock webhooks in development. It is public, so it
+// is refused outside development.
+const devWebhookSecret = "mock-webhook-secret-change-in-production"
+
 func Load() (*Config, error) {
 	godotenv.Load()
 
@@ -52,7 +107,67 @@ func Load() (*Config, error) {
 			AccessTokenTTL:  24,
 			RefreshTokenTTL: 168, // 7 days
 		},
+		Notify: NotifyConfig{
+			EmailDriver: getEnv("NOTIFY_EMAIL_DRIVER", "log"),
+			SMSDriver:   getEnv("NOTIFY_SMS_DRIVER", "log"),
+			PushDriver:  getEnv("NOTIFY_PUSH_DRIVER", "log"),
+			FileDir:     getEnv("NOTIFY_FILE_DIR", "tmp/notifications"),
+			PublicURL:   getEnv("APP_PUBLIC_URL", "http://localhost:3000"),
+			SMTP: SMTPConfig{
+				Host:     getEnv("SMTP_HOST", "localhost"),
+				Port:     getEnv("SMTP_PORT", "587"),
+				Username: getEnv("SMTP_USERNAME", ""),
+				Password: getEnv("SMTP_PASSWORD", ""),
+				From:     getEnv("SMTP_FROM", "Amar Pathagar <no-reply@amarpathagar.com>"),
+			},
+			SMSGateway: GatewayConfig{
+				URL:   getEnv("SMS_GATEWAY_URL", ""),
+				Token: getEnv("SMS_GATEWAY_TOKEN", ""),
+			},
+			PushGateway: GatewayConfig{
+				URL:   getEnv("PUSH_GATEWAY_URL", ""),
+				Token: getEnv("PUSH_GATEWAY_TOKEN", ""),
+			},
+		},
+		Payment: PaymentConfig{
+			Provider:      getEnv("PAYMENT_PROVIDER", ""),
+			WebhookSecret: getEnv("PAYMENT_WEBHOOK_SECRET", ""),
+			WebhookURL:    getEnv("PAYMENT_WEBHOOK_URL", "http://localhost:8080/api/v1/payments/webhook/mock"),
+			PublicURL:     getEnv("APP_PUBLIC_URL", "http://localhost:3000"),
+			MockOutcome:   getEnv("PAYMENT_MOCK_OUTCOME", "success"),
+		},
+	}
+
+	// Outside development the payment gateway must be chosen explicitly: the
+	// mock confirms every checkout, and a published secret lets anyone
+	// forge webhooks
+	if config.Server.Mode == "debug" {
+		if config.Payment.Provider == "" {
+			config.Payment.Provider = "mock"
+		}
+		if config.Payment.WebhookSecret == "" {
+			config.Payment.WebhookSecret = devWebhookSecret
+		}
+	} else {
+		if config.Payment.Provider == "" {
+			return nil, fmt.Errorf("PAYMENT_PROVIDER must be set when GIN_MODE is %q", config.Server.Mode)
+		}
+		if config.Payment.WebhookSecret == "" || config.Payment.WebhookSecret == devWebhookSecret {
+			return nil, fmt.Errorf("PAYMENT_WEBHOOK_SECRET must be set to a private value when GIN_MODE is %q", config.Server.Mode)
+		}
+	}
+
+	delay, err := time.ParseDuration(getEnv("PAYMENT_MOCK_DELAY", "30s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid PAYMENT_MOCK_DELAY: %w", err)
+	}
+	config.Payment.MockDelay = delay
+
+	readersOnly, err := strconv.ParseBool(getEnv("IDEA_COMMENTS_READERS_ONLY", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid IDEA_COMMENTS_READERS_ONLY: %w", err)
 	}
+	config.Idea.CommentsReadersOnly = readersOnly
 
 	return config, nil
 }
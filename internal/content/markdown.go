@@ -0,0 +1,273 @@
+package content
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// The supported Markdown subset: paragraphs and line breaks, **bold**,
+// *italic*, `code`, fenced code blocks, [links](https://...), bare
+// http(s) URLs, "- " and "1. " lists and "> " quotes. Everything else is
+// shown as typed. Source text is escaped before any markup is added and
+// the renderer only ever emits the tags below, so its output is safe to
+// embed without further sanitizing.
+
+var (
+	codeSpanPattern    = regexp.MustCompile("`([^`\n]+)`")
+	linkPattern        = regexp.MustCompile(`\[([^\]\n]+)\]\(([^)\s]+)\)`)
+	autolinkPattern    = regexp.MustCompile(`https?://[^\s<>()\[\]]+`)
+	mentionPattern     = regexp.MustCompile(`(^|[^\w@])@([A-Za-z0-9_](?:[A-Za-z0-9_.-]*[A-Za-z0-9_])?)`)
+	bookRefPattern     = regexp.MustCompile(`(^|[^\w#&])#([0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12})\b`)
+	strongPattern      = regexp.MustCompile(`\*\*([^*\s](?:[^*]*[^*\s])?)\*\*`)
+	emphasisPattern    = regexp.MustCompile(`\*([^*\s<](?:[^*<]*[^*\s<])?)\*`)
+	placeholderPattern = regexp.MustCompile("\x00([0-9]+)\x00")
+	orderedItem        = regexp.MustCompile(`^[0-9]{1,9}\. `)
+)
+
+// Links holds what mentions and book references resolve to: user IDs by
+// lower-cased username and book titles by ID. Unresolved references are
+// shown as typed.
+type Links struct {
+	Users map[string]string
+	Books map[string]string
+}
+
+// refs collects the mentions and book references found while rendering
+type refs struct {
+	mentions []string
+	books    []string
+	seen     map[string]bool
+}
+
+func (r *refs) add(kind, key string) {
+	if r.seen[kind+key] {
+		return
+	}
+	r.seen[kind+key] = true
+	if kind == "@" {
+		r.mentions = append(r.mentions, key)
+	} else {
+		r.books = append(r.books, key)
+	}
+}
+
+// Clean normalizes line endings, replaces invalid UTF-8 and drops control
+// characters other than newlines and tabs
+func Clean(source string) string {
+	source = strings.ToValidUTF8(source, "�")
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	source = strings.ReplaceAll(source, "\r", "\n")
+	return strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' || !unicode.IsControl(r) {
+			return r
+		}
+		return -1
+	}, source)
+}
+
+// Extract returns the usernames mentioned (lower-cased) and the book IDs
+// referenced in source, in order of first appearance. Mentions and
+// references inside code are ignored.
+func Extract(source string) (mentions, bookIDs []string) {
+	found := &refs{seen: map[string]bool{}}
+	render(Clean(source), Links{}, found)
+	return found.mentions, found.books
+}
+
+// NewMentions drops the mentions that were already in previous, the text
+// an edit replaced
+func NewMentions(mentions []Mention, previous string) []Mention {
+	usernames, _ := Extract(previous)
+	before := make(map[string]bool, len(usernames))
+	for _, username := range usernames {
+		before[username] = true
+	}
+	var added []Mention
+	for _, m := range mentions {
+		if !before[m.Username] {
+			added = append(added, m)
+		}
+	}
+	return added
+}
+
+// ToHTML renders source as sanitized HTML
+func ToHTML(source string, links Links) string {
+	return render(Clean(source), links, &refs{seen: map[string]bool{}})
+}
+
+func render(source string, links Links, found *refs) string {
+	var b strings.Builder
+	lines := strings.Split(source, "\n")
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			i++
+
+		case strings.HasPrefix(trimmed, "```"):
+			i++
+			var code []string
+			for i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```") {
+				code = append(code, lines[i])
+				i++
+			}
+			i++ // closing fence
+			b.WriteString("<pre><code>")
+			b.WriteString(html.EscapeString(strings.Join(code, "\n")))
+			b.WriteString("</code></pre>")
+
+		case strings.HasPrefix(trimmed, ">"):
+			var quoted []string
+			for i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">") {
+				quoted = append(quoted, strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(lines[i]), ">"), " "))
+				i++
+			}
+			b.WriteString("<blockquote>")
+			b.WriteString(render(strings.Join(quoted, "\n"), links, found))
+			b.WriteString("</blockquote>")
+
+		case unorderedItem(trimmed) != "":
+			b.WriteString("<ul>")
+			for i < len(lines) && unorderedItem(strings.TrimSpace(lines[i])) != "" {
+				b.WriteString("<li>" + inline(unorderedItem(strings.TrimSpace(lines[i])), links, found) + "</li>")
+				i++
+			}
+			b.WriteString("</ul>")
+
+		case orderedItem.MatchString(trimmed):
+			b.WriteString("<ol>")
+			for i < len(lines) && orderedItem.MatchString(strings.TrimSpace(lines[i])) {
+				item := orderedItem.ReplaceAllString(strings.TrimSpace(lines[i]), "")
+				b.WriteString("<li>" + inline(item, links, found) + "</li>")
+				i++
+			}
+			b.WriteString("</ol>")
+
+		default:
+			var para []string
+			for i < len(lines) && startsParagraphLine(lines[i]) {
+				para = append(para, inline(strings.TrimSpace(lines[i]), links, found))
+				i++
+			}
+			b.WriteString("<p>" + strings.Join(para, "<br>") + "</p>")
+		}
+	}
+	return b.String()
+}
+
+// startsParagraphLine reports whether line continues a paragraph rather
+// than ending it or starting another block
+func startsParagraphLine(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed != "" && !strings.HasPrefix(trimmed, "```") && !strings.HasPrefix(trimmed, ">") &&
+		unorderedItem(trimmed) == "" && !orderedItem.MatchString(trimmed)
+}
+
+func unorderedItem(line string) string {
+	for _, marker := range []string{"- ", "* ", "+ "} {
+		if strings.HasPrefix(line, marker) {
+			return strings.TrimSpace(line[len(marker):])
+		}
+	}
+	return ""
+}
+
+// inline renders one line. Code, links and references are swapped for
+// numbered placeholders so that emphasis never reaches into them, the rest
+// is escaped, and the placeholders are put back as HTML.
+func inline(text string, links Links, found *refs) string {
+	var parts []string
+	return inlineParts(text, links, found, &parts)
+}
+
+// inlineParts is inline with the placeholder table passed in, so that link
+// text, rendered by a nested call, can hold placeholders of the outer one
+func inlineParts(text string, links Links, found *refs, parts *[]string) string {
+	hold := func(fragment string) string {
+		*parts = append(*parts, fragment)
+		return "\x00" + strconv.Itoa(len(*parts)-1) + "\x00"
+	}
+
+	text = codeSpanPattern.ReplaceAllStringFunc(text, func(m string) string {
+		return hold("<code>" + html.EscapeString(m[1:len(m)-1]) + "</code>")
+	})
+	text = linkPattern.ReplaceAllStringFunc(text, func(m string) string {
+		sub := linkPattern.FindStringSubmatch(m)
+		href, ok := safeURL(sub[2])
+		if !ok {
+			return m
+		}
+		return hold(anchor(href, inlineParts(sub[1], Links{}, &refs{seen: map[string]bool{}}, parts), true))
+	})
+	text = autolinkPattern.ReplaceAllStringFunc(text, func(m string) string {
+		trimmed := strings.TrimRight(m, ".,;:!?'\"")
+		href, ok := safeURL(trimmed)
+		if !ok {
+			return m
+		}
+		return hold(anchor(href, html.EscapeString(trimmed), true)) + m[len(trimmed):]
+	})
+	text = mentionPattern.ReplaceAllStringFunc(text, func(m string) string {
+		sub := mentionPattern.FindStringSubmatch(m)
+		username := strings.ToLower(sub[2])
+		found.add("@", username)
+		userID, ok := links.Users[username]
+		if !ok {
+			return m
+		}
+		return sub[1] + hold(anchor("/users/"+url.PathEscape(userID), "@"+html.EscapeString(sub[2]), false))
+	})
+	text = bookRefPattern.ReplaceAllStringFunc(text, func(m string) string {
+		sub := bookRefPattern.FindStringSubmatch(m)
+		bookID := strings.ToLower(sub[2])
+		found.add("#", bookID)
+		title, ok := links.Books[bookID]
+		if !ok {
+			return m
+		}
+		return sub[1] + hold(anchor("/books/"+url.PathEscape(bookID), html.EscapeString(title), false))
+	})
+
+	text = html.EscapeString(text)
+	text = strongPattern.ReplaceAllString(text, "<strong>$1</strong>")
+	text = emphasisPattern.ReplaceAllString(text, "<em>$1</em>")
+	return placeholderPattern.ReplaceAllStringFunc(text, func(m string) string {
+		n, _ := strconv.Atoi(m[1 : len(m)-1])
+		return (*parts)[n]
+	})
+}
+
+func anchor(href, text string, external bool) string {
+	if external {
+		return `<a href="` + href + `" rel="nofollow noopener noreferrer">` + text + `</a>`
+	}
+	return `<a href="` + href + `">` + text + `</a>`
+}
+
+// safeURL accepts absolute http(s) and mailto URLs and returns them
+// escaped for an attribute
+func safeURL(raw string) (string, bool) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		if u.Host == "" {
+			return "", false
+		}
+	case "mailto":
+		if u.Opaque == "" {
+			return "", false
+		}
+	default:
+		return "", false
+	}
+	return html.EscapeString(u.String()), true
+}
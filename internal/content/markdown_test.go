@@ -0,0 +1,16 @@
+package content
+
+import "testing"
+
+func TestToHTMLCodeSpanInLinkText(t *testing.T) {
+	cases := map[string]string{
+		"[`x`](https://a.com)": `<p><a href="https://a.com" rel="nofollow noopener noreferrer"><code>x</code></a></p>`,
+		"[a `b` c](https://e.com) and `z`": `<p><a href="https://e.com" rel="nofollow noopener noreferrer">a <code>b</code> c</a>` +
+			` and <code>z</code></p>`,
+	}
+	for source, want := range cases {
+		if got := ToHTML(source, Links{}); got != want {
+			t.Errorf("ToHTML(%q) = %q, want %q", source, got, want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package content
+
+import "context"
+
+// Length limits, in characters, of the user-written fields
+const (
+	MaxIdeaContent     = 10000
+	MaxIdeaComment     = 2000
+	MaxHandoverMessage = 2000
+	MaxReviewComment   = 2000
+	MaxBio             = 500
+
+	// MaxMentions caps the mentions in one text that notify anyone
+	MaxMentions = 10
+)
+
+// Service turns user-written Markdown into sanitized HTML
+type Service interface {
+	// Render rejects source longer than maxLen characters with
+	// domain.ErrContentTooLong and renders it with mentions of existing
+	// users and references to catalogued books linked
+	Render(ctx context.Context, source string, maxLen int) (*Rendered, error)
+
+	// Backfill renders a batch of stored texts that predate rendering and
+	// returns how many it rendered
+	Backfill(ctx context.Context) (int, error)
+}
+
+// Rendered is the sanitized form of a text with what it references
+type Rendered struct {
+	Source string
+	HTML   string
+	// Mentions lists the first MaxMentions existing users mentioned
+	Mentions []Mention
+	// BookIDs lists the catalogued books referenced, in order
+	BookIDs []string
+}
+
+type Mention struct {
+	UserID   string
+	Username string
+}
+
+// Field is a stored user-written text with a rendered HTML copy
+type Field string
+
+const (
+	FieldIdea            Field = "idea"
+	FieldIdeaRevision    Field = "idea_revision"
+	FieldIdeaComment     Field = "idea_comment"
+	FieldHandoverMessage Field = "handover_message"
+	FieldReviewComment   Field = "review_comment"
+	FieldBio             Field = "bio"
+)
+
+// Fields lists every rendered field
+var Fields = []Field{FieldIdea, FieldIdeaRevision, FieldIdeaComment, FieldHandoverMessage, FieldReviewComment, FieldBio}
+
+// Unrendered is a stored text without its HTML copy
+type Unrendered struct {
+	ID     string
+	Source string
+}
+
+type Repo interface {
+	// FindUserIDs maps usernames, matched case-insensitively, to user IDs.
+	// Keys are lower-cased; unknown usernames are left out.
+	FindUserIDs(ctx context.Context, usernames []string) (map[string]string, error)
+	// FindBookTitles maps the IDs of existing books to their titles
+	FindBookTitles(ctx context.Context, ids []string) (map[string]string, error)
+
+	ListUnrendered(ctx context.Context, field Field, limit int) ([]*Unrendered, error)
+	SaveRendered(ctx context.Context, field Field, id, html string) error
+}
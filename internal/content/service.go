@@ -0,0 +1,83 @@
+package content
+
+import (
+	"context"
+	"unicode/utf8"
+
+	"github.com/yourusername/online-library/internal/domain"
+	"go.uber.org/zap"
+)
+
+// backfillBatch is how many texts per field one Backfill run renders
+const backfillBatch = 200
+
+type service struct {
+	contentRepo Repo
+	log         *zap.Logger
+}
+
+func NewService(contentRepo Repo, log *zap.Logger) Service {
+	return &service{contentRepo: contentRepo, log: log}
+}
+
+func (s *service) Render(ctx context.Context, source string, maxLen int) (*Rendered, error) {
+	source = Clean(source)
+	if utf8.RuneCountInString(source) > maxLen {
+		return nil, domain.ErrContentTooLong
+	}
+
+	usernames, bookIDs := Extract(source)
+	links := Links{Users: map[string]string{}, Books: map[string]string{}}
+	var err error
+	if len(usernames) > 0 {
+		if links.Users, err = s.contentRepo.FindUserIDs(ctx, usernames); err != nil {
+			return nil, err
+		}
+	}
+	if len(bookIDs) > 0 {
+		if links.Books, err = s.contentRepo.FindBookTitles(ctx, bookIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	rendered := &Rendered{Source: source, HTML: ToHTML(source, links)}
+	for _, username := range usernames {
+		if len(rendered.Mentions) == MaxMentions {
+			break
+		}
+		if userID, ok := links.Users[username]; ok {
+			rendered.Mentions = append(rendered.Mentions, Mention{UserID: userID, Username: username})
+		}
+	}
+	for _, bookID := range bookIDs {
+		if _, ok := links.Books[bookID]; ok {
+			rendered.BookIDs = append(rendered.BookIDs, bookID)
+		}
+	}
+	return rendered, nil
+}
+
+// Backfill renders texts stored before rendering was introduced. Their
+// length isn't checked again, so texts over today's limits keep working.
+func (s *service) Backfill(ctx context.Context) (int, error) {
+	rendered := 0
+	for _, field := range Fields {
+		pending, err := s.contentRepo.ListUnrendered(ctx, field, backfillBatch)
+		if err != nil {
+			s.log.Error("failed to list unrendered texts", zap.String("field", string(field)), zap.Error(err))
+			return rendered, err
+		}
+		for _, text := range pending {
+			r, err := s.Render(ctx, text.Source, utf8.RuneCountInString(text.Source))
+			if err != nil {
+				return rendered, err
+			}
+			if err := s.contentRepo.SaveRendered(ctx, field, text.ID, r.HTML); err != nil {
+				s.log.Error("failed to save rendered text", zap.String("field", string(field)), zap.String("id", text.ID), zap.Error(err))
+				return rendered, err
+			}
+			rendered++
+		}
+	}
+	return rendered, nil
+}
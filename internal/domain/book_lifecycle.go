@@ -0,0 +1,171 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// BookAction names a step in a book's lifecycle
+type BookAction string
+
+const (
+	BookCreated           BookAction = "created"
+	BookApproved          BookAction = "approved"
+	BookDelivered         BookAction = "delivered"
+	BookCompleted         BookAction = "completed"
+	BookReturned          BookAction = "returned"
+	BookHandoverCancelled BookAction = "handover_cancelled"
+	BookOverridden        BookAction = "admin_override"
+)
+
+// BookActor is the role of whoever triggers a status change
+type BookActor string
+
+const (
+	BookActorAdmin BookActor = "admin"
+	// BookActorHolder has the physical copy
+	BookActorHolder BookActor = "holder"
+	// BookActorReader is the next reader receiving the copy
+	BookActorReader BookActor = "reader"
+	BookActorSystem BookActor = "system"
+)
+
+// BookHolderEffect says what a transition does to books.current_holder_id
+type BookHolderEffect int
+
+const (
+	HolderKeep BookHolderEffect = iota
+	// HolderSet makes the triggering user the holder
+	HolderSet
+	HolderClear
+)
+
+// BookTransition is one allowed move between statuses
+type BookTransition struct {
+	From   []BookStatus
+	To     []BookStatus
+	By     []BookActor
+	Holder BookHolderEffect
+}
+
+// BookTransitions is the book lifecycle. Every status write goes through
+// one of these; anything else is rejected with a BookTransitionError.
+var BookTransitions = map[BookAction]BookTransition{
+	// An admin approves a request for a book nobody is reading
+	BookApproved: {
+		From: []BookStatus{StatusAvailable, StatusOnHold},
+		To:   []BookStatus{StatusRequested},
+		By:   []BookActor{BookActorAdmin},
+	},
+	// The next reader confirms they have the copy. From reading when one
+	// reader hands straight to the next.
+	BookDelivered: {
+		From:   []BookStatus{StatusRequested, StatusReading},
+		To:     []BookStatus{StatusReading},
+		By:     []BookActor{BookActorReader},
+		Holder: HolderSet,
+	},
+	// The reader finishes with nobody waiting and keeps the copy
+	BookCompleted: {
+		From: []BookStatus{StatusReading},
+		To:   []BookStatus{StatusOnHold},
+		By:   []BookActor{BookActorHolder},
+	},
+	BookReturned: {
+		From:   []BookStatus{StatusReading, StatusOnHold},
+		To:     []BookStatus{StatusAvailable},
+		By:     []BookActor{BookActorHolder},
+		Holder: HolderClear,
+	},
+	// A handover was called off with nobody else waiting
+	BookHandoverCancelled: {
+		From: []BookStatus{StatusRequested, StatusReading},
+		To:   []BookStatus{StatusOnHold, StatusAvailable},
+		By:   []BookActor{BookActorHolder, BookActorReader, BookActorAdmin},
+	},
+	// Admins can park, reserve or release a book but never put it in a
+	// handover state, which needs a request and a thread behind it
+	BookOverridden: {
+		From: []BookStatus{StatusAvailable, StatusReading, StatusReserved, StatusRequested, StatusOnHold},
+		To:   []BookStatus{StatusAvailable, StatusReserved, StatusOnHold},
+		By:   []BookActor{BookActorAdmin},
+	},
+}
+
+// InitialBookStatus is the status a new book is created with
+const InitialBookStatus = StatusAvailable
+
+var bookStatuses = []BookStatus{StatusAvailable, StatusReading, StatusReserved, StatusRequested, StatusOnHold}
+
+func (s BookStatus) Valid() bool {
+	for _, status := range bookStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckBookTransition returns ErrForbidden if actor may not perform action
+// and a *BookTransitionError if the move from→to isn't part of it
+func CheckBookTransition(action BookAction, from, to BookStatus, actor BookActor) (BookTransition, error) {
+	t, ok := BookTransitions[action]
+	if !ok {
+		return t, &BookTransitionError{Action: action, From: from, To: to}
+	}
+	if !containsActor(t.By, actor) {
+		return t, ErrForbidden
+	}
+	if !containsStatus(t.From, from) || !containsStatus(t.To, to) || (from == to && t.Holder != HolderSet) {
+		return t, &BookTransitionError{Action: action, From: from, To: to}
+	}
+	return t, nil
+}
+
+// BookTransitionError reports a status change the lifecycle doesn't allow
+type BookTransitionError struct {
+	Action BookAction
+	From   BookStatus
+	To     BookStatus
+}
+
+func (e *BookTransitionError) Error() string {
+	return fmt.Sprintf("book cannot go from %s to %s (%s)", e.From, e.To, e.Action)
+}
+
+// Is lets errors.Is match any transition error against ErrIllegalBookTransition
+func (e *BookTransitionError) Is(target error) bool {
+	return target == ErrIllegalBookTransition
+}
+
+// BookEvent is one entry in a book's lifecycle log
+type BookEvent struct {
+	ID         string     `json:"id"`
+	BookID     string     `json:"book_id"`
+	Action     BookAction `json:"action"`
+	FromStatus BookStatus `json:"from_status,omitempty"`
+	ToStatus   BookStatus `json:"to_status"`
+	Actor      BookActor  `json:"actor"`
+	ActorID    *string    `json:"actor_id,omitempty"`
+	HolderID   *string    `json:"holder_id,omitempty"`
+	Note       string     `json:"note,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func containsStatus(list []BookStatus, s BookStatus) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func containsActor(list []BookActor, a BookActor) bool {
+	for _, v := range list {
+		if v == a {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package domain
+
+import "time"
+
+// BookTimelineKind is the type of a custody timeline entry
+type BookTimelineKind string
+
+const (
+	TimelineCreated           BookTimelineKind = "created"
+	TimelineDonated           BookTimelineKind = "donated"
+	TimelineRequested         BookTimelineKind = "requested"
+	TimelineApproved          BookTimelineKind = "approved"
+	TimelineHandoverStarted   BookTimelineKind = "handover_started"
+	TimelineDelivered         BookTimelineKind = "delivered"
+	TimelineCompleted         BookTimelineKind = "completed"
+	TimelineReturned          BookTimelineKind = "returned"
+	TimelineHandoverCancelled BookTimelineKind = "handover_cancelled"
+	// TimelineReported is a handover cancelled because a participant
+	// stopped responding
+	TimelineReported      BookTimelineKind = "reported"
+	TimelineStatusChanged BookTimelineKind = "status_changed"
+)
+
+// TimelineActor is someone involved in a timeline entry. UserID and
+// Username are left out of the anonymized view.
+type TimelineActor struct {
+	Role     string  `json:"role"`
+	UserID   *string `json:"user_id,omitempty"`
+	Username string  `json:"username,omitempty"`
+}
+
+// BookTimelineEvent is one entry in a book's custody timeline
+type BookTimelineEvent struct {
+	Kind   BookTimelineKind `json:"kind"`
+	At     time.Time        `json:"at"`
+	Actors []TimelineActor  `json:"actors"`
+	// Status is the status the book moved to, for status changes
+	Status BookStatus `json:"status,omitempty"`
+	// Detail is a cancellation reason or admin note
+	Detail string `json:"detail,omitempty"`
+	// RefID is the request or handover thread behind the entry
+	RefID string `json:"ref_id,omitempty"`
+}
@@ -9,13 +9,32 @@ type UserBookmark struct {
 	Book          *Book        `json:"book,omitempty"`
 	BookmarkType  BookmarkType `json:"bookmark_type"`
 	PriorityLevel int          `json:"priority_level"`
-	CreatedAt     time.Time    `json:"created_at"`
+	// AutoRequest files a request for a wishlisted book as soon as it frees
+	// up instead of only notifying
+	AutoRequest bool `json:"auto_request"`
+	// AlertedAt is when the watcher was last told the book freed up
+	AlertedAt *time.Time `json:"alerted_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
 }
 
 type BookmarkType string
 
 const (
+	// BookmarkTypeWishlist entries are watches: the user is alerted when
+	// the book becomes available or on hold
 	BookmarkTypeWishlist BookmarkType = "wishlist"
 	BookmarkTypeFavorite BookmarkType = "favorite"
 	BookmarkTypeReading  BookmarkType = "reading"
 )
+
+// MaxBookmarkPriority is the highest priority_level a wishlist entry can
+// have. Higher levels raise the score of the user's request for the book.
+const MaxBookmarkPriority = 5
+
+func (t BookmarkType) Valid() bool {
+	switch t {
+	case BookmarkTypeWishlist, BookmarkTypeFavorite, BookmarkTypeReading:
+		return true
+	}
+	return false
+}
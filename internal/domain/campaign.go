@@ -0,0 +1,40 @@
+package domain
+
+import "time"
+
+// Campaign is a donation drive with a goal, such as "Fund 100 books for the
+// Sylhet branch". GoalType says which donations count towards it: money
+// campaigns have a GoalAmount, book campaigns a GoalBooks count.
+type Campaign struct {
+	ID          string       `json:"id"`
+	Title       string       `json:"title"`
+	Description string       `json:"description,omitempty"`
+	GoalType    DonationType `json:"goal_type"`
+	GoalAmount  *float64     `json:"goal_amount,omitempty"`
+	GoalBooks   *int         `json:"goal_books,omitempty"`
+	Currency    string       `json:"currency,omitempty"`
+	// Category is the kind of book wanted, if any; users interested in it
+	// are told when the campaign is created
+	Category  string            `json:"category,omitempty"`
+	StartsAt  time.Time         `json:"starts_at"`
+	EndsAt    time.Time         `json:"ends_at"`
+	CreatedBy string            `json:"created_by"`
+	Progress  *CampaignProgress `json:"progress,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// CampaignProgress totals the fulfilled donations of a campaign: confirmed
+// money and catalogued books
+type CampaignProgress struct {
+	Raised          float64 `json:"raised"`
+	BooksCatalogued int     `json:"books_catalogued"`
+	Donors          int     `json:"donors"`
+	// Percent of the goal reached; may pass 100
+	Percent float64 `json:"percent"`
+}
+
+// OpenAt reports whether the campaign accepts donations at t
+func (c *Campaign) OpenAt(t time.Time) bool {
+	return !t.Before(c.StartsAt) && t.Before(c.EndsAt)
+}
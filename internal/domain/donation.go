@@ -3,17 +3,36 @@ package domain
 import "time"
 
 type Donation struct {
-	ID           string       `json:"id"`
-	DonorID      string       `json:"donor_id"`
-	Donor        *User        `json:"donor,omitempty"`
-	DonationType DonationType `json:"donation_type"`
-	BookID       *string      `json:"book_id,omitempty"`
-	Book         *Book        `json:"book,omitempty"`
-	Amount       *float64     `json:"amount,omitempty"`
-	Currency     string       `json:"currency,omitempty"`
-	Message      string       `json:"message,omitempty"`
-	IsPublic     bool         `json:"is_public"`
-	CreatedAt    time.Time    `json:"created_at"`
+	ID           string         `json:"id"`
+	DonorID      string         `json:"donor_id"`
+	Donor        *User          `json:"donor,omitempty"`
+	DonationType DonationType   `json:"donation_type"`
+	Status       DonationStatus `json:"status"`
+	// Pledge describes the book a donor promised, for book donations
+	Pledge       *BookPledge `json:"pledge,omitempty"`
+	BookID       *string     `json:"book_id,omitempty"`
+	Book         *Book       `json:"book,omitempty"`
+	Amount       *float64    `json:"amount,omitempty"`
+	Currency     string      `json:"currency,omitempty"`
+	Message      string      `json:"message,omitempty"`
+	IsPublic     bool        `json:"is_public"`
+	CampaignID   *string     `json:"campaign_id,omitempty"`
+	ReceivedBy   *string     `json:"received_by,omitempty"`
+	ReceivedAt   *time.Time  `json:"received_at,omitempty"`
+	CataloguedAt *time.Time  `json:"catalogued_at,omitempty"`
+	CancelReason string      `json:"cancel_reason,omitempty"`
+	// Payment fields are set for money donations paid through a provider
+	PaymentProvider  string     `json:"payment_provider,omitempty"`
+	PaymentSessionID string     `json:"-"`
+	PaymentRef       string     `json:"payment_ref,omitempty"`
+	ConfirmedAt      *time.Time `json:"confirmed_at,omitempty"`
+	RefundedAt       *time.Time `json:"refunded_at,omitempty"`
+	RefundID         string     `json:"refund_id,omitempty"`
+	// CheckoutURL is where the donor pays; only returned on creation
+	CheckoutURL string `json:"checkout_url,omitempty"`
+	// ScoredAt is when the donor was awarded points for this donation
+	ScoredAt  *time.Time `json:"scored_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
 }
 
 type DonationType string
@@ -22,3 +41,29 @@ const (
 	DonationTypeBook  DonationType = "book"
 	DonationTypeMoney DonationType = "money"
 )
+
+// DonationStatus tracks a donation from promise to use. Book donations go
+// pledged → received → catalogued and can be cancelled before cataloguing.
+// Money donations go pending → confirmed → refunded, or pending → failed.
+type DonationStatus string
+
+const (
+	DonationPledged    DonationStatus = "pledged"
+	DonationReceived   DonationStatus = "received"
+	DonationCatalogued DonationStatus = "catalogued"
+	DonationCancelled  DonationStatus = "cancelled"
+	DonationPending    DonationStatus = "pending"
+	DonationConfirmed  DonationStatus = "confirmed"
+	DonationFailed     DonationStatus = "failed"
+	DonationRefunded   DonationStatus = "refunded"
+)
+
+// BookPledge is what the donor says they will bring in
+type BookPledge struct {
+	Title       string `json:"title"`
+	Author      string `json:"author"`
+	ISBN        string `json:"isbn,omitempty"`
+	Category    string `json:"category,omitempty"`
+	Description string `json:"description,omitempty"`
+	Condition   string `json:"condition,omitempty"`
+}
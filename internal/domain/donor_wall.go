@@ -0,0 +1,60 @@
+package domain
+
+import "time"
+
+// WallPeriod is the window the donor wall ranks donors over
+type WallPeriod string
+
+const (
+	WallMonth   WallPeriod = "month"
+	WallYear    WallPeriod = "year"
+	WallAllTime WallPeriod = "all"
+)
+
+// DonorProfile is the public part of a donor's account
+type DonorProfile struct {
+	UserID    string `json:"user_id"`
+	Username  string `json:"username"`
+	FullName  string `json:"full_name,omitempty"`
+	AvatarURL string `json:"avatar_url,omitempty"`
+}
+
+// DonorWall shows fulfilled public donations. Donations the donor marked
+// private are left out of both lists.
+type DonorWall struct {
+	Period WallPeriod `json:"period"`
+	// Since is the start of the period; nil for all time
+	Since *time.Time `json:"since,omitempty"`
+	// Currency is the currency TopDonors' amounts are totalled in
+	Currency  string          `json:"currency"`
+	TopDonors []*TopDonor     `json:"top_donors"`
+	Recent    []*WallDonation `json:"recent"`
+}
+
+type TopDonor struct {
+	Donor     DonorProfile `json:"donor"`
+	Amount    float64      `json:"amount"`
+	Books     int          `json:"books"`
+	Donations int          `json:"donations"`
+}
+
+// WallDonation is a donation as shown on the wall. Book donations carry the
+// book they were catalogued as.
+type WallDonation struct {
+	ID           string       `json:"id"`
+	DonationType DonationType `json:"donation_type"`
+	Donor        DonorProfile `json:"donor"`
+	Amount       *float64     `json:"amount,omitempty"`
+	Currency     string       `json:"currency,omitempty"`
+	Message      string       `json:"message,omitempty"`
+	Book         *WallBook    `json:"book,omitempty"`
+	CampaignID   *string      `json:"campaign_id,omitempty"`
+	DonatedAt    time.Time    `json:"donated_at"`
+}
+
+type WallBook struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Author   string `json:"author"`
+	CoverURL string `json:"cover_url,omitempty"`
+}
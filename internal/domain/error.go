@@ -10,6 +10,7 @@ var (
 	ErrUnauthorized   = errors.New("unauthorized")
 	ErrForbidden      = errors.New("forbidden")
 	ErrInternalServer = errors.New("internal server error")
+	ErrContentTooLong = errors.New("text is too long")
 
 	// Auth errors
 	ErrInvalidCredentials = errors.New("invalid credentials")
@@ -25,8 +26,33 @@ var (
 	ErrBookAlreadyRequested = errors.New("you already have a pending request for this book")
 	ErrAlreadyRequested     = errors.New("already requested")
 	ErrInvalidBookStatus    = errors.New("invalid book status")
+	ErrRequestNotPending    = errors.New("this request is no longer pending")
+	// ErrIllegalBookTransition matches every *BookTransitionError
+	ErrIllegalBookTransition = errors.New("illegal book status transition")
 
 	// User errors
 	ErrUserNotFound      = errors.New("user not found")
 	ErrInsufficientScore = errors.New("insufficient success score")
+
+	// Review errors
+	ErrReviewNotAllowed   = errors.New("reviews are only allowed between participants of a completed handover")
+	ErrReviewWindowClosed = errors.New("the review window for this handover has closed")
+	ErrAlreadyReviewed    = errors.New("you have already reviewed this handover")
+
+	// Handover errors
+	ErrHandoverNotActive  = errors.New("this handover is no longer active")
+	ErrHandoverNotOverdue = errors.New("a participant can only be reported unresponsive after the handover due date")
+
+	// Donation errors
+	ErrDonationStatus = errors.New("this donation can't be changed in its current state")
+	ErrCampaignClosed = errors.New("this campaign is not accepting donations")
+	ErrOutflowVoided  = errors.New("this ledger entry has already been voided")
+
+	// Shelf errors
+	ErrShelfLimit = errors.New("shelf limit reached")
+
+	// Idea errors
+	ErrSelfVote            = errors.New("you cannot vote on your own idea")
+	ErrCommentNotAllowed   = errors.New("only members who have read this book can comment on its ideas")
+	ErrCommentWindowClosed = errors.New("this comment can no longer be changed")
 )
@@ -15,6 +15,13 @@ type HandoverThread struct {
 	CompletedAt      *time.Time `json:"completed_at,omitempty"`
 	UpdatedAt        time.Time  `json:"updated_at"`
 
+	// Set when the thread is cancelled
+	CancelledBy  *string              `json:"cancelled_by,omitempty"`
+	CancelReason HandoverCancelReason `json:"cancel_reason,omitempty"`
+	CancelNote   string               `json:"cancel_note,omitempty"`
+	AtFaultID    *string              `json:"at_fault_id,omitempty"`
+	CancelledAt  *time.Time           `json:"cancelled_at,omitempty"`
+
 	// Populated fields
 	Book          *Book             `json:"book,omitempty"`
 	CurrentHolder *User             `json:"current_holder,omitempty"`
@@ -30,6 +37,14 @@ type HandoverMessage struct {
 	IsSystemMessage bool      `json:"is_system_message"`
 	CreatedAt       time.Time `json:"created_at"`
 
+	// System messages are rendered from the i18n catalog in the viewer's
+	// locale; Message holds the English text
+	MessageKey    string            `json:"message_key,omitempty"`
+	MessageParams map[string]string `json:"message_params,omitempty"`
+
+	// MessageHTML is a member's message rendered to sanitized HTML
+	MessageHTML string `json:"message_html,omitempty"`
+
 	// Populated fields
 	User *User `json:"user,omitempty"`
 }
@@ -60,3 +75,36 @@ const (
 	HandoverCompleted HandoverThreadStatus = "completed"
 	HandoverCancelled HandoverThreadStatus = "cancelled"
 )
+
+// HandoverCancelReason says why a handover was called off
+type HandoverCancelReason string
+
+const (
+	// CancelWithdrawn: the cancelling participant backs out
+	CancelWithdrawn HandoverCancelReason = "withdrawn"
+	// CancelUnresponsive: the other participant stopped responding
+	CancelUnresponsive HandoverCancelReason = "unresponsive"
+	CancelOther        HandoverCancelReason = "other"
+)
+
+func (r HandoverCancelReason) Valid() bool {
+	switch r {
+	case CancelWithdrawn, CancelUnresponsive, CancelOther:
+		return true
+	}
+	return false
+}
+
+// HandoverEvent is pushed to participants streaming a handover thread
+type HandoverEvent struct {
+	Type    HandoverEventType `json:"type"`
+	Message *HandoverMessage  `json:"message,omitempty"`
+	Status  string            `json:"status,omitempty"`
+}
+
+type HandoverEventType string
+
+const (
+	HandoverEventMessage HandoverEventType = "message"
+	HandoverEventStatus  HandoverEventType = "status"
+)
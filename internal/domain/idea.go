@@ -14,14 +14,73 @@ type ReadingIdea struct {
 	Downvotes int       `json:"downvotes"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	// CommentCount excludes deleted comments
+	CommentCount int `json:"comment_count"`
+	// EditedAt is set once the author has edited the idea
+	EditedAt *time.Time `json:"edited_at,omitempty"`
+	// ContentHTML is Content rendered to sanitized HTML
+	ContentHTML string `json:"content_html,omitempty"`
 }
 
-type IdeaVote struct {
+// IdeaRevision is the version of an idea that an edit replaced
+type IdeaRevision struct {
 	ID        string    `json:"id"`
 	IdeaID    string    `json:"idea_id"`
-	UserID    string    `json:"user_id"`
-	VoteType  VoteType  `json:"vote_type"`
+	Title     string    `json:"title"`
+	Content   string    `json:"content"`
 	CreatedAt time.Time `json:"created_at"`
+	// ContentHTML is Content rendered to sanitized HTML
+	ContentHTML string `json:"content_html,omitempty"`
+}
+
+// IdeaFeedSort orders the global ideas feed
+type IdeaFeedSort string
+
+const (
+	// IdeaFeedTop ranks the last week's ideas by net votes
+	IdeaFeedTop IdeaFeedSort = "top"
+	// IdeaFeedNew lists the newest ideas first
+	IdeaFeedNew IdeaFeedSort = "new"
+	// IdeaFeedBest ranks all ideas by the lower bound of the Wilson score
+	// interval, so a few unanimous votes don't outrank many mostly positive
+	// ones
+	IdeaFeedBest IdeaFeedSort = "best"
+)
+
+func (s IdeaFeedSort) Valid() bool {
+	switch s {
+	case IdeaFeedTop, IdeaFeedNew, IdeaFeedBest:
+		return true
+	}
+	return false
+}
+
+// IdeaComment is a comment on a reading idea. ParentID is set on replies.
+// Deleted comments keep their place in the thread with the content blanked
+// while they still have replies.
+type IdeaComment struct {
+	ID        string         `json:"id"`
+	IdeaID    string         `json:"idea_id"`
+	ParentID  *string        `json:"parent_id,omitempty"`
+	UserID    string         `json:"user_id,omitempty"`
+	Username  string         `json:"username,omitempty"`
+	Depth     int            `json:"depth"`
+	Content   string         `json:"content"`
+	Replies   []*IdeaComment `json:"replies,omitempty"`
+	EditedAt  *time.Time     `json:"edited_at,omitempty"`
+	DeletedAt *time.Time     `json:"deleted_at,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+	// ContentHTML is Content rendered to sanitized HTML
+	ContentHTML string `json:"content_html,omitempty"`
+}
+
+type IdeaVote struct {
+	ID           string    `json:"id"`
+	IdeaID       string    `json:"idea_id"`
+	UserID       string    `json:"user_id"`
+	VoteType     VoteType  `json:"vote_type"`
+	ScoreAwarded int       `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
 }
 
 type VoteType string
@@ -30,3 +89,23 @@ const (
 	VoteTypeUp   VoteType = "upvote"
 	VoteTypeDown VoteType = "downvote"
 )
+
+// VoteRing is a group of accounts flagged for upvoting each other's ideas
+type VoteRing struct {
+	ID         string         `json:"id"`
+	MemberIDs  []string       `json:"member_ids"`
+	Usernames  []string       `json:"usernames,omitempty"`
+	VoteCount  int            `json:"vote_count"`
+	Status     VoteRingStatus `json:"status"`
+	DetectedAt time.Time      `json:"detected_at"`
+	ReviewedBy *string        `json:"reviewed_by,omitempty"`
+	ReviewedAt *time.Time     `json:"reviewed_at,omitempty"`
+}
+
+type VoteRingStatus string
+
+const (
+	VoteRingPending   VoteRingStatus = "pending"
+	VoteRingConfirmed VoteRingStatus = "confirmed"
+	VoteRingDismissed VoteRingStatus = "dismissed"
+)
@@ -0,0 +1,54 @@
+package domain
+
+import "time"
+
+// OutflowCategory says what money was spent on
+type OutflowCategory string
+
+const (
+	OutflowBooks      OutflowCategory = "books"
+	OutflowShipping   OutflowCategory = "shipping"
+	OutflowOperations OutflowCategory = "operations"
+	OutflowEvents     OutflowCategory = "events"
+	OutflowOther      OutflowCategory = "other"
+)
+
+// LedgerOutflow is spending recorded by an admin. Entries are never
+// deleted; a mistaken one is voided with a reason and stays listed.
+type LedgerOutflow struct {
+	ID          string          `json:"id"`
+	Amount      float64         `json:"amount"`
+	Currency    string          `json:"currency"`
+	Category    OutflowCategory `json:"category"`
+	Description string          `json:"description"`
+	SpentOn     time.Time       `json:"spent_on"`
+	CampaignID  *string         `json:"campaign_id,omitempty"`
+	RecordedBy  string          `json:"recorded_by"`
+	VoidedAt    *time.Time      `json:"voided_at,omitempty"`
+	VoidedBy    *string         `json:"voided_by,omitempty"`
+	VoidReason  string          `json:"void_reason,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// Ledger is the public account of a calendar year. Inflows are confirmed
+// money donations, public or not, net of refunds; outflows leave out voided
+// entries. Amounts are per currency.
+type Ledger struct {
+	Year     int                `json:"year"`
+	Months   []*LedgerMonth     `json:"months"`
+	Inflows  map[string]float64 `json:"inflows"`
+	Outflows map[string]float64 `json:"outflows"`
+	Balance  map[string]float64 `json:"balance"`
+	// Books is the number of donated books catalogued in the year
+	Books   int              `json:"books"`
+	Entries []*LedgerOutflow `json:"entries"`
+}
+
+type LedgerMonth struct {
+	// Month is "2026-01"
+	Month     string             `json:"month"`
+	Inflows   map[string]float64 `json:"inflows"`
+	Outflows  map[string]float64 `json:"outflows"`
+	Donations int                `json:"donations"`
+	Books     int                `json:"books"`
+}
@@ -2,23 +2,202 @@ package domain
 
 import "time"
 
+// NotificationVersion is the schema version of newly created
+// notifications. Version 1 rows predate payloads and only carry Link.
+const NotificationVersion = 2
+
 type Notification struct {
+	ID         string              `json:"id"`
+	UserID     string              `json:"user_id"`
+	Type       NotificationType    `json:"type"`
+	Version    int                 `json:"version"`
+	Title      string              `json:"title"`
+	Message    string              `json:"message"`
+	Link       string              `json:"link,omitempty"`
+	Payload    NotificationPayload `json:"payload"`
+	IsRead     bool                `json:"is_read"`
+	ArchivedAt *time.Time          `json:"archived_at,omitempty"`
+	CreatedAt  time.Time           `json:"created_at"`
+}
+
+// NotificationPayload carries the identifiers a client needs to act on a
+// notification. Which fields are set depends on the type.
+type NotificationPayload struct {
+	BookID   string `json:"book_id,omitempty"`
+	ThreadID string `json:"thread_id,omitempty"`
+	IdeaID   string `json:"idea_id,omitempty"`
+	ReviewID string `json:"review_id,omitempty"`
+	Upvote   *bool  `json:"upvote,omitempty"`
+	DaysLeft *int   `json:"days_left,omitempty"`
+	Outcome  string `json:"outcome,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+	// CampaignID is set for campaign_launched
+	CampaignID string `json:"campaign_id,omitempty"`
+	// CommentID is set for idea_comment
+	CommentID string `json:"comment_id,omitempty"`
+	// UserID is set for a mention in a user's bio
+	UserID string `json:"user_id,omitempty"`
+}
+
+// MentionSourceType is the kind of user-written text a mention was made in
+type MentionSourceType string
+
+const (
+	MentionInIdea     MentionSourceType = "idea"
+	MentionInReview   MentionSourceType = "review"
+	MentionInHandover MentionSourceType = "handover"
+	MentionInBio      MentionSourceType = "bio"
+)
+
+// MentionSource locates the text a user was mentioned in
+type MentionSource struct {
+	Type MentionSourceType
+	// ID is the idea, review or handover thread, or for a bio its user
+	ID string
+	// CommentID is set for a mention in a comment on an idea
+	CommentID string
+	// BookID is set for a mention in a handover message
+	BookID string
+	// Title is the idea's title, or the title of the book handed over
+	Title string
+}
+
+// NotificationType is a closed set; adding a type needs a migration that
+// extends the notifications_type_check constraint.
+type NotificationType string
+
+const (
+	NotificationIdeaVote              NotificationType = "idea_vote"
+	NotificationReviewReceived        NotificationType = "review_received"
+	NotificationReviewDisputeResolved NotificationType = "review_dispute_resolved"
+	NotificationBookAvailable         NotificationType = "book_available"
+	NotificationRequestApproved       NotificationType = "request_approved"
+	NotificationReturnDue             NotificationType = "return_due"
+	NotificationBookInTransit         NotificationType = "book_in_transit"
+	NotificationBookDelivered         NotificationType = "book_delivered"
+	NotificationHandoverThread        NotificationType = "handover_thread"
+	NotificationHandoverMessage       NotificationType = "handover_message"
+	NotificationHandoverCancelled     NotificationType = "handover_cancelled"
+	NotificationCampaignLaunched      NotificationType = "campaign_launched"
+	NotificationIdeaComment           NotificationType = "idea_comment"
+	NotificationMention               NotificationType = "mention"
+	NotificationDonationReceived      NotificationType = "donation_received"
+	// NotificationSystem holds notifications of legacy types that no longer
+	// exist. Nothing new is sent with it.
+	NotificationSystem NotificationType = "system"
+)
+
+// NotificationTypes lists every notification type
+var NotificationTypes = []NotificationType{
+	NotificationIdeaVote,
+	NotificationReviewReceived,
+	NotificationReviewDisputeResolved,
+	NotificationBookAvailable,
+	NotificationRequestApproved,
+	NotificationReturnDue,
+	NotificationBookInTransit,
+	NotificationBookDelivered,
+	NotificationHandoverThread,
+	NotificationHandoverMessage,
+	NotificationHandoverCancelled,
+	NotificationCampaignLaunched,
+	NotificationIdeaComment,
+	NotificationMention,
+	NotificationDonationReceived,
+	NotificationSystem,
+}
+
+func (t NotificationType) Valid() bool {
+	for _, known := range NotificationTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// NotificationChannel is a medium a notification is delivered over
+type NotificationChannel string
+
+const (
+	ChannelInApp NotificationChannel = "in_app"
+	ChannelEmail NotificationChannel = "email"
+	ChannelSMS   NotificationChannel = "sms"
+	ChannelPush  NotificationChannel = "push"
+)
+
+type NotificationPreference struct {
+	Type    NotificationType    `json:"type"`
+	Channel NotificationChannel `json:"channel"`
+	Enabled bool                `json:"enabled"`
+}
+
+// NotificationSettings holds a user's delivery addresses, quiet hours and
+// digest mode. Quiet hours are "HH:MM" in Timezone and may wrap past
+// midnight.
+type NotificationSettings struct {
+	UserID          string          `json:"-"`
+	SMSNumber       string          `json:"sms_number,omitempty"`
+	QuietHoursStart string          `json:"quiet_hours_start,omitempty"`
+	QuietHoursEnd   string          `json:"quiet_hours_end,omitempty"`
+	Timezone        string          `json:"timezone"`
+	DigestFrequency DigestFrequency `json:"digest_frequency"`
+}
+
+type DigestFrequency string
+
+const (
+	DigestOff    DigestFrequency = "off"
+	DigestDaily  DigestFrequency = "daily"
+	DigestWeekly DigestFrequency = "weekly"
+)
+
+// DigestItem is a low-priority notification waiting for the user's next
+// digest
+type DigestItem struct {
 	ID        string           `json:"id"`
 	UserID    string           `json:"user_id"`
 	Type      NotificationType `json:"type"`
 	Title     string           `json:"title"`
 	Message   string           `json:"message"`
 	Link      string           `json:"link,omitempty"`
-	IsRead    bool             `json:"is_read"`
 	CreatedAt time.Time        `json:"created_at"`
 }
 
-type NotificationType string
+// NotificationDigest records a sent digest. PeriodKey ("2026-10-18" or
+// "2026-W42") is unique per user, so a period is never summarised twice.
+type NotificationDigest struct {
+	ID        string          `json:"id"`
+	UserID    string          `json:"user_id"`
+	Frequency DigestFrequency `json:"frequency"`
+	PeriodKey string          `json:"period_key"`
+	ItemCount int             `json:"item_count"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// NotificationDelivery is one outbox entry: a notification queued for an
+// external channel
+type NotificationDelivery struct {
+	ID             string              `json:"id"`
+	NotificationID *string             `json:"notification_id,omitempty"`
+	UserID         string              `json:"user_id"`
+	Channel        NotificationChannel `json:"channel"`
+	Type           NotificationType    `json:"type"`
+	Title          string              `json:"title"`
+	Message        string              `json:"message"`
+	Link           string              `json:"link,omitempty"`
+	Status         OutboxStatus        `json:"status"`
+	Attempts       int                 `json:"attempts"`
+	LastError      string              `json:"last_error,omitempty"`
+	NextAttemptAt  time.Time           `json:"next_attempt_at"`
+	SentAt         *time.Time          `json:"sent_at,omitempty"`
+	CreatedAt      time.Time           `json:"created_at"`
+}
+
+type OutboxStatus string
 
 const (
-	NotificationTypeBookAvailable NotificationType = "book_available"
-	NotificationTypeBookDue       NotificationType = "book_due"
-	NotificationTypeReview        NotificationType = "review"
-	NotificationTypeIdea          NotificationType = "idea"
-	NotificationTypeDonation      NotificationType = "donation"
+	OutboxPending OutboxStatus = "pending"
+	OutboxSent    OutboxStatus = "sent"
+	OutboxFailed  OutboxStatus = "failed"
 )
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// DonationReceipt is proof of a confirmed money donation or a catalogued
+// book donation. Numbers come from a single counter with no gaps. The donor
+// and donation details are copied in when the receipt is issued and only
+// change when an admin regenerates it.
+type DonationReceipt struct {
+	ID           string       `json:"id"`
+	Number       int64        `json:"number"`
+	Code         string       `json:"code"`
+	DonationID   string       `json:"donation_id"`
+	DonorID      string       `json:"donor_id"`
+	DonorName    string       `json:"donor_name"`
+	DonationType DonationType `json:"donation_type"`
+	Amount       *float64     `json:"amount,omitempty"`
+	Currency     string       `json:"currency,omitempty"`
+	BookTitle    string       `json:"book_title,omitempty"`
+	// DonatedAt is when the payment was confirmed or the book catalogued
+	DonatedAt     time.Time  `json:"donated_at"`
+	IssuedAt      time.Time  `json:"issued_at"`
+	RegeneratedAt *time.Time `json:"regenerated_at,omitempty"`
+	// VoidedAt is set when the donation is refunded; the number stays used
+	VoidedAt   *time.Time `json:"voided_at,omitempty"`
+	VoidReason string     `json:"void_reason,omitempty"`
+}
+
+// ReceiptCode formats a receipt number for display
+func ReceiptCode(number int64) string {
+	return fmt.Sprintf("AP-%06d", number)
+}
+
+// DonorStatement summarises a donor's receipts for one calendar year.
+// Voided receipts are left out.
+type DonorStatement struct {
+	DonorID   string             `json:"donor_id"`
+	DonorName string             `json:"donor_name"`
+	Year      int                `json:"year"`
+	Receipts  []*DonationReceipt `json:"receipts"`
+	// Totals is the money donated per currency
+	Totals      map[string]float64 `json:"totals"`
+	Books       int                `json:"books"`
+	GeneratedAt time.Time          `json:"generated_at"`
+}
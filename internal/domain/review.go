@@ -9,9 +9,34 @@ type UserReview struct {
 	RevieweeID          string    `json:"reviewee_id"`
 	Reviewee            *User     `json:"reviewee,omitempty"`
 	BookID              *string   `json:"book_id,omitempty"`
+	HandoverThreadID    *string   `json:"handover_thread_id,omitempty"`
 	BehaviorRating      *int      `json:"behavior_rating,omitempty"`
 	BookConditionRating *int      `json:"book_condition_rating,omitempty"`
 	CommunicationRating *int      `json:"communication_rating,omitempty"`
 	Comment             string    `json:"comment,omitempty"`
+	CommentHTML         string    `json:"comment_html,omitempty"`
 	CreatedAt           time.Time `json:"created_at"`
 }
+
+// ReviewDispute is a reviewee's challenge to a review, resolved by an admin
+type ReviewDispute struct {
+	ID             string        `json:"id"`
+	ReviewID       string        `json:"review_id"`
+	Review         *UserReview   `json:"review,omitempty"`
+	DisputerID     string        `json:"disputer_id"`
+	Statement      string        `json:"statement"`
+	Status         DisputeStatus `json:"status"`
+	ResolutionNote string        `json:"resolution_note,omitempty"`
+	ResolvedBy     *string       `json:"resolved_by,omitempty"`
+	ResolvedAt     *time.Time    `json:"resolved_at,omitempty"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
+type DisputeStatus string
+
+const (
+	DisputePending DisputeStatus = "pending"
+	DisputeUpheld  DisputeStatus = "upheld"
+	DisputeEdited  DisputeStatus = "edited"
+	DisputeRemoved DisputeStatus = "removed"
+)
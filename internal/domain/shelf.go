@@ -0,0 +1,65 @@
+package domain
+
+import "time"
+
+// ShelfVisibility says who can see a shelf besides its owner
+type ShelfVisibility string
+
+const (
+	ShelfPrivate ShelfVisibility = "private"
+	// ShelfLink shelves are seen by anyone with the share link, and by
+	// users who followed them
+	ShelfLink   ShelfVisibility = "link"
+	ShelfPublic ShelfVisibility = "public"
+)
+
+func (v ShelfVisibility) Valid() bool {
+	switch v {
+	case ShelfPrivate, ShelfLink, ShelfPublic:
+		return true
+	}
+	return false
+}
+
+// Shelf is a named, ordered reading list
+type Shelf struct {
+	ID            string          `json:"id"`
+	OwnerID       string          `json:"owner_id"`
+	OwnerUsername string          `json:"owner_username"`
+	Name          string          `json:"name"`
+	Description   string          `json:"description,omitempty"`
+	Visibility    ShelfVisibility `json:"visibility"`
+	// ShareToken makes up the share link; only the owner sees it
+	ShareToken string `json:"share_token,omitempty"`
+	// ClonedFrom is the shelf this one was copied from
+	ClonedFrom *string      `json:"cloned_from,omitempty"`
+	BookCount  int          `json:"book_count"`
+	Followers  int          `json:"followers"`
+	Following  bool         `json:"following"`
+	Items      []*ShelfItem `json:"items,omitempty"`
+	CreatedAt  time.Time    `json:"created_at"`
+	UpdatedAt  time.Time    `json:"updated_at"`
+}
+
+// ShelfItem is a book on a shelf. Book carries the book's current status,
+// read when the shelf is loaded.
+type ShelfItem struct {
+	ID       string     `json:"id"`
+	ShelfID  string     `json:"shelf_id"`
+	BookID   string     `json:"book_id"`
+	Position int        `json:"position"`
+	Note     string     `json:"note,omitempty"`
+	Book     *ShelfBook `json:"book,omitempty"`
+	AddedAt  time.Time  `json:"added_at"`
+}
+
+type ShelfBook struct {
+	ID        string     `json:"id"`
+	Title     string     `json:"title"`
+	Author    string     `json:"author"`
+	CoverURL  string     `json:"cover_url,omitempty"`
+	Status    BookStatus `json:"status"`
+	Available bool       `json:"available"`
+	// PendingRequests is how many readers are waiting for the book
+	PendingRequests int `json:"pending_requests"`
+}
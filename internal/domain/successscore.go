@@ -0,0 +1,36 @@
+package domain
+
+import "time"
+
+type ScoreEventType string
+
+const (
+	ScoreEventReturnOnTime    ScoreEventType = "return_on_time"
+	ScoreEventReturnLate      ScoreEventType = "return_late"
+	ScoreEventPositiveReview  ScoreEventType = "positive_review"
+	ScoreEventNegativeReview  ScoreEventType = "negative_review"
+	ScoreEventIdeaPosted      ScoreEventType = "idea_posted"
+	ScoreEventIdeaUpvote      ScoreEventType = "idea_upvote"
+	ScoreEventIdeaDownvote    ScoreEventType = "idea_downvote"
+	ScoreEventLostBook        ScoreEventType = "lost_book"
+	ScoreEventBookDonated     ScoreEventType = "book_donated"
+	ScoreEventMoneyDonated    ScoreEventType = "money_donated"
+	ScoreEventHandoverCancel  ScoreEventType = "handover_cancelled"
+	ScoreEventAdminAdjustment ScoreEventType = "admin_adjustment"
+	// ScoreEventVoteReverted takes back the points of a changed or
+	// retracted vote. It has no rule and doesn't count towards caps.
+	ScoreEventVoteReverted ScoreEventType = "idea_vote_reverted"
+)
+
+// ScoreRule defines how many points an event is worth. DailyCap, when set,
+// limits the absolute points a single user can collect from the event per day.
+type ScoreRule struct {
+	ID          string         `json:"id"`
+	EventType   ScoreEventType `json:"event_type"`
+	Amount      int            `json:"amount"`
+	DailyCap    *int           `json:"daily_cap,omitempty"`
+	IsActive    bool           `json:"is_active"`
+	Description string         `json:"description,omitempty"`
+	UpdatedBy   *string        `json:"updated_by,omitempty"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
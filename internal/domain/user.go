@@ -3,27 +3,33 @@ package domain
 import "time"
 
 type User struct {
-	ID              string    `json:"id"`
-	Username        string    `json:"username"`
-	Email           string    `json:"email"`
-	PasswordHash    string    `json:"-"`
-	FullName        string    `json:"full_name"`
-	Role            UserRole  `json:"role"`
-	AvatarURL       string    `json:"avatar_url,omitempty"`
-	Bio             string    `json:"bio,omitempty"`
-	LocationLat     *float64  `json:"location_lat,omitempty"`
-	LocationLng     *float64  `json:"location_lng,omitempty"`
-	LocationAddress string    `json:"location_address,omitempty"`
-	SuccessScore    int       `json:"success_score"`
-	BooksShared     int       `json:"books_shared"`
-	BooksReceived   int       `json:"books_received"`
-	ReviewsReceived int       `json:"reviews_received"`
-	IdeasPosted     int       `json:"ideas_posted"`
-	TotalUpvotes    int       `json:"total_upvotes"`
-	TotalDownvotes  int       `json:"total_downvotes"`
-	IsDonor         bool      `json:"is_donor"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
+	ID              string   `json:"id"`
+	Username        string   `json:"username"`
+	Email           string   `json:"email"`
+	PasswordHash    string   `json:"-"`
+	FullName        string   `json:"full_name"`
+	Role            UserRole `json:"role"`
+	AvatarURL       string   `json:"avatar_url,omitempty"`
+	Bio             string   `json:"bio,omitempty"`
+	BioHTML         string   `json:"bio_html,omitempty"`
+	LocationLat     *float64 `json:"location_lat,omitempty"`
+	LocationLng     *float64 `json:"location_lng,omitempty"`
+	LocationAddress string   `json:"location_address,omitempty"`
+	SuccessScore    int      `json:"success_score"`
+	BooksShared     int      `json:"books_shared"`
+	BooksReceived   int      `json:"books_received"`
+	ReviewsReceived int      `json:"reviews_received"`
+	// Weighted averages of received review ratings, nil until rated
+	AvgBehaviorRating      *float64  `json:"avg_behavior_rating,omitempty"`
+	AvgBookConditionRating *float64  `json:"avg_book_condition_rating,omitempty"`
+	AvgCommunicationRating *float64  `json:"avg_communication_rating,omitempty"`
+	IdeasPosted            int       `json:"ideas_posted"`
+	TotalUpvotes           int       `json:"total_upvotes"`
+	TotalDownvotes         int       `json:"total_downvotes"`
+	IsDonor                bool      `json:"is_donor"`
+	PreferredLocale        string    `json:"preferred_locale"` // "en" or "bn"
+	CreatedAt              time.Time `json:"created_at"`
+	UpdatedAt              time.Time `json:"updated_at"`
 }
 
 type UserRole string
@@ -42,11 +48,13 @@ type UserInterest struct {
 }
 
 type SuccessScoreHistory struct {
-	ID            string    `json:"id"`
-	UserID        string    `json:"user_id"`
-	ChangeAmount  int       `json:"change_amount"`
-	Reason        string    `json:"reason"`
-	ReferenceType string    `json:"reference_type,omitempty"`
-	ReferenceID   *string   `json:"reference_id,omitempty"`
-	CreatedAt     time.Time `json:"created_at"`
+	ID            string         `json:"id"`
+	UserID        string         `json:"user_id"`
+	ChangeAmount  int            `json:"change_amount"`
+	EventType     ScoreEventType `json:"event_type,omitempty"`
+	Reason        string         `json:"reason"`
+	ReferenceType string         `json:"reference_type,omitempty"`
+	ReferenceID   *string        `json:"reference_id,omitempty"`
+	Link          string         `json:"link,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
 }
@@ -0,0 +1,234 @@
+package donation
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/online-library/internal/domain"
+	"go.uber.org/zap"
+)
+
+func (s *service) CreateCampaign(ctx context.Context, c *domain.Campaign) (*domain.Campaign, error) {
+	if err := validateCampaign(c); err != nil {
+		return nil, err
+	}
+	c.ID = uuid.New().String()
+	c.CreatedAt = time.Now()
+	c.UpdatedAt = c.CreatedAt
+
+	if err := s.campaignRepo.Create(ctx, c); err != nil {
+		s.log.Error("failed to create campaign", zap.Error(err))
+		return nil, err
+	}
+	s.log.Info("campaign created", zap.String("campaign_id", c.ID), zap.String("created_by", c.CreatedBy))
+
+	s.announceCampaign(ctx, c)
+	c.Progress = &domain.CampaignProgress{}
+	return c, nil
+}
+
+// UpdateCampaign edits a campaign's text, goal, dates and category. The goal
+// type can't change once the campaign exists since donations already count
+// towards it.
+func (s *service) UpdateCampaign(ctx context.Context, c *domain.Campaign) (*domain.Campaign, error) {
+	existing, err := s.campaignRepo.GetByID(ctx, c.ID)
+	if err != nil {
+		return nil, err
+	}
+	if c.GoalType != existing.GoalType {
+		return nil, domain.ErrInvalidInput
+	}
+	if err := validateCampaign(c); err != nil {
+		return nil, err
+	}
+	// Confirmed donations were taken in the campaign's currency
+	if existing.GoalType == domain.DonationTypeMoney && c.Currency != existing.Currency {
+		return nil, domain.ErrInvalidInput
+	}
+	c.CreatedBy = existing.CreatedBy
+	c.CreatedAt = existing.CreatedAt
+	c.UpdatedAt = time.Now()
+
+	if err := s.campaignRepo.Update(ctx, c); err != nil {
+		s.log.Error("failed to update campaign", zap.String("campaign_id", c.ID), zap.Error(err))
+		return nil, err
+	}
+	if err := s.withProgress(ctx, []*domain.Campaign{c}); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (s *service) GetCampaign(ctx context.Context, id string) (*domain.Campaign, error) {
+	c, err := s.campaignRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.withProgress(ctx, []*domain.Campaign{c}); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (s *service) ListCampaigns(ctx context.Context, includeEnded bool) ([]*domain.Campaign, error) {
+	endsAfter := time.Now()
+	if includeEnded {
+		endsAfter = time.Time{}
+	}
+	campaigns, err := s.campaignRepo.List(ctx, endsAfter)
+	if err != nil {
+		s.log.Error("failed to list campaigns", zap.Error(err))
+		return nil, err
+	}
+	if err := s.withProgress(ctx, campaigns); err != nil {
+		return nil, err
+	}
+	return campaigns, nil
+}
+
+// joinCampaign checks that a new donation may count towards its campaign.
+// Money donations take the campaign's currency and book pledges its
+// category unless they name one.
+func (s *service) joinCampaign(ctx context.Context, d *domain.Donation) error {
+	c, err := s.campaignRepo.GetByID(ctx, *d.CampaignID)
+	if err != nil {
+		return err
+	}
+	if !c.OpenAt(time.Now()) {
+		return domain.ErrCampaignClosed
+	}
+	if d.DonationType != c.GoalType {
+		return domain.ErrInvalidInput
+	}
+
+	switch d.DonationType {
+	case domain.DonationTypeMoney:
+		if d.Currency == "" {
+			d.Currency = c.Currency
+		} else if !strings.EqualFold(d.Currency, c.Currency) {
+			return domain.ErrInvalidInput
+		}
+	case domain.DonationTypeBook:
+		if d.Pledge != nil && d.Pledge.Category == "" {
+			d.Pledge.Category = c.Category
+		}
+	}
+	return nil
+}
+
+// announceCampaign notifies users interested in the campaign's category.
+// Campaigns without a category aren't announced.
+func (s *service) announceCampaign(ctx context.Context, c *domain.Campaign) {
+	if c.Category == "" {
+		return
+	}
+	userIDs, err := s.campaignRepo.ListInterestedUsers(ctx, c.Category)
+	if err != nil {
+		s.log.Error("failed to list users interested in campaign", zap.String("campaign_id", c.ID), zap.Error(err))
+		return
+	}
+	for _, userID := range userIDs {
+		if userID == c.CreatedBy {
+			continue
+		}
+		if err := s.notificationSvc.NotifyCampaignLaunched(ctx, userID, c.ID, c.Title); err != nil {
+			s.log.Warn("failed to notify user of campaign",
+				zap.String("campaign_id", c.ID),
+				zap.String("user_id", userID),
+				zap.Error(err))
+		}
+	}
+	s.log.Info("campaign announced", zap.String("campaign_id", c.ID), zap.Int("users", len(userIDs)))
+}
+
+// notifyCampaignOwner tells the creator of the campaign a donation was made
+// to that it has arrived. Donations to no campaign, or by the creator
+// themselves, are skipped.
+func (s *service) notifyCampaignOwner(ctx context.Context, d *domain.Donation) {
+	if d.CampaignID == nil {
+		return
+	}
+	c, err := s.campaignRepo.GetByID(ctx, *d.CampaignID)
+	if err != nil {
+		s.log.Warn("failed to load campaign of donation", zap.String("donation_id", d.ID), zap.Error(err))
+		return
+	}
+	if c.CreatedBy == d.DonorID {
+		return
+	}
+	if err := s.notificationSvc.NotifyDonationReceived(ctx, c.CreatedBy, c.ID, c.Title); err != nil {
+		s.log.Warn("failed to notify campaign owner of donation",
+			zap.String("campaign_id", c.ID),
+			zap.String("donation_id", d.ID),
+			zap.Error(err))
+	}
+}
+
+func (s *service) withProgress(ctx context.Context, campaigns []*domain.Campaign) error {
+	if len(campaigns) == 0 {
+		return nil
+	}
+	ids := make([]string, len(campaigns))
+	for i, c := range campaigns {
+		ids[i] = c.ID
+	}
+	progress, err := s.campaignRepo.Progress(ctx, ids)
+	if err != nil {
+		s.log.Error("failed to load campaign progress", zap.Error(err))
+		return err
+	}
+
+	for _, c := range campaigns {
+		p, ok := progress[c.ID]
+		if !ok {
+			p = &domain.CampaignProgress{}
+		}
+		switch {
+		case c.GoalType == domain.DonationTypeMoney && c.GoalAmount != nil:
+			p.Percent = percentOf(p.Raised, *c.GoalAmount)
+		case c.GoalType == domain.DonationTypeBook && c.GoalBooks != nil:
+			p.Percent = percentOf(float64(p.BooksCatalogued), float64(*c.GoalBooks))
+		}
+		c.Progress = p
+	}
+	return nil
+}
+
+// validateCampaign checks the goal matches its type and normalises the
+// currency
+func validateCampaign(c *domain.Campaign) error {
+	c.Title = strings.TrimSpace(c.Title)
+	c.Category = strings.TrimSpace(c.Category)
+	if c.Title == "" || !c.EndsAt.After(c.StartsAt) {
+		return domain.ErrInvalidInput
+	}
+
+	switch c.GoalType {
+	case domain.DonationTypeMoney:
+		if c.GoalAmount == nil || *c.GoalAmount <= 0 {
+			return domain.ErrInvalidInput
+		}
+		c.GoalBooks = nil
+		c.Currency = strings.ToUpper(c.Currency)
+		if c.Currency == "" {
+			c.Currency = defaultCurrency
+		}
+	case domain.DonationTypeBook:
+		if c.GoalBooks == nil || *c.GoalBooks <= 0 {
+			return domain.ErrInvalidInput
+		}
+		c.GoalAmount = nil
+		c.Currency = ""
+	default:
+		return domain.ErrInvalidInput
+	}
+	return nil
+}
+
+// percentOf rounds to one decimal place
+func percentOf(v, goal float64) float64 {
+	return math.Round(v/goal*1000) / 10
+}
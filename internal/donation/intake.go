@@ -0,0 +1,183 @@
+package donation
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/online-library/internal/domain"
+	"go.uber.org/zap"
+)
+
+// defaultReadingDays matches the default for books added directly
+const defaultReadingDays = 14
+
+func (s *service) ListByStatus(ctx context.Context, status domain.DonationStatus, limit, offset int) ([]*domain.Donation, error) {
+	return s.donationRepo.ListByStatus(ctx, status, limit, offset)
+}
+
+func (s *service) CancelPledge(ctx context.Context, donationID, donorID string) error {
+	d, err := s.bookDonation(ctx, donationID)
+	if err != nil {
+		return err
+	}
+	if d.DonorID != donorID {
+		return domain.ErrForbidden
+	}
+	if d.Status != domain.DonationPledged {
+		return domain.ErrDonationStatus
+	}
+
+	d.Status = domain.DonationCancelled
+	d.CancelReason = "withdrawn by donor"
+	if err := s.donationRepo.UpdateIntake(ctx, d, domain.DonationPledged); err != nil {
+		return err
+	}
+	s.log.Info("book pledge withdrawn", zap.String("donation_id", donationID))
+	return nil
+}
+
+func (s *service) MarkReceived(ctx context.Context, donationID, adminID string) (*domain.Donation, error) {
+	d, err := s.bookDonation(ctx, donationID)
+	if err != nil {
+		return nil, err
+	}
+	if d.Status != domain.DonationPledged {
+		return nil, domain.ErrDonationStatus
+	}
+
+	now := time.Now()
+	d.Status = domain.DonationReceived
+	d.ReceivedBy = &adminID
+	d.ReceivedAt = &now
+	if err := s.donationRepo.UpdateIntake(ctx, d, domain.DonationPledged); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("donated book received", zap.String("donation_id", donationID), zap.String("admin_id", adminID))
+	return d, nil
+}
+
+func (s *service) Catalogue(ctx context.Context, donationID, adminID string, entry CatalogueEntry) (*domain.Book, error) {
+	if entry.PhysicalCode == "" {
+		return nil, domain.ErrInvalidInput
+	}
+	d, err := s.bookDonation(ctx, donationID)
+	if err != nil {
+		return nil, err
+	}
+	if d.Status != domain.DonationReceived {
+		return nil, domain.ErrDonationStatus
+	}
+
+	book := catalogueBook(d, adminID, entry)
+	if book.Title == "" || book.Author == "" {
+		return nil, domain.ErrInvalidInput
+	}
+
+	// The book, the donation's link to it and the receipt are saved
+	// together; a taken physical code leaves the donation received
+	var receipt *domain.DonationReceipt
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		created, err := s.bookSvc.Create(ctx, book)
+		if err != nil {
+			return err
+		}
+		book = created
+
+		now := time.Now()
+		d.Status = domain.DonationCatalogued
+		d.BookID = &book.ID
+		d.CataloguedAt = &now
+		if err := s.donationRepo.UpdateIntake(ctx, d, domain.DonationReceived); err != nil {
+			return err
+		}
+		receipt, err = s.issueReceipt(ctx, d.ID)
+		return err
+	})
+	if err != nil {
+		s.log.Error("failed to catalogue donation", zap.String("donation_id", donationID), zap.Error(err))
+		return nil, err
+	}
+
+	s.award(ctx, d)
+	s.publishReceipt(ctx, receipt)
+	s.notifyCampaignOwner(ctx, d)
+
+	s.log.Info("donated book catalogued",
+		zap.String("donation_id", donationID),
+		zap.String("book_id", book.ID),
+		zap.String("physical_code", book.PhysicalCode))
+	return book, nil
+}
+
+func (s *service) Decline(ctx context.Context, donationID, adminID, reason string) error {
+	d, err := s.bookDonation(ctx, donationID)
+	if err != nil {
+		return err
+	}
+	from := d.Status
+	if from != domain.DonationPledged && from != domain.DonationReceived {
+		return domain.ErrDonationStatus
+	}
+
+	d.Status = domain.DonationCancelled
+	d.CancelReason = reason
+	if err := s.donationRepo.UpdateIntake(ctx, d, from); err != nil {
+		return err
+	}
+	s.log.Info("book donation declined", zap.String("donation_id", donationID), zap.String("admin_id", adminID))
+	return nil
+}
+
+// bookDonation loads a book donation, reporting other types as not found
+func (s *service) bookDonation(ctx context.Context, donationID string) (*domain.Donation, error) {
+	d, err := s.donationRepo.GetByID(ctx, donationID)
+	if err != nil {
+		return nil, err
+	}
+	if d.DonationType != domain.DonationTypeBook {
+		return nil, domain.ErrNotFound
+	}
+	return d, nil
+}
+
+// catalogueBook builds the catalogue entry from the pledge and the admin's
+// overrides. The receiving admin holds the copy until its first handover.
+func catalogueBook(d *domain.Donation, adminID string, entry CatalogueEntry) *domain.Book {
+	pledge := domain.BookPledge{}
+	if d.Pledge != nil {
+		pledge = *d.Pledge
+	}
+
+	readingDays := entry.MaxReadingDays
+	if readingDays <= 0 {
+		readingDays = defaultReadingDays
+	}
+	donatedBy := d.DonorID
+
+	return &domain.Book{
+		Title:          firstNonEmpty(entry.Title, pledge.Title),
+		Author:         firstNonEmpty(entry.Author, pledge.Author),
+		ISBN:           firstNonEmpty(entry.ISBN, pledge.ISBN),
+		CoverURL:       entry.CoverURL,
+		Description:    firstNonEmpty(entry.Description, pledge.Description),
+		Category:       firstNonEmpty(entry.Category, pledge.Category),
+		Tags:           entry.Tags,
+		Topics:         entry.Topics,
+		PhysicalCode:   entry.PhysicalCode,
+		MaxReadingDays: readingDays,
+		CreatedBy:      &adminID,
+		DonatedBy:      &donatedBy,
+		IsDonated:      true,
+		DonationDate:   d.ReceivedAt,
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,161 @@
+package donation
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/online-library/internal/domain"
+	"go.uber.org/zap"
+)
+
+// Ledger builds the year's account month by month, up to the current month
+// for the running year
+func (s *service) Ledger(ctx context.Context, year int) (*domain.Ledger, error) {
+	now := time.Now()
+	if year < earliestStatementYear || year > now.Year() {
+		return nil, domain.ErrInvalidInput
+	}
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(1, 0, 0)
+
+	inflows, err := s.ledgerRepo.MonthlyInflows(ctx, from, to)
+	if err != nil {
+		s.log.Error("failed to total ledger inflows", zap.Int("year", year), zap.Error(err))
+		return nil, err
+	}
+	outflows, err := s.ledgerRepo.MonthlyOutflows(ctx, from, to)
+	if err != nil {
+		s.log.Error("failed to total ledger outflows", zap.Int("year", year), zap.Error(err))
+		return nil, err
+	}
+	books, err := s.ledgerRepo.MonthlyBooks(ctx, from, to)
+	if err != nil {
+		s.log.Error("failed to count catalogued books", zap.Int("year", year), zap.Error(err))
+		return nil, err
+	}
+	entries, err := s.ledgerRepo.ListOutflows(ctx, from, to)
+	if err != nil {
+		s.log.Error("failed to list ledger outflows", zap.Int("year", year), zap.Error(err))
+		return nil, err
+	}
+	if entries == nil {
+		entries = []*domain.LedgerOutflow{}
+	}
+
+	ledger := &domain.Ledger{
+		Year:     year,
+		Inflows:  map[string]float64{},
+		Outflows: map[string]float64{},
+		Balance:  map[string]float64{},
+		Entries:  entries,
+	}
+	months := map[string]*domain.LedgerMonth{}
+	for m := from; m.Before(to); m = m.AddDate(0, 1, 0) {
+		if year == now.Year() && m.Month() > now.Month() {
+			break
+		}
+		month := &domain.LedgerMonth{
+			Month:    m.Format("2006-01"),
+			Inflows:  map[string]float64{},
+			Outflows: map[string]float64{},
+			Books:    books[m.Format("2006-01")],
+		}
+		months[month.Month] = month
+		ledger.Months = append(ledger.Months, month)
+		ledger.Books += month.Books
+	}
+
+	for _, t := range inflows {
+		if month, ok := months[t.Month]; ok {
+			month.Inflows[t.Currency] = roundMoney(month.Inflows[t.Currency] + t.Amount)
+			month.Donations += t.Count
+		}
+		ledger.Inflows[t.Currency] = roundMoney(ledger.Inflows[t.Currency] + t.Amount)
+	}
+	for _, t := range outflows {
+		if month, ok := months[t.Month]; ok {
+			month.Outflows[t.Currency] = roundMoney(month.Outflows[t.Currency] + t.Amount)
+		}
+		ledger.Outflows[t.Currency] = roundMoney(ledger.Outflows[t.Currency] + t.Amount)
+	}
+	for currency, amount := range ledger.Inflows {
+		ledger.Balance[currency] = amount
+	}
+	for currency, amount := range ledger.Outflows {
+		ledger.Balance[currency] = roundMoney(ledger.Balance[currency] - amount)
+	}
+	return ledger, nil
+}
+
+func (s *service) RecordOutflow(ctx context.Context, o *domain.LedgerOutflow) (*domain.LedgerOutflow, error) {
+	o.Currency = strings.ToUpper(o.Currency)
+	if o.Currency == "" {
+		o.Currency = defaultCurrency
+	}
+	if o.Amount <= 0 || len(o.Currency) != 3 || strings.TrimSpace(o.Description) == "" || !validOutflowCategory(o.Category) {
+		return nil, domain.ErrInvalidInput
+	}
+	if o.SpentOn.IsZero() || o.SpentOn.After(time.Now()) || o.SpentOn.Year() < earliestStatementYear {
+		return nil, domain.ErrInvalidInput
+	}
+	if o.CampaignID != nil {
+		if _, err := s.campaignRepo.GetByID(ctx, *o.CampaignID); err != nil {
+			return nil, err
+		}
+	}
+
+	o.ID = uuid.New().String()
+	o.Amount = roundMoney(o.Amount)
+	o.CreatedAt = time.Now()
+	o.VoidedAt = nil
+	o.VoidedBy = nil
+	o.VoidReason = ""
+	if err := s.ledgerRepo.CreateOutflow(ctx, o); err != nil {
+		s.log.Error("failed to record outflow", zap.Error(err))
+		return nil, err
+	}
+
+	s.log.Info("ledger outflow recorded",
+		zap.String("outflow_id", o.ID),
+		zap.String("recorded_by", o.RecordedBy),
+		zap.Float64("amount", o.Amount),
+		zap.String("currency", o.Currency))
+	return o, nil
+}
+
+func (s *service) VoidOutflow(ctx context.Context, outflowID, adminID, reason string) (*domain.LedgerOutflow, error) {
+	o, err := s.ledgerRepo.GetOutflow(ctx, outflowID)
+	if err != nil {
+		return nil, err
+	}
+	if o.VoidedAt != nil {
+		return nil, domain.ErrOutflowVoided
+	}
+
+	now := time.Now()
+	o.VoidedAt = &now
+	o.VoidedBy = &adminID
+	o.VoidReason = reason
+	if err := s.ledgerRepo.VoidOutflow(ctx, o); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("ledger outflow voided", zap.String("outflow_id", outflowID), zap.String("admin_id", adminID))
+	return o, nil
+}
+
+func validOutflowCategory(c domain.OutflowCategory) bool {
+	switch c {
+	case domain.OutflowBooks, domain.OutflowShipping, domain.OutflowOperations, domain.OutflowEvents, domain.OutflowOther:
+		return true
+	}
+	return false
+}
+
+// roundMoney keeps sums of DECIMAL(12, 2) amounts at two places
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
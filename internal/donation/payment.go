@@ -0,0 +1,188 @@
+package donation
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/yourusername/online-library/internal/domain"
+	"go.uber.org/zap"
+)
+
+// defaultCurrency is used when a money donation doesn't name one
+const defaultCurrency = "BDT"
+
+// startCheckout opens a payment session for a new money donation. The
+// session is created before the row is saved so a fast webhook always
+// finds it.
+func (s *service) startCheckout(ctx context.Context, d *domain.Donation) error {
+	if d.Amount == nil || *d.Amount <= 0 {
+		return domain.ErrInvalidInput
+	}
+	d.Currency = strings.ToUpper(d.Currency)
+	if d.Currency == "" {
+		d.Currency = defaultCurrency
+	}
+
+	session, err := s.payments.CreateCheckout(ctx, CheckoutRequest{
+		DonationID:  d.ID,
+		Amount:      *d.Amount,
+		Currency:    d.Currency,
+		Description: "Donation to Amar Pathagar",
+	})
+	if err != nil {
+		s.log.Error("failed to create checkout session", zap.String("donation_id", d.ID), zap.Error(err))
+		return err
+	}
+
+	d.Status = domain.DonationPending
+	d.PaymentProvider = s.payments.Name()
+	d.PaymentSessionID = session.ID
+	d.CheckoutURL = session.URL
+	return nil
+}
+
+func (s *service) PaymentSignatureHeader() string {
+	return s.payments.SignatureHeader()
+}
+
+func (s *service) HandleWebhook(ctx context.Context, provider string, payload []byte, signature string) error {
+	if provider != s.payments.Name() {
+		return domain.ErrNotFound
+	}
+	event, err := s.payments.ParseWebhook(payload, signature)
+	if err != nil {
+		s.log.Warn("rejected payment webhook", zap.String("provider", provider), zap.Error(err))
+		return err
+	}
+
+	d, err := s.donationRepo.GetByPaymentSession(ctx, provider, event.SessionID)
+	if err != nil {
+		s.log.Warn("payment webhook for unknown session", zap.String("session_id", event.SessionID), zap.Error(err))
+		return err
+	}
+	if d.Amount == nil || !sameAmount(*d.Amount, event.Amount) || !strings.EqualFold(d.Currency, event.Currency) {
+		s.log.Error("payment webhook amount mismatch",
+			zap.String("donation_id", d.ID),
+			zap.Float64("paid", event.Amount),
+			zap.String("currency", event.Currency))
+		return domain.ErrInvalidInput
+	}
+
+	switch event.Type {
+	case PaymentSucceeded:
+		if d.Status == domain.DonationConfirmed || d.Status == domain.DonationRefunded {
+			return nil
+		}
+		now := time.Now()
+		d.Status = domain.DonationConfirmed
+		d.PaymentRef = event.PaymentRef
+		d.ConfirmedAt = &now
+		var receipt *domain.DonationReceipt
+		err = s.uow.Do(ctx, func(ctx context.Context) error {
+			if err := s.donationRepo.UpdatePayment(ctx, d, domain.DonationPending); err != nil {
+				return err
+			}
+			issued, err := s.issueReceipt(ctx, d.ID)
+			receipt = issued
+			return err
+		})
+		if err != nil {
+			s.log.Error("failed to confirm donation", zap.String("donation_id", d.ID), zap.Error(err))
+			return err
+		}
+		s.award(ctx, d)
+		s.publishReceipt(ctx, receipt)
+		s.notifyCampaignOwner(ctx, d)
+		s.log.Info("donation payment confirmed", zap.String("donation_id", d.ID))
+
+	case PaymentFailed:
+		if d.Status == domain.DonationFailed {
+			return nil
+		}
+		d.Status = domain.DonationFailed
+		d.CancelReason = event.Reason
+		if err := s.donationRepo.UpdatePayment(ctx, d, domain.DonationPending); err != nil {
+			return err
+		}
+		s.log.Info("donation payment failed", zap.String("donation_id", d.ID), zap.String("reason", event.Reason))
+
+	default:
+		return fmt.Errorf("unsupported payment event %q", event.Type)
+	}
+	return nil
+}
+
+// Refund returns a confirmed money donation and takes back its points.
+// Donations recorded before payments went through a provider have nothing
+// to refund and are only voided.
+func (s *service) Refund(ctx context.Context, donationID, adminID, reason string) (*domain.Donation, error) {
+	d, err := s.donationRepo.GetByID(ctx, donationID)
+	if err != nil {
+		return nil, err
+	}
+	if d.DonationType != domain.DonationTypeMoney {
+		return nil, domain.ErrNotFound
+	}
+	if d.Status != domain.DonationConfirmed {
+		return nil, domain.ErrDonationStatus
+	}
+
+	now := time.Now()
+	d.Status = domain.DonationRefunded
+	d.RefundedAt = &now
+	d.CancelReason = reason
+	var receipt *domain.DonationReceipt
+	refunded := false
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		// Claim the donation before asking the provider, so a concurrent
+		// refund waits here and then finds it no longer confirmed. A failed
+		// provider call rolls the claim back.
+		if err := s.donationRepo.UpdatePayment(ctx, d, domain.DonationConfirmed); err != nil {
+			return err
+		}
+		if d.PaymentRef != "" {
+			refund, err := s.payments.Refund(ctx, d.PaymentRef, *d.Amount, d.Currency)
+			if err != nil {
+				s.log.Error("payment refund failed", zap.String("donation_id", d.ID), zap.Error(err))
+				return err
+			}
+			refunded = true
+			d.RefundID = refund.ID
+			if err := s.donationRepo.UpdatePayment(ctx, d, domain.DonationRefunded); err != nil {
+				return err
+			}
+		}
+		voided, err := s.voidReceipt(ctx, d.ID, reason)
+		receipt = voided
+		return err
+	})
+	if err != nil && refunded {
+		// The provider has already returned the money
+		s.log.Error("refund issued but donation not updated",
+			zap.String("donation_id", d.ID),
+			zap.String("refund_id", d.RefundID),
+			zap.Error(err))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.successScoreSvc.RevertReference(ctx, d.DonorID, "donation", d.ID, "Donation refunded"); err != nil {
+		s.log.Error("failed to revert donation points", zap.String("donation_id", d.ID), zap.Error(err))
+	}
+	s.publishReceipt(ctx, receipt)
+
+	s.log.Info("donation refunded",
+		zap.String("donation_id", d.ID),
+		zap.String("admin_id", adminID),
+		zap.String("refund_id", d.RefundID))
+	return d, nil
+}
+
+// sameAmount compares to the smallest currency unit
+func sameAmount(a, b float64) bool {
+	return math.Round(a*100) == math.Round(b*100)
+}
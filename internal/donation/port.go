@@ -2,21 +2,254 @@ package donation
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/yourusername/online-library/internal/domain"
 )
 
 type Service interface {
+	// Create records a book pledge, or a pending money donation with a
+	// checkout session to pay it. Points are awarded only once the book is
+	// catalogued or the payment confirmed.
 	Create(ctx context.Context, donation *domain.Donation) (*domain.Donation, error)
 	List(ctx context.Context, limit, offset int) ([]*domain.Donation, error)
+	ListByDonor(ctx context.Context, donorID string) ([]*domain.Donation, error)
+	CancelPledge(ctx context.Context, donationID, donorID string) error
+
+	// Book intake (admin)
+	ListByStatus(ctx context.Context, status domain.DonationStatus, limit, offset int) ([]*domain.Donation, error)
+	MarkReceived(ctx context.Context, donationID, adminID string) (*domain.Donation, error)
+	// Catalogue creates the book for a received donation and awards the
+	// donor
+	Catalogue(ctx context.Context, donationID, adminID string, entry CatalogueEntry) (*domain.Book, error)
+	Decline(ctx context.Context, donationID, adminID, reason string) error
+
+	// Payments
+	// HandleWebhook verifies and applies a payment provider callback.
+	// Replays of an already applied event are ignored.
+	HandleWebhook(ctx context.Context, provider string, payload []byte, signature string) error
+	// PaymentSignatureHeader names the header carrying the webhook signature
+	PaymentSignatureHeader() string
+	Refund(ctx context.Context, donationID, adminID, reason string) (*domain.Donation, error)
+
+	// Campaigns
+	// CreateCampaign saves a campaign and notifies users interested in its
+	// category
+	CreateCampaign(ctx context.Context, campaign *domain.Campaign) (*domain.Campaign, error)
+	UpdateCampaign(ctx context.Context, campaign *domain.Campaign) (*domain.Campaign, error)
+	// GetCampaign and ListCampaigns include progress. ListCampaigns returns
+	// running and upcoming campaigns, and ended ones too if includeEnded.
+	GetCampaign(ctx context.Context, id string) (*domain.Campaign, error)
+	ListCampaigns(ctx context.Context, includeEnded bool) ([]*domain.Campaign, error)
+
+	// Receipts and statements
+	ListReceipts(ctx context.Context, donorID string) ([]*domain.DonationReceipt, error)
+	// ReceiptPDF returns a donation's receipt to its donor or an admin
+	ReceiptPDF(ctx context.Context, donationID, viewerID string, isAdmin bool) (*domain.DonationReceipt, []byte, error)
+	// RegenerateReceipt refreshes a receipt's details and PDF, keeping its
+	// number, or issues it if a fulfilled donation has none
+	RegenerateReceipt(ctx context.Context, donationID, adminID string) (*domain.DonationReceipt, error)
+	// StatementPDF returns a donor's statement for a calendar year. Past
+	// years are generated once and then served as stored.
+	StatementPDF(ctx context.Context, donorID string, year int) ([]byte, error)
+	RegenerateStatement(ctx context.Context, donorID string, year int, adminID string) (*domain.DonorStatement, error)
+
+	// Donor wall and ledger
+	// DonorWall ranks donors over the period by money given in currency,
+	// then books, and lists recent donations. Only public donations count.
+	DonorWall(ctx context.Context, period domain.WallPeriod, currency string) (*domain.DonorWall, error)
+	Ledger(ctx context.Context, year int) (*domain.Ledger, error)
+	RecordOutflow(ctx context.Context, outflow *domain.LedgerOutflow) (*domain.LedgerOutflow, error)
+	// VoidOutflow takes a mistaken entry out of the totals; it stays listed
+	// with the reason
+	VoidOutflow(ctx context.Context, outflowID, adminID, reason string) (*domain.LedgerOutflow, error)
+}
+
+// CatalogueEntry is what the admin adds when shelving a donated book. Empty
+// text fields fall back to the donor's pledge.
+type CatalogueEntry struct {
+	PhysicalCode   string
+	MaxReadingDays int
+	Title          string
+	Author         string
+	ISBN           string
+	CoverURL       string
+	Category       string
+	Description    string
+	Tags           []string
+	Topics         []string
 }
 
 type DonationRepo interface {
 	Create(ctx context.Context, donation *domain.Donation) error
+	GetByID(ctx context.Context, id string) (*domain.Donation, error)
 	List(ctx context.Context, limit, offset int) ([]*domain.Donation, error)
+	ListByDonor(ctx context.Context, donorID string) ([]*domain.Donation, error)
+	ListByStatus(ctx context.Context, status domain.DonationStatus, limit, offset int) ([]*domain.Donation, error)
+	// UpdateIntake saves the donation's status and intake fields if it is
+	// still in status from, and returns domain.ErrDonationStatus otherwise
+	UpdateIntake(ctx context.Context, donation *domain.Donation, from domain.DonationStatus) error
+	MarkScored(ctx context.Context, donationID string) error
+	GetByPaymentSession(ctx context.Context, provider, sessionID string) (*domain.Donation, error)
+	// UpdatePayment saves the donation's status and payment fields if it is
+	// still in status from, and returns domain.ErrDonationStatus otherwise
+	UpdatePayment(ctx context.Context, donation *domain.Donation, from domain.DonationStatus) error
+	// ListWall returns public confirmed and catalogued donations fulfilled
+	// since the given time (any time if nil), newest first
+	ListWall(ctx context.Context, since *time.Time, limit int) ([]*domain.WallDonation, error)
+	// TopDonors ranks donors of public donations fulfilled since the given
+	// time by amount given in currency, then by books catalogued
+	TopDonors(ctx context.Context, since *time.Time, currency string, limit int) ([]*domain.TopDonor, error)
+}
+
+type CampaignRepo interface {
+	Create(ctx context.Context, campaign *domain.Campaign) error
+	Update(ctx context.Context, campaign *domain.Campaign) error
+	GetByID(ctx context.Context, id string) (*domain.Campaign, error)
+	// List returns campaigns ending after endsAfter, soonest first
+	List(ctx context.Context, endsAfter time.Time) ([]*domain.Campaign, error)
+	// Progress totals fulfilled donations per campaign ID. Campaigns
+	// without any are left out.
+	Progress(ctx context.Context, campaignIDs []string) (map[string]*domain.CampaignProgress, error)
+	// ListInterestedUsers returns users with category among their interests
+	// or who bookmarked a book in it
+	ListInterestedUsers(ctx context.Context, category string) ([]string, error)
+}
+
+type ReceiptRepo interface {
+	// ReceiptSource fills a receipt from the donation, its donor and book,
+	// leaving the number and dates of issue empty
+	ReceiptSource(ctx context.Context, donationID string) (*domain.DonationReceipt, error)
+	// NextReceiptNumber increments the receipt counter and returns the new
+	// value. The counter row stays locked until the transaction ends, so
+	// concurrent issuers wait their turn and a rolled back transaction
+	// gives its number back: numbers have no gaps.
+	NextReceiptNumber(ctx context.Context) (int64, error)
+	// CreateReceipt returns domain.ErrAlreadyExists if the donation already
+	// has a receipt
+	CreateReceipt(ctx context.Context, receipt *domain.DonationReceipt) error
+	// UpdateReceipt saves the copied details and void state
+	UpdateReceipt(ctx context.Context, receipt *domain.DonationReceipt) error
+	GetReceiptByDonation(ctx context.Context, donationID string) (*domain.DonationReceipt, error)
+	// ListReceipts returns a donor's receipts oldest first, limited to
+	// donations made in year unless it is 0
+	ListReceipts(ctx context.Context, donorID string, year int) ([]*domain.DonationReceipt, error)
+	// GetReceiptPDF returns nil if the receipt hasn't been rendered
+	GetReceiptPDF(ctx context.Context, receiptID string) ([]byte, error)
+	SaveReceiptPDF(ctx context.Context, receiptID string, pdf []byte) error
+
+	GetDonorName(ctx context.Context, donorID string) (string, error)
+	// GetStatementPDF returns nil if no statement is stored
+	GetStatementPDF(ctx context.Context, donorID string, year int) ([]byte, error)
+	SaveStatement(ctx context.Context, statement *domain.DonorStatement, pdf []byte) error
+	DeleteStatement(ctx context.Context, donorID string, year int) error
+}
+
+// LedgerRepo totals what came in and went out over [from, to). Months are
+// keyed "2006-01".
+type LedgerRepo interface {
+	// MonthlyInflows totals confirmed money donations, public or not, by
+	// month and currency. Refunded donations are left out.
+	MonthlyInflows(ctx context.Context, from, to time.Time) ([]*LedgerTotal, error)
+	// MonthlyBooks counts donated books catalogued per month
+	MonthlyBooks(ctx context.Context, from, to time.Time) (map[string]int, error)
+	// MonthlyOutflows totals outflows that haven't been voided
+	MonthlyOutflows(ctx context.Context, from, to time.Time) ([]*LedgerTotal, error)
+	// ListOutflows returns every outflow, voided ones included, by date spent
+	ListOutflows(ctx context.Context, from, to time.Time) ([]*domain.LedgerOutflow, error)
+	CreateOutflow(ctx context.Context, outflow *domain.LedgerOutflow) error
+	GetOutflow(ctx context.Context, id string) (*domain.LedgerOutflow, error)
+	// VoidOutflow saves the void fields and returns domain.ErrOutflowVoided
+	// if the outflow was already voided
+	VoidOutflow(ctx context.Context, outflow *domain.LedgerOutflow) error
+}
+
+type LedgerTotal struct {
+	Month    string
+	Currency string
+	Amount   float64
+	Count    int
+}
+
+// DocumentRenderer lays out receipts and statements as PDF. The adapter
+// lives in internal/infrastructure/pdf.
+type DocumentRenderer interface {
+	Receipt(receipt *domain.DonationReceipt) ([]byte, error)
+	Statement(statement *domain.DonorStatement) ([]byte, error)
+}
+
+type NotificationSvc interface {
+	NotifyCampaignLaunched(ctx context.Context, userID, campaignID, campaignTitle string) error
+	NotifyDonationReceived(ctx context.Context, userID, campaignID, campaignTitle string) error
 }
 
 type SuccessScoreSvc interface {
 	ProcessBookDonation(ctx context.Context, userID, donationID string) error
 	ProcessMoneyDonation(ctx context.Context, userID, donationID string) error
+	RevertReference(ctx context.Context, userID, refType, refID, reason string) (int, error)
+}
+
+// PaymentProvider is a payment gateway. Adapters live in
+// internal/infrastructure/payment.
+type PaymentProvider interface {
+	Name() string
+	// CreateCheckout starts a hosted payment page for a donation
+	CreateCheckout(ctx context.Context, req CheckoutRequest) (*CheckoutSession, error)
+	// SignatureHeader names the request header carrying the webhook
+	// signature
+	SignatureHeader() string
+	// ParseWebhook checks the signature on a callback and decodes it. It
+	// returns ErrInvalidSignature if the payload wasn't sent by the provider.
+	ParseWebhook(payload []byte, signature string) (*PaymentEvent, error)
+	Refund(ctx context.Context, paymentRef string, amount float64, currency string) (*PaymentRefund, error)
+}
+
+type CheckoutRequest struct {
+	DonationID  string
+	Amount      float64
+	Currency    string
+	Description string
+}
+
+type CheckoutSession struct {
+	ID  string
+	URL string
+}
+
+type PaymentEventType string
+
+const (
+	PaymentSucceeded PaymentEventType = "payment.succeeded"
+	PaymentFailed    PaymentEventType = "payment.failed"
+)
+
+// PaymentEvent is a verified webhook from the provider
+type PaymentEvent struct {
+	Type      PaymentEventType
+	SessionID string
+	// PaymentRef identifies the captured payment for refunds
+	PaymentRef string
+	Amount     float64
+	Currency   string
+	Reason     string
+}
+
+type PaymentRefund struct {
+	ID string
+}
+
+// ErrInvalidSignature is returned by PaymentProvider.ParseWebhook for a
+// callback that fails verification
+var ErrInvalidSignature = errors.New("invalid payment webhook signature")
+
+// BookCreator adds a book to the catalogue
+type BookCreator interface {
+	Create(ctx context.Context, book *domain.Book) (*domain.Book, error)
+}
+
+// UnitOfWork runs fn in one database transaction. Repository calls made
+// with the context passed to fn take part in it.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
 }
@@ -0,0 +1,261 @@
+package donation
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/yourusername/online-library/internal/domain"
+	"go.uber.org/zap"
+)
+
+// earliestStatementYear bounds the statement years that can be requested
+const earliestStatementYear = 2000
+
+func (s *service) ListReceipts(ctx context.Context, donorID string) ([]*domain.DonationReceipt, error) {
+	receipts, err := s.receiptRepo.ListReceipts(ctx, donorID, 0)
+	if err != nil {
+		s.log.Error("failed to list receipts", zap.String("donor_id", donorID), zap.Error(err))
+		return nil, err
+	}
+	return receipts, nil
+}
+
+func (s *service) ReceiptPDF(ctx context.Context, donationID, viewerID string, isAdmin bool) (*domain.DonationReceipt, []byte, error) {
+	receipt, err := s.receiptRepo.GetReceiptByDonation(ctx, donationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if receipt.DonorID != viewerID && !isAdmin {
+		return nil, nil, domain.ErrForbidden
+	}
+
+	pdf, err := s.receiptRepo.GetReceiptPDF(ctx, receipt.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if pdf == nil {
+		// Receipts backfilled by migration are rendered on first download
+		if pdf, err = s.renderReceipt(ctx, receipt); err != nil {
+			return nil, nil, err
+		}
+	}
+	return receipt, pdf, nil
+}
+
+func (s *service) RegenerateReceipt(ctx context.Context, donationID, adminID string) (*domain.DonationReceipt, error) {
+	receipt, err := s.receiptRepo.GetReceiptByDonation(ctx, donationID)
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		receipt, err = s.issueMissingReceipt(ctx, donationID)
+		if err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	default:
+		source, err := s.receiptRepo.ReceiptSource(ctx, donationID)
+		if err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		receipt.DonorName = source.DonorName
+		receipt.Amount = source.Amount
+		receipt.Currency = source.Currency
+		receipt.BookTitle = source.BookTitle
+		receipt.DonatedAt = source.DonatedAt
+		receipt.RegeneratedAt = &now
+		if err := s.receiptRepo.UpdateReceipt(ctx, receipt); err != nil {
+			s.log.Error("failed to update receipt", zap.String("receipt_id", receipt.ID), zap.Error(err))
+			return nil, err
+		}
+	}
+
+	if _, err := s.renderReceipt(ctx, receipt); err != nil {
+		return nil, err
+	}
+	s.forgetStatement(ctx, receipt)
+
+	s.log.Info("receipt regenerated",
+		zap.String("receipt", receipt.Code),
+		zap.String("donation_id", donationID),
+		zap.String("admin_id", adminID))
+	return receipt, nil
+}
+
+func (s *service) StatementPDF(ctx context.Context, donorID string, year int) ([]byte, error) {
+	current := time.Now().Year()
+	if year < earliestStatementYear || year > current {
+		return nil, domain.ErrInvalidInput
+	}
+	// The current year's statement changes with every donation
+	if year < current {
+		pdf, err := s.receiptRepo.GetStatementPDF(ctx, donorID, year)
+		if err != nil {
+			return nil, err
+		}
+		if pdf != nil {
+			return pdf, nil
+		}
+	}
+	_, pdf, err := s.generateStatement(ctx, donorID, year)
+	return pdf, err
+}
+
+func (s *service) RegenerateStatement(ctx context.Context, donorID string, year int, adminID string) (*domain.DonorStatement, error) {
+	if year < earliestStatementYear || year > time.Now().Year() {
+		return nil, domain.ErrInvalidInput
+	}
+	statement, _, err := s.generateStatement(ctx, donorID, year)
+	if err != nil {
+		return nil, err
+	}
+	s.log.Info("statement regenerated",
+		zap.String("donor_id", donorID),
+		zap.Int("year", year),
+		zap.String("admin_id", adminID))
+	return statement, nil
+}
+
+// issueReceipt numbers and saves the receipt of a fulfilled donation. Call
+// it in the transaction that confirms or catalogues the donation, so the
+// receipt exists exactly when the donation is fulfilled and a rollback
+// returns its number.
+func (s *service) issueReceipt(ctx context.Context, donationID string) (*domain.DonationReceipt, error) {
+	receipt, err := s.receiptRepo.ReceiptSource(ctx, donationID)
+	if err != nil {
+		return nil, err
+	}
+	number, err := s.receiptRepo.NextReceiptNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+	receipt.Number = number
+	receipt.Code = domain.ReceiptCode(number)
+	receipt.IssuedAt = time.Now()
+	if err := s.receiptRepo.CreateReceipt(ctx, receipt); err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// issueMissingReceipt issues a receipt for a fulfilled donation that has
+// none, such as one fulfilled while receipts couldn't be issued
+func (s *service) issueMissingReceipt(ctx context.Context, donationID string) (*domain.DonationReceipt, error) {
+	d, err := s.donationRepo.GetByID(ctx, donationID)
+	if err != nil {
+		return nil, err
+	}
+	if d.Status != domain.DonationConfirmed && d.Status != domain.DonationCatalogued {
+		return nil, domain.ErrDonationStatus
+	}
+
+	var receipt *domain.DonationReceipt
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		receipt, err = s.issueReceipt(ctx, donationID)
+		return err
+	})
+	if errors.Is(err, domain.ErrAlreadyExists) {
+		return s.receiptRepo.GetReceiptByDonation(ctx, donationID)
+	}
+	if err != nil {
+		s.log.Error("failed to issue receipt", zap.String("donation_id", donationID), zap.Error(err))
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// voidReceipt marks a refunded donation's receipt void. It returns nil if
+// the donation has no receipt.
+func (s *service) voidReceipt(ctx context.Context, donationID, reason string) (*domain.DonationReceipt, error) {
+	receipt, err := s.receiptRepo.GetReceiptByDonation(ctx, donationID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	receipt.VoidedAt = &now
+	receipt.VoidReason = reason
+	if err := s.receiptRepo.UpdateReceipt(ctx, receipt); err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// publishReceipt renders a receipt after the transaction that issued or
+// voided it has committed. Failures are logged; the PDF is rendered again
+// on download.
+func (s *service) publishReceipt(ctx context.Context, receipt *domain.DonationReceipt) {
+	if receipt == nil {
+		return
+	}
+	if _, err := s.renderReceipt(ctx, receipt); err != nil {
+		return
+	}
+	s.forgetStatement(ctx, receipt)
+}
+
+func (s *service) renderReceipt(ctx context.Context, receipt *domain.DonationReceipt) ([]byte, error) {
+	pdf, err := s.documents.Receipt(receipt)
+	if err != nil {
+		s.log.Error("failed to render receipt", zap.String("receipt", receipt.Code), zap.Error(err))
+		return nil, err
+	}
+	if err := s.receiptRepo.SaveReceiptPDF(ctx, receipt.ID, pdf); err != nil {
+		s.log.Error("failed to save receipt", zap.String("receipt", receipt.Code), zap.Error(err))
+		return nil, err
+	}
+	return pdf, nil
+}
+
+// forgetStatement drops the stored statement covering receipt so the next
+// download includes the change
+func (s *service) forgetStatement(ctx context.Context, receipt *domain.DonationReceipt) {
+	if err := s.receiptRepo.DeleteStatement(ctx, receipt.DonorID, receipt.DonatedAt.Year()); err != nil {
+		s.log.Warn("failed to drop stale statement", zap.String("donor_id", receipt.DonorID), zap.Error(err))
+	}
+}
+
+func (s *service) generateStatement(ctx context.Context, donorID string, year int) (*domain.DonorStatement, []byte, error) {
+	name, err := s.receiptRepo.GetDonorName(ctx, donorID)
+	if err != nil {
+		return nil, nil, err
+	}
+	receipts, err := s.receiptRepo.ListReceipts(ctx, donorID, year)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	statement := &domain.DonorStatement{
+		DonorID:     donorID,
+		DonorName:   name,
+		Year:        year,
+		Receipts:    []*domain.DonationReceipt{},
+		Totals:      map[string]float64{},
+		GeneratedAt: time.Now(),
+	}
+	for _, r := range receipts {
+		if r.VoidedAt != nil {
+			continue
+		}
+		statement.Receipts = append(statement.Receipts, r)
+		if r.DonationType == domain.DonationTypeMoney && r.Amount != nil {
+			statement.Totals[r.Currency] += *r.Amount
+		} else if r.DonationType == domain.DonationTypeBook {
+			statement.Books++
+		}
+	}
+
+	pdf, err := s.documents.Statement(statement)
+	if err != nil {
+		s.log.Error("failed to render statement", zap.String("donor_id", donorID), zap.Int("year", year), zap.Error(err))
+		return nil, nil, err
+	}
+	if err := s.receiptRepo.SaveStatement(ctx, statement, pdf); err != nil {
+		s.log.Error("failed to save statement", zap.String("donor_id", donorID), zap.Int("year", year), zap.Error(err))
+		return nil, nil, err
+	}
+	return statement, pdf, nil
+}
@@ -11,14 +11,30 @@ import (
 
 type service struct {
 	donationRepo    DonationRepo
+	campaignRepo    CampaignRepo
+	receiptRepo     ReceiptRepo
+	ledgerRepo      LedgerRepo
 	successScoreSvc SuccessScoreSvc
+	notificationSvc NotificationSvc
+	bookSvc         BookCreator
+	payments        PaymentProvider
+	documents       DocumentRenderer
+	uow             UnitOfWork
 	log             *zap.Logger
 }
 
-func NewService(donationRepo DonationRepo, successScoreSvc SuccessScoreSvc, log *zap.Logger) Service {
+func NewService(donationRepo DonationRepo, campaignRepo CampaignRepo, receiptRepo ReceiptRepo, ledgerRepo LedgerRepo, successScoreSvc SuccessScoreSvc, notificationSvc NotificationSvc, bookSvc BookCreator, payments PaymentProvider, documents DocumentRenderer, uow UnitOfWork, log *zap.Logger) Service {
 	return &service{
 		donationRepo:    donationRepo,
+		campaignRepo:    campaignRepo,
+		receiptRepo:     receiptRepo,
+		ledgerRepo:      ledgerRepo,
 		successScoreSvc: successScoreSvc,
+		notificationSvc: notificationSvc,
+		bookSvc:         bookSvc,
+		payments:        payments,
+		documents:       documents,
+		uow:             uow,
 		log:             log,
 	}
 }
@@ -27,23 +43,37 @@ func (s *service) Create(ctx context.Context, donation *domain.Donation) (*domai
 	donation.ID = uuid.New().String()
 	donation.CreatedAt = time.Now()
 
-	if err := s.donationRepo.Create(ctx, donation); err != nil {
-		s.log.Error("failed to create donation", zap.Error(err))
-		return nil, err
+	if donation.CampaignID != nil {
+		if err := s.joinCampaign(ctx, donation); err != nil {
+			return nil, err
+		}
 	}
 
-	// Update success score based on donation type
-	if donation.DonationType == domain.DonationTypeBook {
-		if err := s.successScoreSvc.ProcessBookDonation(ctx, donation.DonorID, donation.ID); err != nil {
-			s.log.Warn("failed to update success score for book donation", zap.Error(err))
+	switch donation.DonationType {
+	case domain.DonationTypeBook:
+		if donation.Pledge == nil || donation.Pledge.Title == "" || donation.Pledge.Author == "" {
+			return nil, domain.ErrInvalidInput
 		}
-	} else if donation.DonationType == domain.DonationTypeMoney {
-		if err := s.successScoreSvc.ProcessMoneyDonation(ctx, donation.DonorID, donation.ID); err != nil {
-			s.log.Warn("failed to update success score for money donation", zap.Error(err))
+		// The book is created when it arrives and is catalogued
+		donation.Status = domain.DonationPledged
+		donation.BookID = nil
+	case domain.DonationTypeMoney:
+		donation.Pledge = nil
+		if err := s.startCheckout(ctx, donation); err != nil {
+			return nil, err
 		}
+	default:
+		return nil, domain.ErrInvalidInput
+	}
+
+	if err := s.donationRepo.Create(ctx, donation); err != nil {
+		s.log.Error("failed to create donation", zap.Error(err))
+		return nil, err
 	}
 
-	s.log.Info("donation created successfully", zap.String("donation_id", donation.ID))
+	s.log.Info("donation created successfully",
+		zap.String("donation_id", donation.ID),
+		zap.String("status", string(donation.Status)))
 	return donation, nil
 }
 
@@ -55,3 +85,34 @@ func (s *service) List(ctx context.Context, limit, offset int) ([]*domain.Donati
 	}
 	return donations, nil
 }
+
+func (s *service) ListByDonor(ctx context.Context, donorID string) ([]*domain.Donation, error) {
+	donations, err := s.donationRepo.ListByDonor(ctx, donorID)
+	if err != nil {
+		s.log.Error("failed to list donations", zap.String("donor_id", donorID), zap.Error(err))
+		return nil, err
+	}
+	return donations, nil
+}
+
+// award grants the donor's points once per donation
+func (s *service) award(ctx context.Context, d *domain.Donation) {
+	if d.ScoredAt != nil {
+		return
+	}
+
+	var err error
+	if d.DonationType == domain.DonationTypeBook {
+		err = s.successScoreSvc.ProcessBookDonation(ctx, d.DonorID, d.ID)
+	} else {
+		err = s.successScoreSvc.ProcessMoneyDonation(ctx, d.DonorID, d.ID)
+	}
+	if err != nil {
+		s.log.Warn("failed to update success score for donation", zap.String("donation_id", d.ID), zap.Error(err))
+		return
+	}
+
+	if err := s.donationRepo.MarkScored(ctx, d.ID); err != nil {
+		s.log.Error("failed to mark donation scored", zap.String("donation_id", d.ID), zap.Error(err))
+	}
+}
@@ -0,0 +1,68 @@
+package donation
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/yourusername/online-library/internal/domain"
+	"go.uber.org/zap"
+)
+
+const (
+	wallTopDonors = 10
+	wallRecent    = 50
+)
+
+func (s *service) DonorWall(ctx context.Context, period domain.WallPeriod, currency string) (*domain.DonorWall, error) {
+	since, err := wallSince(period, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	currency = strings.ToUpper(currency)
+	if currency == "" {
+		currency = defaultCurrency
+	}
+
+	top, err := s.donationRepo.TopDonors(ctx, since, currency, wallTopDonors)
+	if err != nil {
+		s.log.Error("failed to rank donors", zap.String("period", string(period)), zap.Error(err))
+		return nil, err
+	}
+	recent, err := s.donationRepo.ListWall(ctx, since, wallRecent)
+	if err != nil {
+		s.log.Error("failed to list wall donations", zap.String("period", string(period)), zap.Error(err))
+		return nil, err
+	}
+	if top == nil {
+		top = []*domain.TopDonor{}
+	}
+	if recent == nil {
+		recent = []*domain.WallDonation{}
+	}
+
+	return &domain.DonorWall{
+		Period:    period,
+		Since:     since,
+		Currency:  currency,
+		TopDonors: top,
+		Recent:    recent,
+	}, nil
+}
+
+// wallSince returns the start of the period containing now, or nil for all
+// time
+func wallSince(period domain.WallPeriod, now time.Time) (*time.Time, error) {
+	var since time.Time
+	switch period {
+	case domain.WallMonth:
+		since = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	case domain.WallYear:
+		since = time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, now.Location())
+	case domain.WallAllTime:
+		return nil, nil
+	default:
+		return nil, domain.ErrInvalidInput
+	}
+	return &since, nil
+}
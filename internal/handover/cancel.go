@@ -0,0 +1,255 @@
+package handover
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/online-library/internal/booklifecycle"
+	"github.com/yourusername/online-library/internal/domain"
+	"github.com/yourusername/online-library/internal/i18n"
+	"go.uber.org/zap"
+)
+
+// reofferWindow is how long the holder gets to hand the book to the next
+// reader when the cancelled handover's due date has already passed
+const reofferWindow = 7 * 24 * time.Hour
+
+func (s *service) CancelThread(ctx context.Context, threadID, userID string, reason domain.HandoverCancelReason, note string) (*domain.HandoverThread, error) {
+	return s.cancel(ctx, threadID, userID, reason, note, func(thread *domain.HandoverThread) (string, error) {
+		var other string
+		switch userID {
+		case thread.CurrentHolderID:
+			other = thread.NextHolderID
+		case thread.NextHolderID:
+			other = thread.CurrentHolderID
+		default:
+			return "", domain.ErrForbidden
+		}
+
+		switch reason {
+		case domain.CancelWithdrawn:
+			return userID, nil
+		case domain.CancelUnresponsive:
+			// Give the other participant until the due date to respond
+			if time.Now().Before(thread.HandoverDueDate) {
+				return "", domain.ErrHandoverNotOverdue
+			}
+			return other, nil
+		}
+		return "", nil
+	})
+}
+
+func (s *service) AdminCancelThread(ctx context.Context, threadID, adminID string, reason domain.HandoverCancelReason, note, atFaultID string) (*domain.HandoverThread, error) {
+	return s.cancel(ctx, threadID, adminID, reason, note, func(thread *domain.HandoverThread) (string, error) {
+		if atFaultID != "" && atFaultID != thread.CurrentHolderID && atFaultID != thread.NextHolderID {
+			return "", domain.ErrInvalidInput
+		}
+		return atFaultID, nil
+	})
+}
+
+// cancel closes the thread and frees the book for the next reader. atFault
+// checks the caller may cancel and names the participant held responsible.
+// The next holder's request is released unless the current holder was at
+// fault, in which case it keeps its place and the thread is recreated by
+// the daily handover check once the current holder is due again.
+func (s *service) cancel(ctx context.Context, threadID, actorID string, reason domain.HandoverCancelReason, note string, atFault func(*domain.HandoverThread) (string, error)) (*domain.HandoverThread, error) {
+	if !reason.Valid() {
+		return nil, domain.ErrInvalidInput
+	}
+	thread, err := s.handoverRepo.GetHandoverThreadByID(ctx, threadID)
+	if err != nil {
+		return nil, err
+	}
+	if thread == nil {
+		return nil, domain.ErrNotFound
+	}
+
+	var atFaultID string
+	var reoffered *domain.HandoverThread
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.handoverRepo.LockBook(ctx, thread.BookID); err != nil {
+			return err
+		}
+		// Re-read under the lock; a delivery may have completed it
+		thread, err = s.handoverRepo.GetHandoverThreadByID(ctx, threadID)
+		if err != nil {
+			return err
+		}
+		if thread.Status != string(domain.HandoverActive) {
+			return domain.ErrHandoverNotActive
+		}
+		if atFaultID, err = atFault(thread); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		thread.CancelledBy = &actorID
+		thread.CancelReason = reason
+		thread.CancelNote = note
+		thread.CancelledAt = &now
+		if atFaultID != "" {
+			thread.AtFaultID = &atFaultID
+		}
+		if err := s.handoverRepo.CancelHandoverThread(ctx, thread); err != nil {
+			return err
+		}
+		thread.Status = string(domain.HandoverCancelled)
+
+		systemMsg := SystemMessage(thread.ID, actorID, "handover.system.cancelled."+string(reason), map[string]string{
+			"note": note,
+		})
+		if err := s.handoverRepo.CreateHandoverMessage(ctx, systemMsg); err != nil {
+			return err
+		}
+
+		if thread.ReadingHistoryID != nil {
+			if err := s.handoverRepo.ClearReadingHistoryNextReader(ctx, *thread.ReadingHistoryID); err != nil {
+				return err
+			}
+		}
+
+		if atFaultID == thread.CurrentHolderID {
+			return nil
+		}
+		if err := s.handoverRepo.CancelApprovedRequest(ctx, thread.BookID, thread.NextHolderID); err != nil {
+			return err
+		}
+		reoffered, err = s.reoffer(ctx, thread, actorID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if atFaultID != "" {
+		if err := s.successScoreSvc.ProcessHandoverCancelled(ctx, atFaultID, thread.ID); err != nil {
+			s.log.Error("failed to apply handover cancellation penalty", zap.String("user_id", atFaultID), zap.Error(err))
+		}
+	}
+
+	for _, userID := range []string{thread.CurrentHolderID, thread.NextHolderID} {
+		if userID == actorID {
+			continue
+		}
+		if err := s.notificationSvc.NotifyHandoverCancelled(ctx, userID, thread.ID, thread.BookID, thread.Book.Title, reason); err != nil {
+			s.log.Error("failed to send notification", zap.Error(err))
+		}
+	}
+
+	if reoffered != nil {
+		if err := s.notificationSvc.NotifyHandoverThreadCreated(ctx, reoffered.ID, reoffered.CurrentHolderID, reoffered.NextHolderID, reoffered.BookID, thread.Book.Title); err != nil {
+			s.log.Error("failed to send notifications", zap.Error(err))
+		}
+	}
+
+	s.log.Info("handover thread cancelled",
+		zap.String("thread_id", thread.ID),
+		zap.String("book_id", thread.BookID),
+		zap.String("cancelled_by", actorID),
+		zap.String("reason", string(reason)),
+		zap.String("at_fault", atFaultID))
+	return thread, nil
+}
+
+// reoffer opens a fresh thread between the current holder and the next
+// approved request and returns it, or parks the book if the queue is empty
+func (s *service) reoffer(ctx context.Context, cancelled *domain.HandoverThread, actorID string) (*domain.HandoverThread, error) {
+	history, err := s.handoverRepo.GetActiveReadingHistory(ctx, cancelled.BookID)
+	if err != nil {
+		return nil, err
+	}
+	nextRequest, err := s.handoverRepo.GetNextApprovedRequest(ctx, cancelled.BookID)
+	if err != nil {
+		return nil, err
+	}
+
+	if nextRequest == nil {
+		return nil, s.park(ctx, cancelled, actorID, history)
+	}
+
+	dueDate := cancelled.HandoverDueDate
+	if dueDate.Before(time.Now()) {
+		dueDate = time.Now().Add(reofferWindow)
+	}
+
+	thread := &domain.HandoverThread{
+		BookID:          cancelled.BookID,
+		CurrentHolderID: cancelled.CurrentHolderID,
+		NextHolderID:    nextRequest.UserID,
+		Status:          string(domain.HandoverActive),
+		HandoverDueDate: dueDate,
+		IsPublic:        true,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+	}
+	if history != nil {
+		thread.ReadingHistoryID = &history.ID
+		if err := s.handoverRepo.UpdateReadingHistoryNextReader(ctx, history.ID, nextRequest.UserID); err != nil {
+			return nil, err
+		}
+		if history.IsCompleted {
+			if err := s.handoverRepo.UpdateReadingHistoryDeliveryStatus(ctx, history.ID, domain.DeliveryInTransit, nil); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := s.handoverRepo.CreateHandoverThread(ctx, thread); err != nil {
+		return nil, err
+	}
+
+	systemMsg := SystemMessage(thread.ID, thread.CurrentHolderID, "handover.system.thread_created", map[string]string{
+		"due_date": dueDate.Format(i18n.DateLayout),
+	})
+	if err := s.handoverRepo.CreateHandoverMessage(ctx, systemMsg); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("handover reoffered",
+		zap.String("book_id", thread.BookID),
+		zap.String("current_holder", thread.CurrentHolderID),
+		zap.String("next_holder", thread.NextHolderID))
+	return thread, nil
+}
+
+// park leaves the book with its holder until someone requests it. A reader
+// who has not finished keeps reading.
+func (s *service) park(ctx context.Context, cancelled *domain.HandoverThread, actorID string, history *domain.ReadingHistoryExtended) error {
+	if history != nil && !history.IsCompleted {
+		return nil
+	}
+
+	change := booklifecycle.Change{
+		BookID:  cancelled.BookID,
+		Action:  domain.BookHandoverCancelled,
+		To:      domain.StatusOnHold,
+		ActorID: actorID,
+	}
+	switch actorID {
+	case cancelled.CurrentHolderID:
+		change.Actor = domain.BookActorHolder
+	case cancelled.NextHolderID:
+		change.Actor = domain.BookActorReader
+	default:
+		change.Actor = domain.BookActorAdmin
+	}
+
+	if history != nil {
+		if err := s.handoverRepo.CloseReadingHistory(ctx, history.ID, time.Now()); err != nil {
+			return err
+		}
+		return s.lifecycle.Transition(ctx, change)
+	}
+
+	// Nobody has read it yet; it goes back on the shelf
+	last, err := s.handoverRepo.GetLastCompletedReadingHistory(ctx, cancelled.BookID)
+	if err != nil {
+		return err
+	}
+	if last == nil {
+		change.To = domain.StatusAvailable
+	}
+	return s.lifecycle.Transition(ctx, change)
+}
@@ -0,0 +1,44 @@
+package handover
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/online-library/internal/domain"
+	"github.com/yourusername/online-library/internal/i18n"
+	"go.uber.org/zap"
+)
+
+// SystemMessage builds a system message from an i18n catalog key. The
+// English text is stored as well for clients that ignore the key.
+func SystemMessage(threadID, userID, key string, params map[string]string) *domain.HandoverMessage {
+	return &domain.HandoverMessage{
+		ThreadID:        threadID,
+		UserID:          userID,
+		Message:         i18n.T(i18n.Default, key, i18n.StringParams(params)),
+		IsSystemMessage: true,
+		MessageKey:      key,
+		MessageParams:   params,
+		CreatedAt:       time.Now(),
+	}
+}
+
+// viewerLocale returns the locale system messages are rendered in for
+// userID, falling back to the default if it cannot be loaded
+func (s *service) viewerLocale(ctx context.Context, userID string) i18n.Locale {
+	locale, err := s.handoverRepo.GetUserLocale(ctx, userID)
+	if err != nil {
+		s.log.Warn("failed to load user locale", zap.String("user_id", userID), zap.Error(err))
+		return i18n.Default
+	}
+	return i18n.Parse(locale)
+}
+
+// localize renders keyed system messages in locale
+func localize(locale i18n.Locale, messages []domain.HandoverMessage) {
+	for i := range messages {
+		if messages[i].MessageKey != "" {
+			messages[i].Message = i18n.T(locale, messages[i].MessageKey, i18n.StringParams(messages[i].MessageParams))
+		}
+	}
+}
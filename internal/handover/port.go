@@ -23,8 +23,23 @@ type Service interface {
 	// Post message to handover thread
 	PostHandoverMessage(ctx context.Context, threadID, userID, message string) error
 
-	// Get messages for a handover thread
-	GetHandoverMessages(ctx context.Context, threadID string) ([]domain.HandoverMessage, error)
+	// Get messages for a handover thread, with system messages in the
+	// viewer's language
+	GetHandoverMessages(ctx context.Context, threadID, viewerID string) ([]domain.HandoverMessage, error)
+
+	// Stream messages and status changes of a thread to a participant,
+	// starting after lastMessageID (or from the beginning if empty)
+	StreamThread(ctx context.Context, threadID, userID, lastMessageID string) (<-chan domain.HandoverEvent, error)
+
+	// CancelThread lets a participant call off an active handover. Backing
+	// out (withdrawn) counts against the canceller; reporting the other
+	// participant as unresponsive is only possible once the handover is
+	// overdue and counts against them.
+	CancelThread(ctx context.Context, threadID, userID string, reason domain.HandoverCancelReason, note string) (*domain.HandoverThread, error)
+
+	// AdminCancelThread cancels a handover on behalf of the participants,
+	// optionally holding one of them (atFaultID) responsible
+	AdminCancelThread(ctx context.Context, threadID, adminID string, reason domain.HandoverCancelReason, note, atFaultID string) (*domain.HandoverThread, error)
 
 	// Check and create handover threads for books nearing due date (cron job)
 	CheckAndCreateHandoverThreads(ctx context.Context) error
@@ -49,14 +64,36 @@ type HandoverRepo interface {
 	GetActiveHandoverThreadByBook(ctx context.Context, bookID string) (*domain.HandoverThread, error)
 	GetHandoverThreadsByUser(ctx context.Context, userID string) ([]*domain.HandoverThread, error)
 	UpdateHandoverThreadStatus(ctx context.Context, threadID string, status domain.HandoverThreadStatus, completedAt *time.Time) error
+	// CancelHandoverThread returns domain.ErrHandoverNotActive if the
+	// thread is no longer active
+	CancelHandoverThread(ctx context.Context, thread *domain.HandoverThread) error
 
 	// Handover message operations
 	CreateHandoverMessage(ctx context.Context, message *domain.HandoverMessage) error
 	GetHandoverMessagesByThread(ctx context.Context, threadID string) ([]domain.HandoverMessage, error)
+	GetHandoverMessagesAfter(ctx context.Context, threadID, afterID string) ([]domain.HandoverMessage, error)
+
+	// User operations
+	GetUserLocale(ctx context.Context, userID string) (string, error)
 
 	// Book operations
+	// LockBook serializes changes to a book; call it first inside
+	// UnitOfWork.Do
+	LockBook(ctx context.Context, bookID string) error
 	GetNextApprovedRequest(ctx context.Context, bookID string) (*domain.BookRequest, error)
 	UpdateReadingHistoryNextReader(ctx context.Context, historyID, nextReaderID string) error
-	UpdateBookStatus(ctx context.Context, bookID string, status domain.BookStatus) error
-	AssignBookToUser(ctx context.Context, bookID, userID string) error
+	ClearReadingHistoryNextReader(ctx context.Context, historyID string) error
+	CancelApprovedRequest(ctx context.Context, bookID, userID string) error
+}
+
+// UnitOfWork runs fn in one database transaction. Repository calls made
+// with the context passed to fn take part in it.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// EventSubscriber delivers change hints published through Postgres
+// LISTEN/NOTIFY. The channel closes when ctx is done.
+type EventSubscriber interface {
+	Subscribe(ctx context.Context, channel string) (<-chan string, error)
 }
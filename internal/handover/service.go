@@ -5,120 +5,148 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/yourusername/online-library/internal/booklifecycle"
+	"github.com/yourusername/online-library/internal/content"
 	"github.com/yourusername/online-library/internal/domain"
+	"github.com/yourusername/online-library/internal/i18n"
 	"github.com/yourusername/online-library/internal/notification"
+	"github.com/yourusername/online-library/internal/successscore"
 	"go.uber.org/zap"
 )
 
 type service struct {
 	handoverRepo    HandoverRepo
 	notificationSvc notification.Service
+	successScoreSvc successscore.Service
+	lifecycle       booklifecycle.Service
+	contentSvc      content.Service
+	uow             UnitOfWork
+	subscriber      EventSubscriber
 	log             *zap.Logger
 }
 
-func NewService(handoverRepo HandoverRepo, notificationSvc notification.Service, log *zap.Logger) Service {
+func NewService(handoverRepo HandoverRepo, notificationSvc notification.Service, successScoreSvc successscore.Service, lifecycle booklifecycle.Service, contentSvc content.Service, uow UnitOfWork, subscriber EventSubscriber, log *zap.Logger) Service {
 	return &service{
 		handoverRepo:    handoverRepo,
 		notificationSvc: notificationSvc,
+		successScoreSvc: successScoreSvc,
+		lifecycle:       lifecycle,
+		contentSvc:      contentSvc,
+		uow:             uow,
+		subscriber:      subscriber,
 		log:             log,
 	}
 }
 
 func (s *service) MarkBookCompleted(ctx context.Context, userID, bookID string) error {
-	// Get active reading history
-	history, err := s.handoverRepo.GetActiveReadingHistory(ctx, bookID)
-	if err != nil {
-		return fmt.Errorf("failed to get reading history: %w", err)
-	}
+	var history *domain.ReadingHistoryExtended
+	var hasNextReader bool
 
-	if history == nil {
-		// Check if there's already a completed reading history for this user
-		lastHistory, err := s.handoverRepo.GetLastCompletedReadingHistory(ctx, bookID)
-		if err == nil && lastHistory != nil && lastHistory.ReaderID == userID {
-			// User already completed this book
-			return fmt.Errorf("you have already marked this book as completed")
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.handoverRepo.LockBook(ctx, bookID); err != nil {
+			return err
 		}
 
-		// No active reading history found - this can happen for old books
-		// Create a reading history entry for this book
-		s.log.Warn("no active reading history found, creating one", zap.String("book_id", bookID), zap.String("user_id", userID))
-
-		// Create reading history starting from now
-		if err := s.handoverRepo.StartNewReadingHistory(ctx, bookID, userID); err != nil {
-			return fmt.Errorf("failed to create reading history: %w", err)
-		}
-
-		// Get the newly created history
+		// Get active reading history
+		var err error
 		history, err = s.handoverRepo.GetActiveReadingHistory(ctx, bookID)
-		if err != nil || history == nil {
-			return fmt.Errorf("failed to get newly created reading history: %w", err)
+		if err != nil {
+			return fmt.Errorf("failed to get reading history: %w", err)
 		}
-	}
 
-	if history.ReaderID != userID {
-		return fmt.Errorf("you are not the current holder of this book")
-	}
+		if history == nil {
+			// Check if there's already a completed reading history for this user
+			lastHistory, err := s.handoverRepo.GetLastCompletedReadingHistory(ctx, bookID)
+			if err != nil {
+				return fmt.Errorf("failed to get reading history: %w", err)
+			}
+			if lastHistory != nil && lastHistory.ReaderID == userID {
+				// User already completed this book
+				return fmt.Errorf("you have already marked this book as completed")
+			}
 
-	if history.IsCompleted {
-		return fmt.Errorf("book already marked as completed")
-	}
+			// No active reading history found - this can happen for old books
+			// Create a reading history entry for this book
+			s.log.Warn("no active reading history found, creating one", zap.String("book_id", bookID), zap.String("user_id", userID))
 
-	// Mark as completed
-	completedAt := time.Now()
-	if err := s.handoverRepo.UpdateReadingHistoryCompleted(ctx, history.ID, completedAt); err != nil {
-		return fmt.Errorf("failed to mark book as completed: %w", err)
-	}
+			// Create reading history starting from now
+			if err := s.handoverRepo.StartNewReadingHistory(ctx, bookID, userID); err != nil {
+				return fmt.Errorf("failed to create reading history: %w", err)
+			}
 
-	// Check if there's a next reader
-	hasNextReader := history.NextReaderID != nil && *history.NextReaderID != ""
+			// Get the newly created history
+			history, err = s.handoverRepo.GetActiveReadingHistory(ctx, bookID)
+			if err != nil || history == nil {
+				return fmt.Errorf("failed to get newly created reading history: %w", err)
+			}
+		}
 
-	if hasNextReader {
-		// Update delivery status to in_transit
-		if err := s.handoverRepo.UpdateReadingHistoryDeliveryStatus(ctx, history.ID, domain.DeliveryInTransit, nil); err != nil {
-			s.log.Error("failed to update delivery status", zap.Error(err))
+		if history.ReaderID != userID {
+			return fmt.Errorf("you are not the current holder of this book")
 		}
 
-		// Notify next reader
-		if err := s.notificationSvc.NotifyBookInTransit(ctx, *history.NextReaderID, bookID, history.Book.Title); err != nil {
-			s.log.Error("failed to send notification", zap.Error(err))
+		if history.IsCompleted {
+			return fmt.Errorf("book already marked as completed")
+		}
+
+		// Mark as completed
+		completedAt := time.Now()
+		if err := s.handoverRepo.UpdateReadingHistoryCompleted(ctx, history.ID, completedAt); err != nil {
+			return fmt.Errorf("failed to mark book as completed: %w", err)
 		}
-	} else {
+
+		// Check if there's a next reader
+		hasNextReader = history.NextReaderID != nil && *history.NextReaderID != ""
+
+		if hasNextReader {
+			// Update delivery status to in_transit
+			if err := s.handoverRepo.UpdateReadingHistoryDeliveryStatus(ctx, history.ID, domain.DeliveryInTransit, nil); err != nil {
+				return fmt.Errorf("failed to update delivery status: %w", err)
+			}
+			return nil
+		}
+
 		// No next reader, close reading history and mark book as on_hold
 		// Book stays with the reader (no penalty) until someone requests it
 		if err := s.handoverRepo.CloseReadingHistory(ctx, history.ID, completedAt); err != nil {
-			s.log.Error("failed to close reading history", zap.Error(err))
 			return fmt.Errorf("failed to close reading history: %w", err)
 		}
 
-		if err := s.handoverRepo.UpdateBookStatus(ctx, bookID, domain.StatusOnHold); err != nil {
-			s.log.Error("failed to update book status to on_hold", zap.String("book_id", bookID), zap.Error(err))
-			return fmt.Errorf("failed to update book status: %w", err)
+		if err := s.lifecycle.Transition(ctx, booklifecycle.Change{
+			BookID:  bookID,
+			Action:  domain.BookCompleted,
+			To:      domain.StatusOnHold,
+			Actor:   domain.BookActorHolder,
+			ActorID: userID,
+		}); err != nil {
+			return err
 		}
 
-		s.log.Info("book status updated to on_hold", zap.String("book_id", bookID))
-
-		// Keep the book assigned to the current reader
-		// They will hold it until someone requests it
-
 		// Close any active handover thread since there's no next reader
 		activeThread, err := s.handoverRepo.GetActiveHandoverThreadByBook(ctx, bookID)
-		if err == nil && activeThread != nil {
+		if err != nil {
+			return fmt.Errorf("failed to get handover thread: %w", err)
+		}
+		if activeThread != nil {
 			if err := s.handoverRepo.UpdateHandoverThreadStatus(ctx, activeThread.ID, domain.HandoverCompleted, &completedAt); err != nil {
-				s.log.Error("failed to complete handover thread", zap.Error(err))
-			} else {
-				// Post system message
-				systemMsg := &domain.HandoverMessage{
-					ThreadID:        activeThread.ID,
-					UserID:          userID,
-					Message:         "📚 Book reading completed. Book is on hold until next request.",
-					IsSystemMessage: true,
-					CreatedAt:       time.Now(),
-				}
-				if err := s.handoverRepo.CreateHandoverMessage(ctx, systemMsg); err != nil {
-					s.log.Error("failed to create system message", zap.Error(err))
-				}
+				return fmt.Errorf("failed to complete handover thread: %w", err)
+			}
+			systemMsg := SystemMessage(activeThread.ID, userID, "handover.system.reading_completed", nil)
+			if err := s.handoverRepo.CreateHandoverMessage(ctx, systemMsg); err != nil {
+				return fmt.Errorf("failed to create system message: %w", err)
 			}
 		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if hasNextReader {
+		// Notify next reader
+		if err := s.notificationSvc.NotifyBookInTransit(ctx, *history.NextReaderID, bookID, history.Book.Title); err != nil {
+			s.log.Error("failed to send notification", zap.Error(err))
+		}
 	}
 
 	s.log.Info("book marked as completed",
@@ -129,84 +157,92 @@ func (s *service) MarkBookCompleted(ctx context.Context, userID, bookID string)
 }
 
 func (s *service) MarkBookDelivered(ctx context.Context, userID, bookID string) error {
-	// Check if there's an active handover thread where user is the next holder
-	thread, err := s.handoverRepo.GetActiveHandoverThreadByBook(ctx, bookID)
-	if err != nil {
-		return fmt.Errorf("failed to get handover thread: %w", err)
-	}
-
-	if thread == nil {
-		return fmt.Errorf("no active handover thread found")
-	}
-
-	// Check if user is the next holder in the thread
-	if thread.NextHolderID != userID {
-		return fmt.Errorf("you are not the next holder for this book")
-	}
-
-	// Get active reading history (may not exist for initial handover)
-	history, err := s.handoverRepo.GetActiveReadingHistory(ctx, bookID)
+	// previous is the reading history of the reader who handed the book
+	// over, if any
+	var previous *domain.ReadingHistoryExtended
+
+	// The book lock serializes deliveries, completions and approvals of the
+	// same book; everything below commits together or not at all
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.handoverRepo.LockBook(ctx, bookID); err != nil {
+			return err
+		}
 
-	if history == nil {
-		// Initial handover case: No reading history exists yet
-		// Create new reading history for the first reader
-		if err := s.handoverRepo.StartNewReadingHistory(ctx, bookID, userID); err != nil {
-			return fmt.Errorf("failed to start reading history: %w", err)
+		// Check if there's an active handover thread where user is the next holder
+		thread, err := s.handoverRepo.GetActiveHandoverThreadByBook(ctx, bookID)
+		if err != nil {
+			return fmt.Errorf("failed to get handover thread: %w", err)
 		}
 
-		// Update book status to reading and assign to user
-		if err := s.handoverRepo.UpdateBookStatus(ctx, bookID, domain.StatusReading); err != nil {
-			s.log.Error("failed to update book status", zap.Error(err))
+		if thread == nil {
+			return fmt.Errorf("no active handover thread found")
 		}
 
-		// Assign book to user
-		if err := s.handoverRepo.AssignBookToUser(ctx, bookID, userID); err != nil {
-			s.log.Error("failed to assign book to user", zap.Error(err))
+		// Check if user is the next holder in the thread
+		if thread.NextHolderID != userID {
+			return fmt.Errorf("you are not the next holder for this book")
 		}
-	} else {
-		// Reader-to-reader handover case
-		if history.DeliveryStatus == string(domain.DeliveryDelivered) {
-			return fmt.Errorf("book already marked as delivered")
+
+		// Get active reading history (may not exist for initial handover)
+		history, err := s.handoverRepo.GetActiveReadingHistory(ctx, bookID)
+		if err != nil {
+			return fmt.Errorf("failed to get reading history: %w", err)
 		}
 
-		// Mark as delivered
 		deliveredAt := time.Now()
-		if err := s.handoverRepo.UpdateReadingHistoryDeliveryStatus(ctx, history.ID, domain.DeliveryDelivered, &deliveredAt); err != nil {
-			return fmt.Errorf("failed to mark book as delivered: %w", err)
-		}
+		if history != nil {
+			// Reader-to-reader handover case
+			if history.DeliveryStatus == string(domain.DeliveryDelivered) {
+				return fmt.Errorf("book already marked as delivered")
+			}
 
-		// Close the old reading history
-		if err := s.handoverRepo.CloseReadingHistory(ctx, history.ID, deliveredAt); err != nil {
-			s.log.Error("failed to close reading history", zap.Error(err))
+			// Mark as delivered
+			if err := s.handoverRepo.UpdateReadingHistoryDeliveryStatus(ctx, history.ID, domain.DeliveryDelivered, &deliveredAt); err != nil {
+				return fmt.Errorf("failed to mark book as delivered: %w", err)
+			}
+
+			// Close the old reading history
+			if err := s.handoverRepo.CloseReadingHistory(ctx, history.ID, deliveredAt); err != nil {
+				return fmt.Errorf("failed to close reading history: %w", err)
+			}
+			previous = history
 		}
 
-		// Start new reading history for the next reader
+		// Start the new reader's history and hand them the book
 		if err := s.handoverRepo.StartNewReadingHistory(ctx, bookID, userID); err != nil {
-			s.log.Error("failed to start new reading history", zap.Error(err))
+			return fmt.Errorf("failed to start reading history: %w", err)
+		}
+		if err := s.lifecycle.Transition(ctx, booklifecycle.Change{
+			BookID:  bookID,
+			Action:  domain.BookDelivered,
+			To:      domain.StatusReading,
+			Actor:   domain.BookActorReader,
+			ActorID: userID,
+		}); err != nil {
+			return err
 		}
 
-		// Notify previous holder
-		if err := s.notificationSvc.NotifyBookDelivered(ctx, history.ReaderID, bookID, history.Book.Title); err != nil {
-			s.log.Error("failed to send notification", zap.Error(err))
+		// Complete the handover thread
+		if err := s.handoverRepo.UpdateHandoverThreadStatus(ctx, thread.ID, domain.HandoverCompleted, &deliveredAt); err != nil {
+			return fmt.Errorf("failed to complete handover thread: %w", err)
 		}
-	}
 
-	// Complete the handover thread
-	completedAt := time.Now()
-	if err := s.handoverRepo.UpdateHandoverThreadStatus(ctx, thread.ID, domain.HandoverCompleted, &completedAt); err != nil {
-		s.log.Error("failed to complete handover thread", zap.Error(err))
+		// Post system message
+		systemMsg := SystemMessage(thread.ID, userID, "handover.system.delivered", nil)
+		if err := s.handoverRepo.CreateHandoverMessage(ctx, systemMsg); err != nil {
+			return fmt.Errorf("failed to create system message: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	// Post system message
-	systemMsg := &domain.HandoverMessage{
-		ThreadID:        thread.ID,
-		UserID:          userID,
-		Message:         "📦 Book has been delivered and received successfully!",
-		IsSystemMessage: true,
-		CreatedAt:       time.Now(),
-	}
-	if err := s.handoverRepo.CreateHandoverMessage(ctx, systemMsg); err != nil {
-		s.log.Error("failed to create system message", zap.Error(err))
+	// Notify previous holder
+	if previous != nil {
+		if err := s.notificationSvc.NotifyBookDelivered(ctx, previous.ReaderID, bookID, previous.Book.Title); err != nil {
+			s.log.Error("failed to send notification", zap.Error(err))
+		}
 	}
 
 	s.log.Info("book marked as delivered", zap.String("book_id", bookID), zap.String("user_id", userID))
@@ -236,12 +272,18 @@ func (s *service) PostHandoverMessage(ctx context.Context, threadID, userID, mes
 		return fmt.Errorf("you are not a participant in this handover")
 	}
 
+	rendered, err := s.contentSvc.Render(ctx, message, content.MaxHandoverMessage)
+	if err != nil {
+		return err
+	}
+
 	msg := &domain.HandoverMessage{
 		ThreadID:        threadID,
 		UserID:          userID,
-		Message:         message,
+		Message:         rendered.Source,
 		IsSystemMessage: false,
 		CreatedAt:       time.Now(),
+		MessageHTML:     rendered.HTML,
 	}
 
 	if err := s.handoverRepo.CreateHandoverMessage(ctx, msg); err != nil {
@@ -254,15 +296,31 @@ func (s *service) PostHandoverMessage(ctx context.Context, threadID, userID, mes
 		otherUserID = thread.CurrentHolderID
 	}
 
-	if err := s.notificationSvc.NotifyHandoverMessage(ctx, otherUserID, thread.BookID, thread.Book.Title); err != nil {
+	if err := s.notificationSvc.NotifyHandoverMessage(ctx, otherUserID, thread.ID, thread.BookID, thread.Book.Title); err != nil {
 		s.log.Error("failed to send notification", zap.Error(err))
 	}
 
+	// The other participant already heard about the message
+	source := domain.MentionSource{Type: domain.MentionInHandover, ID: thread.ID, BookID: thread.BookID, Title: thread.Book.Title}
+	for _, m := range rendered.Mentions {
+		if m.UserID == userID || m.UserID == otherUserID {
+			continue
+		}
+		if err := s.notificationSvc.NotifyMention(ctx, m.UserID, userID, source); err != nil {
+			s.log.Warn("failed to notify mentioned user", zap.String("user_id", m.UserID), zap.Error(err))
+		}
+	}
+
 	return nil
 }
 
-func (s *service) GetHandoverMessages(ctx context.Context, threadID string) ([]domain.HandoverMessage, error) {
-	return s.handoverRepo.GetHandoverMessagesByThread(ctx, threadID)
+func (s *service) GetHandoverMessages(ctx context.Context, threadID, viewerID string) ([]domain.HandoverMessage, error) {
+	messages, err := s.handoverRepo.GetHandoverMessagesByThread(ctx, threadID)
+	if err != nil {
+		return nil, err
+	}
+	localize(s.viewerLocale(ctx, viewerID), messages)
+	return messages, nil
 }
 
 func (s *service) CheckAndCreateHandoverThreads(ctx context.Context) error {
@@ -321,20 +379,16 @@ func (s *service) CheckAndCreateHandoverThreads(ctx context.Context) error {
 		}
 
 		// Create system message
-		systemMsg := &domain.HandoverMessage{
-			ThreadID:        thread.ID,
-			UserID:          history.ReaderID,
-			Message:         fmt.Sprintf("📚 Handover thread created. Book is due on %s. Please coordinate the handover.", history.DueDate.Format("Jan 2, 2006")),
-			IsSystemMessage: true,
-			CreatedAt:       time.Now(),
-		}
+		systemMsg := SystemMessage(thread.ID, history.ReaderID, "handover.system.thread_created", map[string]string{
+			"due_date": history.DueDate.Format(i18n.DateLayout),
+		})
 
 		if err := s.handoverRepo.CreateHandoverMessage(ctx, systemMsg); err != nil {
 			s.log.Error("failed to create system message", zap.Error(err))
 		}
 
 		// Notify both users
-		if err := s.notificationSvc.NotifyHandoverThreadCreated(ctx, history.ReaderID, nextRequest.UserID, history.BookID, history.Book.Title); err != nil {
+		if err := s.notificationSvc.NotifyHandoverThreadCreated(ctx, thread.ID, history.ReaderID, nextRequest.UserID, history.BookID, history.Book.Title); err != nil {
 			s.log.Error("failed to send notifications", zap.Error(err))
 		}
 
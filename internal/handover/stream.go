@@ -0,0 +1,126 @@
+package handover
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/online-library/internal/domain"
+	"go.uber.org/zap"
+)
+
+// ThreadChannel is the LISTEN/NOTIFY channel the database triggers publish
+// a thread's activity on.
+func ThreadChannel(threadID string) string {
+	return "handover_thread_" + threadID
+}
+
+// threadNotice is the payload written by the notify_handover_* triggers
+type threadNotice struct {
+	Type   domain.HandoverEventType `json:"type"`
+	ID     string                   `json:"id,omitempty"`
+	Status string                   `json:"status,omitempty"`
+}
+
+func (s *service) StreamThread(ctx context.Context, threadID, userID, lastMessageID string) (<-chan domain.HandoverEvent, error) {
+	id, err := uuid.Parse(threadID)
+	if err != nil {
+		return nil, domain.ErrInvalidInput
+	}
+	threadID = id.String()
+	if _, err := uuid.Parse(lastMessageID); err != nil {
+		lastMessageID = ""
+	}
+
+	thread, err := s.handoverRepo.GetHandoverThreadByID(ctx, threadID)
+	if err != nil {
+		return nil, err
+	}
+	if thread == nil {
+		return nil, domain.ErrNotFound
+	}
+	if thread.CurrentHolderID != userID && thread.NextHolderID != userID {
+		return nil, domain.ErrForbidden
+	}
+
+	// Subscribe before catching up so nothing published in between is lost
+	notices, err := s.subscriber.Subscribe(ctx, ThreadChannel(threadID))
+	if err != nil {
+		s.log.Error("failed to subscribe to handover thread", zap.String("thread_id", threadID), zap.Error(err))
+		return nil, err
+	}
+
+	locale := s.viewerLocale(ctx, userID)
+
+	events := make(chan domain.HandoverEvent)
+	go func() {
+		defer close(events)
+
+		cursor := lastMessageID
+		send := func(ev domain.HandoverEvent) bool {
+			select {
+			case events <- ev:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		catchUp := func() bool {
+			messages, err := s.handoverRepo.GetHandoverMessagesAfter(ctx, threadID, cursor)
+			if err != nil {
+				s.log.Warn("failed to load handover messages", zap.String("thread_id", threadID), zap.Error(err))
+				return ctx.Err() == nil
+			}
+			localize(locale, messages)
+			for i := range messages {
+				if !send(domain.HandoverEvent{Type: domain.HandoverEventMessage, Message: &messages[i]}) {
+					return false
+				}
+				cursor = messages[i].ID
+			}
+			return true
+		}
+
+		if !send(domain.HandoverEvent{Type: domain.HandoverEventStatus, Status: thread.Status}) || !catchUp() {
+			return
+		}
+
+		for payload := range notices {
+			if payload == "" {
+				// The listener reconnected; anything may have changed
+				if !s.resync(ctx, threadID, send) || !catchUp() {
+					return
+				}
+				continue
+			}
+
+			var notice threadNotice
+			if err := json.Unmarshal([]byte(payload), &notice); err != nil {
+				s.log.Warn("invalid handover notice", zap.String("payload", payload), zap.Error(err))
+				continue
+			}
+
+			if notice.Type == domain.HandoverEventStatus {
+				if !send(domain.HandoverEvent{Type: domain.HandoverEventStatus, Status: notice.Status}) {
+					return
+				}
+				continue
+			}
+
+			if !catchUp() {
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// resync re-sends the thread's current status
+func (s *service) resync(ctx context.Context, threadID string, send func(domain.HandoverEvent) bool) bool {
+	thread, err := s.handoverRepo.GetHandoverThreadByID(ctx, threadID)
+	if err != nil || thread == nil {
+		return ctx.Err() == nil
+	}
+	return send(domain.HandoverEvent{Type: domain.HandoverEventStatus, Status: thread.Status})
+}
@@ -0,0 +1,101 @@
+package i18n
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateLayout is how dates are passed as stored string parameters
+const DateLayout = "2006-01-02"
+
+var banglaDigits = []rune("০১২৩৪৫৬৭৮৯")
+
+var banglaMonths = [...]string{
+	"জানুয়ারি", "ফেব্রুয়ারি", "মার্চ", "এপ্রিল", "মে", "জুন",
+	"জুলাই", "আগস্ট", "সেপ্টেম্বর", "অক্টোবর", "নভেম্বর", "ডিসেম্বর",
+}
+
+// Digits replaces ASCII digits in s with the locale's digits
+func Digits(locale Locale, s string) string {
+	if locale != Bangla {
+		return s
+	}
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return banglaDigits[r-'0']
+		}
+		return r
+	}, s)
+}
+
+// FormatNumber groups n by thousands in English ("1,234,567") and in the
+// South Asian lakh/crore style with Bengali digits in Bangla ("১২,৩৪,৫৬৭")
+func FormatNumber(locale Locale, n int64) string {
+	sign := ""
+	if n < 0 {
+		sign = "-"
+		n = -n
+	}
+	digits := strconv.FormatInt(n, 10)
+
+	var groups []string
+	if locale == Bangla && len(digits) > 3 {
+		// Last three digits, then pairs
+		groups = append(groups, digits[len(digits)-3:])
+		digits = digits[:len(digits)-3]
+		for len(digits) > 2 {
+			groups = append([]string{digits[len(digits)-2:]}, groups...)
+			digits = digits[:len(digits)-2]
+		}
+	} else {
+		for len(digits) > 3 {
+			groups = append([]string{digits[len(digits)-3:]}, groups...)
+			digits = digits[:len(digits)-3]
+		}
+	}
+	groups = append([]string{digits}, groups...)
+	return Digits(locale, sign+strings.Join(groups, ","))
+}
+
+// FormatDate formats t as "Jan 2, 2006" in English and "২ জানুয়ারি ২০০৬"
+// in Bangla
+func FormatDate(locale Locale, t time.Time) string {
+	if locale == Bangla {
+		return Digits(locale, strconv.Itoa(t.Day())) + " " + banglaMonths[t.Month()-1] + " " + Digits(locale, strconv.Itoa(t.Year()))
+	}
+	return t.Format("Jan 2, 2006")
+}
+
+func formatDateValue(locale Locale, v interface{}) string {
+	switch d := v.(type) {
+	case time.Time:
+		return FormatDate(locale, d)
+	case string:
+		if t, err := time.Parse(DateLayout, d); err == nil {
+			return FormatDate(locale, t)
+		}
+		if t, err := time.Parse(time.RFC3339, d); err == nil {
+			return FormatDate(locale, t)
+		}
+		return d
+	}
+	return ""
+}
+
+func formatNumberValue(locale Locale, v interface{}) string {
+	switch n := v.(type) {
+	case int:
+		return FormatNumber(locale, int64(n))
+	case int64:
+		return FormatNumber(locale, n)
+	case float64:
+		return FormatNumber(locale, int64(n))
+	case string:
+		if i, err := strconv.ParseInt(n, 10, 64); err == nil {
+			return FormatNumber(locale, i)
+		}
+		return Digits(locale, n)
+	}
+	return ""
+}
@@ -0,0 +1,96 @@
+// Package i18n renders user-facing texts from a catalog keyed by message
+// key and locale, and formats dates and numbers per locale.
+package i18n
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"text/template"
+)
+
+type Locale string
+
+const (
+	English Locale = "en"
+	Bangla  Locale = "bn"
+
+	// Default is used for unknown locales and missing translations
+	Default = English
+)
+
+//go:embed locales/*.json
+var localeFS embed.FS
+
+// catalog holds the parsed templates of every supported locale
+var catalog = map[Locale]map[string]*template.Template{}
+
+func init() {
+	files, err := localeFS.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+	for _, f := range files {
+		locale := Locale(strings.TrimSuffix(f.Name(), ".json"))
+		raw, err := localeFS.ReadFile(path.Join("locales", f.Name()))
+		if err != nil {
+			panic(err)
+		}
+		var texts map[string]string
+		if err := json.Unmarshal(raw, &texts); err != nil {
+			panic(fmt.Sprintf("i18n: %s: %v", f.Name(), err))
+		}
+
+		funcs := template.FuncMap{
+			"date":   func(v interface{}) string { return formatDateValue(locale, v) },
+			"number": func(v interface{}) string { return formatNumberValue(locale, v) },
+		}
+		catalog[locale] = make(map[string]*template.Template, len(texts))
+		for key, text := range texts {
+			catalog[locale][key] = template.Must(template.New(key).Funcs(funcs).Option("missingkey=zero").Parse(text))
+		}
+	}
+}
+
+// Parse returns the supported locale matching s, or Default
+func Parse(s string) Locale {
+	l := Locale(strings.ToLower(strings.TrimSpace(s)))
+	if _, ok := catalog[l]; ok {
+		return l
+	}
+	return Default
+}
+
+// Supported reports whether s names a locale with a catalog
+func Supported(s string) bool {
+	_, ok := catalog[Locale(s)]
+	return ok
+}
+
+// T renders the text for key in locale. Keys missing from the locale fall
+// back to Default, and unknown keys render as the key itself.
+func T(locale Locale, key string, params map[string]interface{}) string {
+	tmpl, ok := catalog[locale][key]
+	if !ok {
+		if tmpl, ok = catalog[Default][key]; !ok {
+			return key
+		}
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, params); err != nil {
+		return key
+	}
+	return buf.String()
+}
+
+// StringParams adapts stored string parameters for T
+func StringParams(params map[string]string) map[string]interface{} {
+	out := make(map[string]interface{}, len(params))
+	for k, v := range params {
+		out[k] = v
+	}
+	return out
+}
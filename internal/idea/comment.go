@@ -0,0 +1,225 @@
+package idea
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/online-library/internal/content"
+	"github.com/yourusername/online-library/internal/domain"
+	"go.uber.org/zap"
+)
+
+const (
+	// Authors can edit a comment for commentEditWindow and delete it for
+	// commentDeleteWindow after posting
+	commentEditWindow   = 15 * time.Minute
+	commentDeleteWindow = 24 * time.Hour
+	// maxCommentDepth caps nesting; replies to a comment at this depth are
+	// added next to it instead of under it
+	maxCommentDepth = 4
+)
+
+func (s *service) ListComments(ctx context.Context, ideaID string) ([]*domain.IdeaComment, error) {
+	if _, err := s.ideaRepo.FindByID(ctx, ideaID); err != nil {
+		return nil, err
+	}
+	comments, err := s.ideaRepo.ListComments(ctx, ideaID)
+	if err != nil {
+		s.log.Error("failed to list comments", zap.String("idea_id", ideaID), zap.Error(err))
+		return nil, err
+	}
+	return buildThread(comments), nil
+}
+
+func (s *service) AddComment(ctx context.Context, comment *domain.IdeaComment) (*domain.IdeaComment, error) {
+	comment.Content = strings.TrimSpace(comment.Content)
+	if comment.Content == "" {
+		return nil, domain.ErrInvalidInput
+	}
+	rendered, err := s.contentSvc.Render(ctx, comment.Content, content.MaxIdeaComment)
+	if err != nil {
+		return nil, err
+	}
+	comment.Content = rendered.Source
+	comment.ContentHTML = rendered.HTML
+
+	idea, err := s.ideaRepo.FindByID(ctx, comment.IdeaID)
+	if err != nil {
+		return nil, err
+	}
+	if s.commentsReadersOnly && comment.UserID != idea.UserID {
+		read, err := s.ideaRepo.HasReadBook(ctx, comment.UserID, idea.BookID)
+		if err != nil {
+			return nil, err
+		}
+		if !read {
+			return nil, domain.ErrCommentNotAllowed
+		}
+	}
+
+	var parent *domain.IdeaComment
+	if comment.ParentID != nil {
+		parent, err = s.ideaRepo.FindCommentByID(ctx, *comment.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		if parent.IdeaID != idea.ID || parent.DeletedAt != nil {
+			return nil, domain.ErrNotFound
+		}
+		comment.Depth = parent.Depth + 1
+		if parent.Depth >= maxCommentDepth {
+			comment.ParentID = parent.ParentID
+			comment.Depth = parent.Depth
+		}
+	}
+
+	comment.ID = uuid.New().String()
+	comment.CreatedAt = time.Now()
+	if err := s.ideaRepo.CreateComment(ctx, comment); err != nil {
+		s.log.Error("failed to create comment", zap.Error(err))
+		return nil, err
+	}
+
+	created, err := s.ideaRepo.FindCommentByID(ctx, comment.ID)
+	if err != nil {
+		return nil, err
+	}
+	notified := s.notifyComment(ctx, idea, parent, created)
+	s.notifyMentions(ctx, idea, created.ID, created.UserID, rendered.Mentions, notified)
+
+	s.log.Info("comment added", zap.String("idea_id", idea.ID), zap.String("comment_id", created.ID))
+	return created, nil
+}
+
+// notifyComment tells the author of the comment replied to and the idea's
+// author about a new comment, once each and never the commenter. It
+// returns the users it considered notified.
+func (s *service) notifyComment(ctx context.Context, idea *domain.ReadingIdea, parent, comment *domain.IdeaComment) map[string]bool {
+	notified := map[string]bool{comment.UserID: true}
+	if parent != nil && !notified[parent.UserID] {
+		notified[parent.UserID] = true
+		if err := s.notificationSvc.NotifyIdeaComment(ctx, parent.UserID, idea.ID, comment.ID, comment.Username, idea.Title, true); err != nil {
+			s.log.Warn("failed to notify comment author of reply", zap.Error(err))
+		}
+	}
+	if !notified[idea.UserID] {
+		notified[idea.UserID] = true
+		if err := s.notificationSvc.NotifyIdeaComment(ctx, idea.UserID, idea.ID, comment.ID, comment.Username, idea.Title, false); err != nil {
+			s.log.Warn("failed to notify idea author of comment", zap.Error(err))
+		}
+	}
+	return notified
+}
+
+func (s *service) EditComment(ctx context.Context, commentID, userID, text string) (*domain.IdeaComment, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, domain.ErrInvalidInput
+	}
+
+	comment, err := s.ideaRepo.FindCommentByID(ctx, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.DeletedAt != nil {
+		return nil, domain.ErrNotFound
+	}
+	if comment.UserID != userID {
+		return nil, domain.ErrForbidden
+	}
+	now := time.Now()
+	if now.Sub(comment.CreatedAt) > commentEditWindow {
+		return nil, domain.ErrCommentWindowClosed
+	}
+
+	rendered, err := s.contentSvc.Render(ctx, text, content.MaxIdeaComment)
+	if err != nil {
+		return nil, err
+	}
+	previous := comment.Content
+	comment.Content = rendered.Source
+	comment.ContentHTML = rendered.HTML
+	comment.EditedAt = &now
+	if err := s.ideaRepo.UpdateComment(ctx, comment); err != nil {
+		s.log.Error("failed to edit comment", zap.String("comment_id", commentID), zap.Error(err))
+		return nil, err
+	}
+
+	if added := content.NewMentions(rendered.Mentions, previous); len(added) > 0 {
+		idea, err := s.ideaRepo.FindByID(ctx, comment.IdeaID)
+		if err != nil {
+			s.log.Warn("failed to load idea for mentions", zap.Error(err))
+		} else {
+			s.notifyMentions(ctx, idea, comment.ID, userID, added, nil)
+		}
+	}
+	return comment, nil
+}
+
+func (s *service) DeleteComment(ctx context.Context, commentID, userID string, isAdmin bool) error {
+	comment, err := s.ideaRepo.FindCommentByID(ctx, commentID)
+	if err != nil {
+		return err
+	}
+	if comment.DeletedAt != nil {
+		return domain.ErrNotFound
+	}
+	now := time.Now()
+	if !isAdmin {
+		if comment.UserID != userID {
+			return domain.ErrForbidden
+		}
+		if now.Sub(comment.CreatedAt) > commentDeleteWindow {
+			return domain.ErrCommentWindowClosed
+		}
+	}
+
+	if err := s.ideaRepo.SoftDeleteComment(ctx, commentID, userID, now); err != nil {
+		s.log.Error("failed to delete comment", zap.String("comment_id", commentID), zap.Error(err))
+		return err
+	}
+	s.log.Info("comment deleted", zap.String("comment_id", commentID), zap.Bool("by_admin", comment.UserID != userID))
+	return nil
+}
+
+// buildThread nests comments, given oldest first, under their parents.
+// Deleted comments lose their content and author and are dropped when no
+// visible replies hang off them.
+func buildThread(comments []*domain.IdeaComment) []*domain.IdeaComment {
+	byID := make(map[string]*domain.IdeaComment, len(comments))
+	for _, c := range comments {
+		byID[c.ID] = c
+	}
+
+	var roots []*domain.IdeaComment
+	for _, c := range comments {
+		if c.ParentID == nil {
+			roots = append(roots, c)
+			continue
+		}
+		if parent, ok := byID[*c.ParentID]; ok {
+			parent.Replies = append(parent.Replies, c)
+		}
+	}
+	return prune(roots)
+}
+
+func prune(comments []*domain.IdeaComment) []*domain.IdeaComment {
+	kept := comments[:0]
+	for _, c := range comments {
+		c.Replies = prune(c.Replies)
+		if c.DeletedAt != nil {
+			if len(c.Replies) == 0 {
+				continue
+			}
+			c.Content = ""
+			c.ContentHTML = ""
+			c.UserID = ""
+			c.Username = ""
+		}
+		kept = append(kept, c)
+	}
+	return kept
+}
@@ -0,0 +1,118 @@
+package idea
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/online-library/internal/content"
+	"github.com/yourusername/online-library/internal/domain"
+	"go.uber.org/zap"
+)
+
+// topFeedWindow is how far back the "top" feed looks
+const topFeedWindow = 7 * 24 * time.Hour
+
+func (s *service) Feed(ctx context.Context, sort domain.IdeaFeedSort, limit, offset int) ([]*domain.ReadingIdea, error) {
+	if !sort.Valid() {
+		return nil, domain.ErrInvalidInput
+	}
+	var since *time.Time
+	if sort == domain.IdeaFeedTop {
+		start := time.Now().Add(-topFeedWindow)
+		since = &start
+	}
+
+	ideas, err := s.ideaRepo.Feed(ctx, sort, since, limit, offset)
+	if err != nil {
+		s.log.Error("failed to load ideas feed", zap.String("sort", string(sort)), zap.Error(err))
+		return nil, err
+	}
+	return ideas, nil
+}
+
+func (s *service) Update(ctx context.Context, ideaID, userID, title, text string) (*domain.ReadingIdea, error) {
+	title, text = strings.TrimSpace(title), strings.TrimSpace(text)
+	if title == "" || text == "" {
+		return nil, domain.ErrInvalidInput
+	}
+
+	idea, err := s.ideaRepo.FindByID(ctx, ideaID)
+	if err != nil {
+		return nil, err
+	}
+	if idea.UserID != userID {
+		return nil, domain.ErrForbidden
+	}
+	rendered, err := s.contentSvc.Render(ctx, text, content.MaxIdeaContent)
+	if err != nil {
+		return nil, err
+	}
+	if idea.Title == title && idea.Content == rendered.Source {
+		return idea, nil
+	}
+
+	now := time.Now()
+	revision := &domain.IdeaRevision{
+		ID:          uuid.New().String(),
+		IdeaID:      idea.ID,
+		Title:       idea.Title,
+		Content:     idea.Content,
+		ContentHTML: idea.ContentHTML,
+		CreatedAt:   now,
+	}
+	idea.Title = title
+	idea.Content = rendered.Source
+	idea.ContentHTML = rendered.HTML
+	idea.UpdatedAt = now
+	idea.EditedAt = &now
+
+	if err := s.ideaRepo.Update(ctx, idea, revision, userID); err != nil {
+		s.log.Error("failed to update idea", zap.String("idea_id", ideaID), zap.Error(err))
+		return nil, err
+	}
+
+	s.notifyMentions(ctx, idea, "", userID, content.NewMentions(rendered.Mentions, revision.Content), nil)
+
+	s.log.Info("idea edited", zap.String("idea_id", ideaID))
+	return idea, nil
+}
+
+func (s *service) ListRevisions(ctx context.Context, ideaID string) ([]*domain.IdeaRevision, error) {
+	if _, err := s.ideaRepo.FindByID(ctx, ideaID); err != nil {
+		return nil, err
+	}
+	return s.ideaRepo.ListRevisions(ctx, ideaID)
+}
+
+// Delete removes an idea with its votes, comments and revisions. Points the
+// author earned from it are kept when they delete it themselves, and taken
+// back when an admin removes it.
+func (s *service) Delete(ctx context.Context, ideaID, userID string, isAdmin bool) error {
+	idea, err := s.ideaRepo.FindByID(ctx, ideaID)
+	if err != nil {
+		return err
+	}
+	if !isAdmin && idea.UserID != userID {
+		return domain.ErrForbidden
+	}
+
+	removedByAdmin := idea.UserID != userID
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.ideaRepo.Delete(ctx, ideaID); err != nil {
+			return err
+		}
+		if !removedByAdmin {
+			return nil
+		}
+		_, err := s.successScoreSvc.RevertReference(ctx, idea.UserID, "idea", idea.ID, "Idea removed by moderator")
+		return err
+	})
+	if err != nil {
+		s.log.Error("failed to delete idea", zap.String("idea_id", ideaID), zap.Error(err))
+		return err
+	}
+	s.log.Info("idea deleted", zap.String("idea_id", ideaID), zap.Bool("by_admin", removedByAdmin))
+	return nil
+}
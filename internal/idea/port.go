@@ -2,30 +2,114 @@ package idea
 
 import (
 	"context"
+	"time"
 
+	"github.com/yourusername/online-library/internal/content"
 	"github.com/yourusername/online-library/internal/domain"
 )
 
 type Service interface {
 	Create(ctx context.Context, idea *domain.ReadingIdea) (*domain.ReadingIdea, error)
 	GetByBook(ctx context.Context, bookID string) ([]*domain.ReadingIdea, error)
+	// Feed lists ideas across all books in the given order
+	Feed(ctx context.Context, sort domain.IdeaFeedSort, limit, offset int) ([]*domain.ReadingIdea, error)
+	// Update lets the author edit an idea; the replaced version is kept as
+	// a revision
+	Update(ctx context.Context, ideaID, userID, title, content string) (*domain.ReadingIdea, error)
+	ListRevisions(ctx context.Context, ideaID string) ([]*domain.IdeaRevision, error)
+	Delete(ctx context.Context, ideaID, userID string, isAdmin bool) error
+
+	// Vote toggles: voting the same way twice retracts the vote
 	Vote(ctx context.Context, ideaID, userID string, voteType domain.VoteType) error
+	// SetVote records the vote, switching an opposite one; repeating it
+	// changes nothing
+	SetVote(ctx context.Context, ideaID, userID string, voteType domain.VoteType) error
+	RetractVote(ctx context.Context, ideaID, userID string) error
+
+	// Comments. ListComments returns the idea's top-level comments with
+	// their replies nested under them.
+	ListComments(ctx context.Context, ideaID string) ([]*domain.IdeaComment, error)
+	AddComment(ctx context.Context, comment *domain.IdeaComment) (*domain.IdeaComment, error)
+	EditComment(ctx context.Context, commentID, userID, content string) (*domain.IdeaComment, error)
+	// DeleteComment soft-deletes a comment. Authors can delete within the
+	// delete window, admins at any time.
+	DeleteComment(ctx context.Context, commentID, userID string, isAdmin bool) error
+
+	// Vote ring moderation
+	DetectVoteRings(ctx context.Context, since time.Time) ([]*domain.VoteRing, error)
+	ListVoteRings(ctx context.Context, status domain.VoteRingStatus) ([]*domain.VoteRing, error)
+	ReviewVoteRing(ctx context.Context, ringID, adminID string, status domain.VoteRingStatus) error
 }
 
 type IdeaRepo interface {
 	Create(ctx context.Context, idea *domain.ReadingIdea) error
 	FindByBookID(ctx context.Context, bookID string) ([]*domain.ReadingIdea, error)
-	AddVote(ctx context.Context, vote *domain.IdeaVote) error
-	RemoveVote(ctx context.Context, ideaID, userID string) error
+	FindByID(ctx context.Context, id string) (*domain.ReadingIdea, error)
+	// Feed returns ideas created at or after since (all if nil) in the
+	// given order
+	Feed(ctx context.Context, sort domain.IdeaFeedSort, since *time.Time, limit, offset int) ([]*domain.ReadingIdea, error)
+	// Update saves the idea's new title and content and stores revision,
+	// the version it replaced, in one transaction
+	Update(ctx context.Context, idea *domain.ReadingIdea, revision *domain.IdeaRevision, editedBy string) error
+	ListRevisions(ctx context.Context, ideaID string) ([]*domain.IdeaRevision, error)
+	Delete(ctx context.Context, id string) error
+	// AddVote records, switches or toggles off a vote and returns the
+	// vote it replaced, or nil if the user had not voted before.
+	AddVote(ctx context.Context, vote *domain.IdeaVote) (*domain.IdeaVote, error)
+	// UpsertVote records the vote or switches an opposite one, leaving a
+	// vote of the same type alone. It returns the vote it replaced, or nil
+	// if there was none, and whether anything changed.
+	UpsertVote(ctx context.Context, vote *domain.IdeaVote) (*domain.IdeaVote, bool, error)
+	SetVoteScore(ctx context.Context, ideaID, userID string, points int) error
+	// RemoveVote deletes the vote and adjusts the idea's counters. It
+	// returns the removed vote, or nil if there was none.
+	RemoveVote(ctx context.Context, ideaID, userID string) (*domain.IdeaVote, error)
 	UpdateVoteCounts(ctx context.Context, ideaID string, upvotes, downvotes int) error
+
+	CreateComment(ctx context.Context, comment *domain.IdeaComment) error
+	FindCommentByID(ctx context.Context, id string) (*domain.IdeaComment, error)
+	// ListComments returns every comment on the idea, deleted ones
+	// included, oldest first
+	ListComments(ctx context.Context, ideaID string) ([]*domain.IdeaComment, error)
+	// UpdateComment saves the comment's content and edit time
+	UpdateComment(ctx context.Context, comment *domain.IdeaComment) error
+	SoftDeleteComment(ctx context.Context, id, deletedBy string, deletedAt time.Time) error
+	// HasReadBook reports whether the user has ever borrowed the book
+	HasReadBook(ctx context.Context, userID, bookID string) (bool, error)
+
+	ListUpvoteEdges(ctx context.Context, since time.Time, minVotes int) ([]*VoteEdge, error)
+	SaveVoteRing(ctx context.Context, ring *domain.VoteRing, ringKey string) error
+	ListVoteRings(ctx context.Context, status domain.VoteRingStatus) ([]*domain.VoteRing, error)
+	UpdateVoteRingStatus(ctx context.Context, ringID, adminID string, status domain.VoteRingStatus) error
+}
+
+// VoteEdge counts the upvotes one user gave to another user's ideas
+type VoteEdge struct {
+	VoterID  string
+	AuthorID string
+	Votes    int
 }
 
 type SuccessScoreSvc interface {
 	ProcessIdeaPosted(ctx context.Context, userID, ideaID string) error
-	ProcessIdeaUpvote(ctx context.Context, userID, ideaID string) error
-	ProcessIdeaDownvote(ctx context.Context, userID, ideaID string) error
+	ProcessIdeaUpvote(ctx context.Context, userID, ideaID string) (int, error)
+	ProcessIdeaDownvote(ctx context.Context, userID, ideaID string) (int, error)
+	RevertIdeaVote(ctx context.Context, userID, ideaID string, voteType domain.VoteType, points int) error
+	RevertReference(ctx context.Context, userID, refType, refID, reason string) (int, error)
 }
 
 type NotificationSvc interface {
-	NotifyIdeaVote(ctx context.Context, userID, voterName, ideaTitle string, isUpvote bool) error
+	NotifyIdeaVote(ctx context.Context, userID, ideaID, voterID, ideaTitle string, isUpvote bool) error
+	NotifyIdeaComment(ctx context.Context, userID, ideaID, commentID, commenterName, ideaTitle string, isReply bool) error
+	NotifyMention(ctx context.Context, userID, authorID string, source domain.MentionSource) error
+}
+
+type ContentSvc interface {
+	Render(ctx context.Context, source string, maxLen int) (*content.Rendered, error)
+}
+
+// UnitOfWork runs fn in one database transaction. Repository calls made
+// with the context passed to fn take part in it.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
 }
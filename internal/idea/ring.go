@@ -0,0 +1,146 @@
+package idea
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourusername/online-library/internal/domain"
+	"go.uber.org/zap"
+)
+
+// ringMinVotes is how many upvotes one user must give another's ideas
+// before the pair counts as linked in the vote graph.
+const ringMinVotes = 3
+
+// DetectVoteRings flags groups of users who, directly or through a cycle,
+// keep upvoting each other's ideas. Each group is a strongly connected
+// component of the voter -> author graph.
+func (s *service) DetectVoteRings(ctx context.Context, since time.Time) ([]*domain.VoteRing, error) {
+	edges, err := s.ideaRepo.ListUpvoteEdges(ctx, since, ringMinVotes)
+	if err != nil {
+		s.log.Error("failed to load vote graph", zap.Error(err))
+		return nil, err
+	}
+
+	graph := map[string][]string{}
+	for _, e := range edges {
+		graph[e.VoterID] = append(graph[e.VoterID], e.AuthorID)
+	}
+
+	rings := []*domain.VoteRing{}
+	for _, members := range stronglyConnected(graph) {
+		if len(members) < 2 {
+			continue
+		}
+		sort.Strings(members)
+
+		inRing := make(map[string]bool, len(members))
+		for _, m := range members {
+			inRing[m] = true
+		}
+		votes := 0
+		for _, e := range edges {
+			if inRing[e.VoterID] && inRing[e.AuthorID] {
+				votes += e.Votes
+			}
+		}
+
+		ring := &domain.VoteRing{
+			MemberIDs:  members,
+			VoteCount:  votes,
+			Status:     domain.VoteRingPending,
+			DetectedAt: time.Now(),
+		}
+		if err := s.ideaRepo.SaveVoteRing(ctx, ring, strings.Join(members, ",")); err != nil {
+			s.log.Error("failed to save vote ring", zap.Error(err))
+			return nil, err
+		}
+		rings = append(rings, ring)
+	}
+
+	s.log.Info("vote ring detection finished", zap.Int("rings", len(rings)))
+	return rings, nil
+}
+
+func (s *service) ListVoteRings(ctx context.Context, status domain.VoteRingStatus) ([]*domain.VoteRing, error) {
+	rings, err := s.ideaRepo.ListVoteRings(ctx, status)
+	if err != nil {
+		s.log.Error("failed to list vote rings", zap.Error(err))
+		return nil, err
+	}
+	return rings, nil
+}
+
+func (s *service) ReviewVoteRing(ctx context.Context, ringID, adminID string, status domain.VoteRingStatus) error {
+	if status != domain.VoteRingConfirmed && status != domain.VoteRingDismissed {
+		return domain.ErrInvalidInput
+	}
+	if err := s.ideaRepo.UpdateVoteRingStatus(ctx, ringID, adminID, status); err != nil {
+		return err
+	}
+	s.log.Info("vote ring reviewed",
+		zap.String("ring_id", ringID),
+		zap.String("status", string(status)),
+		zap.String("admin_id", adminID))
+	return nil
+}
+
+// stronglyConnected returns the strongly connected components of graph
+// using Tarjan's algorithm.
+func stronglyConnected(graph map[string][]string) [][]string {
+	var (
+		index    = map[string]int{}
+		lowlink  = map[string]int{}
+		onStack  = map[string]bool{}
+		stack    []string
+		next     int
+		comps    [][]string
+		visit    func(v string)
+		vertices = make([]string, 0, len(graph))
+	)
+
+	visit = func(v string) {
+		index[v] = next
+		lowlink[v] = next
+		next++
+		stack = append(stack, v)
+		onStack[v] = true
+
+		for _, w := range graph[v] {
+			if _, seen := index[w]; !seen {
+				visit(w)
+				lowlink[v] = min(lowlink[v], lowlink[w])
+			} else if onStack[w] {
+				lowlink[v] = min(lowlink[v], index[w])
+			}
+		}
+
+		if lowlink[v] == index[v] {
+			var comp []string
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				comp = append(comp, w)
+				if w == v {
+					break
+				}
+			}
+			comps = append(comps, comp)
+		}
+	}
+
+	// Visit in a stable order so repeated runs produce the same output
+	for v := range graph {
+		vertices = append(vertices, v)
+	}
+	sort.Strings(vertices)
+	for _, v := range vertices {
+		if _, seen := index[v]; !seen {
+			visit(v)
+		}
+	}
+	return comps
+}
@@ -2,9 +2,11 @@ package idea
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/yourusername/online-library/internal/content"
 	"github.com/yourusername/online-library/internal/domain"
 	"go.uber.org/zap"
 )
@@ -13,19 +15,33 @@ type service struct {
 	ideaRepo        IdeaRepo
 	successScoreSvc SuccessScoreSvc
 	notificationSvc NotificationSvc
-	log             *zap.Logger
+	contentSvc      ContentSvc
+	uow             UnitOfWork
+	// commentsReadersOnly limits commenting to members who have borrowed
+	// the idea's book
+	commentsReadersOnly bool
+	log                 *zap.Logger
 }
 
-func NewService(ideaRepo IdeaRepo, successScoreSvc SuccessScoreSvc, notificationSvc NotificationSvc, log *zap.Logger) Service {
+func NewService(ideaRepo IdeaRepo, successScoreSvc SuccessScoreSvc, notificationSvc NotificationSvc, contentSvc ContentSvc, uow UnitOfWork, commentsReadersOnly bool, log *zap.Logger) Service {
 	return &service{
-		ideaRepo:        ideaRepo,
-		successScoreSvc: successScoreSvc,
-		notificationSvc: notificationSvc,
-		log:             log,
+		ideaRepo:            ideaRepo,
+		successScoreSvc:     successScoreSvc,
+		notificationSvc:     notificationSvc,
+		contentSvc:          contentSvc,
+		uow:                 uow,
+		commentsReadersOnly: commentsReadersOnly,
+		log:                 log,
 	}
 }
 
 func (s *service) Create(ctx context.Context, idea *domain.ReadingIdea) (*domain.ReadingIdea, error) {
+	rendered, err := s.contentSvc.Render(ctx, strings.TrimSpace(idea.Content), content.MaxIdeaContent)
+	if err != nil {
+		return nil, err
+	}
+	idea.Content = rendered.Source
+	idea.ContentHTML = rendered.HTML
 	idea.ID = uuid.New().String()
 	idea.CreatedAt = time.Now()
 	idea.UpdatedAt = time.Now()
@@ -39,6 +55,7 @@ func (s *service) Create(ctx context.Context, idea *domain.ReadingIdea) (*domain
 	if err := s.successScoreSvc.ProcessIdeaPosted(ctx, idea.UserID, idea.ID); err != nil {
 		s.log.Warn("failed to update success score for idea", zap.Error(err))
 	}
+	s.notifyMentions(ctx, idea, "", idea.UserID, rendered.Mentions, nil)
 
 	s.log.Info("idea created successfully", zap.String("idea_id", idea.ID))
 	return idea, nil
@@ -54,6 +71,14 @@ func (s *service) GetByBook(ctx context.Context, bookID string) ([]*domain.Readi
 }
 
 func (s *service) Vote(ctx context.Context, ideaID, userID string, voteType domain.VoteType) error {
+	idea, err := s.ideaRepo.FindByID(ctx, ideaID)
+	if err != nil {
+		return err
+	}
+	if idea.UserID == userID {
+		return domain.ErrSelfVote
+	}
+
 	vote := &domain.IdeaVote{
 		ID:        uuid.New().String(),
 		IdeaID:    ideaID,
@@ -62,22 +87,154 @@ func (s *service) Vote(ctx context.Context, ideaID, userID string, voteType doma
 		CreatedAt: time.Now(),
 	}
 
-	if err := s.ideaRepo.AddVote(ctx, vote); err != nil {
+	// The vote and the points it moves commit together, so a concurrent
+	// change of the same vote always sees the points it has to take back
+	var retracted bool
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		previous, err := s.ideaRepo.AddVote(ctx, vote)
+		if err != nil {
+			return err
+		}
+
+		// Undo whatever the earlier vote did to the author's score
+		if previous != nil {
+			if err := s.revertVoteScore(ctx, idea, previous); err != nil {
+				return err
+			}
+		}
+
+		// Voting the same way twice retracts the vote
+		if previous != nil && previous.VoteType == voteType {
+			retracted = true
+			return nil
+		}
+		return s.scoreVote(ctx, idea, userID, voteType)
+	})
+	if err != nil {
 		s.log.Error("failed to add vote", zap.Error(err))
 		return err
 	}
 
-	// Update success score
+	if retracted {
+		s.log.Info("vote retracted", zap.String("idea_id", ideaID))
+		return nil
+	}
+	s.notifyVote(ctx, idea, userID, voteType)
+
+	s.log.Info("vote added successfully", zap.String("idea_id", ideaID))
+	return nil
+}
+
+// scoreVote awards the idea's author the points for a new vote and records
+// them on the vote so they can be taken back. Callers run it in the
+// transaction that wrote the vote.
+func (s *service) scoreVote(ctx context.Context, idea *domain.ReadingIdea, userID string, voteType domain.VoteType) error {
+	var points int
+	var err error
 	if voteType == domain.VoteTypeUp {
-		if err := s.successScoreSvc.ProcessIdeaUpvote(ctx, userID, ideaID); err != nil {
-			s.log.Warn("failed to update success score for upvote", zap.Error(err))
-		}
+		points, err = s.successScoreSvc.ProcessIdeaUpvote(ctx, idea.UserID, idea.ID)
 	} else {
-		if err := s.successScoreSvc.ProcessIdeaDownvote(ctx, userID, ideaID); err != nil {
-			s.log.Warn("failed to update success score for downvote", zap.Error(err))
+		points, err = s.successScoreSvc.ProcessIdeaDownvote(ctx, idea.UserID, idea.ID)
+	}
+	if err != nil {
+		return err
+	}
+	return s.ideaRepo.SetVoteScore(ctx, idea.ID, userID, points)
+}
+
+func (s *service) SetVote(ctx context.Context, ideaID, userID string, voteType domain.VoteType) error {
+	idea, err := s.ideaRepo.FindByID(ctx, ideaID)
+	if err != nil {
+		return err
+	}
+	if idea.UserID == userID {
+		return domain.ErrSelfVote
+	}
+
+	var changed bool
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		var previous *domain.IdeaVote
+		var err error
+		previous, changed, err = s.ideaRepo.UpsertVote(ctx, &domain.IdeaVote{
+			ID:        uuid.New().String(),
+			IdeaID:    ideaID,
+			UserID:    userID,
+			VoteType:  voteType,
+			CreatedAt: time.Now(),
+		})
+		if err != nil || !changed {
+			return err
+		}
+		if previous != nil {
+			if err := s.revertVoteScore(ctx, idea, previous); err != nil {
+				return err
+			}
 		}
+		return s.scoreVote(ctx, idea, userID, voteType)
+	})
+	if err != nil {
+		s.log.Error("failed to set vote", zap.Error(err))
+		return err
 	}
+	if !changed {
+		return nil
+	}
+	s.notifyVote(ctx, idea, userID, voteType)
 
-	s.log.Info("vote added successfully", zap.String("idea_id", ideaID))
+	s.log.Info("vote set", zap.String("idea_id", ideaID), zap.String("vote_type", string(voteType)))
+	return nil
+}
+
+func (s *service) RetractVote(ctx context.Context, ideaID, userID string) error {
+	idea, err := s.ideaRepo.FindByID(ctx, ideaID)
+	if err != nil {
+		return err
+	}
+	var removed *domain.IdeaVote
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		removed, err = s.ideaRepo.RemoveVote(ctx, ideaID, userID)
+		if err != nil || removed == nil {
+			return err
+		}
+		return s.revertVoteScore(ctx, idea, removed)
+	})
+	if err != nil {
+		s.log.Error("failed to remove vote", zap.Error(err))
+		return err
+	}
+	if removed == nil {
+		return nil
+	}
+
+	s.log.Info("vote retracted", zap.String("idea_id", ideaID))
 	return nil
 }
+
+// revertVoteScore undoes the points a withdrawn vote gave the idea's author
+func (s *service) revertVoteScore(ctx context.Context, idea *domain.ReadingIdea, vote *domain.IdeaVote) error {
+	return s.successScoreSvc.RevertIdeaVote(ctx, idea.UserID, idea.ID, vote.VoteType, vote.ScoreAwarded)
+}
+
+// notifyVote tells the idea's author about a new or changed vote
+func (s *service) notifyVote(ctx context.Context, idea *domain.ReadingIdea, voterID string, voteType domain.VoteType) {
+	isUpvote := voteType == domain.VoteTypeUp
+	if err := s.notificationSvc.NotifyIdeaVote(ctx, idea.UserID, idea.ID, voterID, idea.Title, isUpvote); err != nil {
+		s.log.Warn("failed to notify idea author of vote", zap.String("idea_id", idea.ID), zap.Error(err))
+	}
+}
+
+// notifyMentions tells the users mentioned in an idea, or in the comment
+// commentID on it, about the mention. The author and the user IDs in
+// notified, who already heard about it otherwise, are left out.
+func (s *service) notifyMentions(ctx context.Context, idea *domain.ReadingIdea, commentID, authorID string, mentions []content.Mention, notified map[string]bool) {
+	source := domain.MentionSource{Type: domain.MentionInIdea, ID: idea.ID, CommentID: commentID, Title: idea.Title}
+	for _, m := range mentions {
+		if m.UserID == authorID || notified[m.UserID] {
+			continue
+		}
+		if err := s.notificationSvc.NotifyMention(ctx, m.UserID, authorID, source); err != nil {
+			s.log.Warn("failed to notify mentioned user", zap.String("user_id", m.UserID), zap.Error(err))
+		}
+	}
+}
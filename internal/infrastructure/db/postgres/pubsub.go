@@ -0,0 +1,215 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+// ErrPubSubClosed is returned when subscribing after Close
+var ErrPubSubClosed = errors.New("pubsub closed")
+
+// subscriberBuffer is how many undelivered payloads a subscriber may queue
+// before further payloads are dropped.
+const subscriberBuffer = 16
+
+// PubSub fans Postgres LISTEN/NOTIFY out to in-process subscribers over a
+// single dedicated connection, so events published by any replica reach
+// streams held open on every replica. Publishers issue pg_notify on the
+// transaction that makes the change, so it is only heard once committed.
+//
+// Payloads are change hints: subscribers should re-read state from the
+// database after receiving one. An empty payload means the listener
+// reconnected and notifications may have been missed.
+type PubSub struct {
+	listener *pq.Listener
+	log      *zap.Logger
+
+	mu     sync.Mutex
+	subs   map[string]map[chan string]struct{}
+	closed bool
+
+	// listenMu serializes LISTEN and UNLISTEN, which are issued without mu
+	// held: they can wait on dispatch, which needs mu to drain Notify.
+	// listening holds the channels currently listened to.
+	listenMu  sync.Mutex
+	listening map[string]bool
+}
+
+// NewPubSub opens the listener connection and starts dispatching
+func NewPubSub(connectionString string, log *zap.Logger) *PubSub {
+	p := &PubSub{
+		log:       log,
+		subs:      make(map[string]map[chan string]struct{}),
+		listening: make(map[string]bool),
+	}
+	p.listener = pq.NewListener(connectionString, 10*time.Second, time.Minute, p.onEvent)
+	go p.dispatch()
+	return p
+}
+
+// Subscribe returns payloads published on channel until ctx is done, at
+// which point the returned channel is closed.
+func (p *PubSub) Subscribe(ctx context.Context, channel string) (<-chan string, error) {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil, ErrPubSubClosed
+	}
+	if p.subs[channel] == nil {
+		p.subs[channel] = make(map[chan string]struct{})
+	}
+	ch := make(chan string, subscriberBuffer)
+	p.subs[channel][ch] = struct{}{}
+	p.mu.Unlock()
+
+	if err := p.syncListen(channel); err != nil {
+		p.unsubscribe(channel, ch)
+		return nil, err
+	}
+
+	go func() {
+		<-ctx.Done()
+		p.unsubscribe(channel, ch)
+	}()
+
+	return ch, nil
+}
+
+func (p *PubSub) unsubscribe(channel string, ch chan string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	subs, ok := p.subs[channel]
+	if !ok {
+		return
+	}
+	if _, ok := subs[ch]; !ok {
+		return
+	}
+	delete(subs, ch)
+	close(ch)
+
+	if len(subs) == 0 {
+		delete(p.subs, channel)
+		go func() {
+			if err := p.syncListen(channel); err != nil {
+				p.log.Warn("failed to unlisten", zap.String("channel", channel), zap.Error(err))
+			}
+		}()
+	}
+}
+
+// syncListen listens to channel while it has subscribers and stops once
+// it has none, whichever order subscribes and unsubscribes raced in
+func (p *PubSub) syncListen(channel string) error {
+	p.listenMu.Lock()
+	defer p.listenMu.Unlock()
+
+	p.mu.Lock()
+	want := !p.closed && len(p.subs[channel]) > 0
+	p.mu.Unlock()
+
+	switch {
+	case want && !p.listening[channel]:
+		if err := p.listener.Listen(channel); err != nil && err != pq.ErrChannelAlreadyOpen {
+			return err
+		}
+		p.listening[channel] = true
+	case !want && p.listening[channel]:
+		delete(p.listening, channel)
+		if err := p.listener.Unlisten(channel); err != nil && err != pq.ErrChannelNotOpen {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close ends every subscription and closes the listener connection
+func (p *PubSub) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+
+	for channel, subs := range p.subs {
+		for ch := range subs {
+			close(ch)
+		}
+		delete(p.subs, channel)
+	}
+	p.mu.Unlock()
+
+	p.listenMu.Lock()
+	defer p.listenMu.Unlock()
+	p.listening = make(map[string]bool)
+	return p.listener.Close()
+}
+
+func (p *PubSub) dispatch() {
+	for {
+		select {
+		case n, ok := <-p.listener.Notify:
+			if !ok {
+				return
+			}
+			if n == nil {
+				// Reconnected: tell everyone to resync
+				p.broadcast()
+				continue
+			}
+			p.deliver(n.Channel, n.Extra)
+
+		case <-time.After(90 * time.Second):
+			go func() {
+				if err := p.listener.Ping(); err != nil {
+					p.log.Warn("pubsub listener ping failed", zap.Error(err))
+				}
+			}()
+		}
+	}
+}
+
+func (p *PubSub) deliver(channel, payload string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for ch := range p.subs[channel] {
+		select {
+		case ch <- payload:
+		default:
+			p.log.Warn("pubsub subscriber is behind, dropping payload", zap.String("channel", channel))
+		}
+	}
+}
+
+func (p *PubSub) broadcast() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, subs := range p.subs {
+		for ch := range subs {
+			select {
+			case ch <- "":
+			default:
+			}
+		}
+	}
+}
+
+func (p *PubSub) onEvent(event pq.ListenerEventType, err error) {
+	switch event {
+	case pq.ListenerEventDisconnected:
+		p.log.Warn("pubsub listener disconnected", zap.Error(err))
+	case pq.ListenerEventReconnected:
+		p.log.Info("pubsub listener reconnected")
+	case pq.ListenerEventConnectionAttemptFailed:
+		p.log.Warn("pubsub listener connection attempt failed", zap.Error(err))
+	}
+}
@@ -0,0 +1,63 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/yourusername/online-library/internal/domain"
+	"github.com/yourusername/online-library/internal/notification"
+)
+
+// FileSender appends each delivery as a JSON line to <dir>/<channel>.jsonl,
+// so local development can inspect what would have been sent
+type FileSender struct {
+	channel domain.NotificationChannel
+	path    string
+	mu      sync.Mutex
+}
+
+func NewFileSender(ch domain.NotificationChannel, dir string) (*FileSender, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create notification outbox dir: %w", err)
+	}
+	return &FileSender{channel: ch, path: filepath.Join(dir, string(ch)+".jsonl")}, nil
+}
+
+func (s *FileSender) Channel() domain.NotificationChannel {
+	return s.channel
+}
+
+func (s *FileSender) Send(ctx context.Context, to *notification.Recipient, d *domain.NotificationDelivery) error {
+	addresses := to.Addresses(s.channel)
+	if len(addresses) == 0 {
+		return notification.ErrNoAddress
+	}
+	line, err := json.Marshal(map[string]interface{}{
+		"delivery_id": d.ID,
+		"to":          addresses,
+		"type":        d.Type,
+		"title":       d.Title,
+		"message":     d.Message,
+		"link":        d.Link,
+		"sent_at":     time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
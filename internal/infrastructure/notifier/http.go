@@ -0,0 +1,81 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/yourusername/online-library/internal/config"
+	"github.com/yourusername/online-library/internal/domain"
+	"github.com/yourusername/online-library/internal/notification"
+)
+
+// HTTPSender posts SMS and push deliveries as JSON to a gateway, which
+// relays them to the SMS provider or FCM/APNs/Web Push:
+//
+//	{"to": ["+8801..."], "title": "...", "message": "...", "link": "https://..."}
+//
+// Any non-2xx response is treated as a failed attempt and retried.
+type HTTPSender struct {
+	channel   domain.NotificationChannel
+	gateway   config.GatewayConfig
+	publicURL string
+	client    *http.Client
+}
+
+func NewHTTPSender(ch domain.NotificationChannel, gateway config.GatewayConfig, publicURL string) (*HTTPSender, error) {
+	if gateway.URL == "" {
+		return nil, fmt.Errorf("%s http driver needs a gateway URL", ch)
+	}
+	return &HTTPSender{
+		channel:   ch,
+		gateway:   gateway,
+		publicURL: publicURL,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (s *HTTPSender) Channel() domain.NotificationChannel {
+	return s.channel
+}
+
+func (s *HTTPSender) Send(ctx context.Context, to *notification.Recipient, d *domain.NotificationDelivery) error {
+	addresses := to.Addresses(s.channel)
+	if len(addresses) == 0 {
+		return notification.ErrNoAddress
+	}
+	payload, err := json.Marshal(map[string]interface{}{
+		"to":      addresses,
+		"title":   d.Title,
+		"message": d.Message,
+		"link":    absoluteLink(s.publicURL, d.Link),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.gateway.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", d.ID)
+	if s.gateway.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.gateway.Token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s gateway returned %d: %s", s.channel, resp.StatusCode, bytes.TrimSpace(body))
+	}
+	return nil
+}
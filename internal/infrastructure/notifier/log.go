@@ -0,0 +1,38 @@
+package notifier
+
+import (
+	"context"
+
+	"github.com/yourusername/online-library/internal/domain"
+	"github.com/yourusername/online-library/internal/notification"
+	"go.uber.org/zap"
+)
+
+// LogSender writes deliveries to the server log instead of sending them
+type LogSender struct {
+	channel domain.NotificationChannel
+	log     *zap.Logger
+}
+
+func NewLogSender(ch domain.NotificationChannel, log *zap.Logger) *LogSender {
+	return &LogSender{channel: ch, log: log}
+}
+
+func (s *LogSender) Channel() domain.NotificationChannel {
+	return s.channel
+}
+
+func (s *LogSender) Send(ctx context.Context, to *notification.Recipient, d *domain.NotificationDelivery) error {
+	addresses := to.Addresses(s.channel)
+	if len(addresses) == 0 {
+		return notification.ErrNoAddress
+	}
+	s.log.Info("notification delivered (log driver)",
+		zap.String("channel", string(s.channel)),
+		zap.Strings("to", addresses),
+		zap.String("type", string(d.Type)),
+		zap.String("title", d.Title),
+		zap.String("message", d.Message),
+	)
+	return nil
+}
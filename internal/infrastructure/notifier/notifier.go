@@ -0,0 +1,54 @@
+// Package notifier holds the adapters that deliver notifications over
+// email, SMS and push, plus log and file stand-ins for development.
+package notifier
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yourusername/online-library/internal/config"
+	"github.com/yourusername/online-library/internal/domain"
+	"github.com/yourusername/online-library/internal/notification"
+	"go.uber.org/zap"
+)
+
+// NewSenders builds one sender per external channel from cfg
+func NewSenders(cfg config.NotifyConfig, log *zap.Logger) ([]notification.Sender, error) {
+	email, err := newSender(domain.ChannelEmail, cfg.EmailDriver, cfg, log)
+	if err != nil {
+		return nil, err
+	}
+	sms, err := newSender(domain.ChannelSMS, cfg.SMSDriver, cfg, log)
+	if err != nil {
+		return nil, err
+	}
+	push, err := newSender(domain.ChannelPush, cfg.PushDriver, cfg, log)
+	if err != nil {
+		return nil, err
+	}
+	return []notification.Sender{email, sms, push}, nil
+}
+
+func newSender(ch domain.NotificationChannel, driver string, cfg config.NotifyConfig, log *zap.Logger) (notification.Sender, error) {
+	switch {
+	case driver == "log":
+		return NewLogSender(ch, log), nil
+	case driver == "file":
+		return NewFileSender(ch, cfg.FileDir)
+	case driver == "smtp" && ch == domain.ChannelEmail:
+		return NewSMTPSender(cfg.SMTP, cfg.PublicURL), nil
+	case driver == "http" && ch == domain.ChannelSMS:
+		return NewHTTPSender(ch, cfg.SMSGateway, cfg.PublicURL)
+	case driver == "http" && ch == domain.ChannelPush:
+		return NewHTTPSender(ch, cfg.PushGateway, cfg.PublicURL)
+	}
+	return nil, fmt.Errorf("unsupported %s notification driver %q", ch, driver)
+}
+
+// absoluteLink turns an app-relative notification link into a full URL
+func absoluteLink(publicURL, link string) string {
+	if link == "" || strings.Contains(link, "://") {
+		return link
+	}
+	return strings.TrimRight(publicURL, "/") + link
+}
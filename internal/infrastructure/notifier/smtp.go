@@ -0,0 +1,63 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/yourusername/online-library/internal/config"
+	"github.com/yourusername/online-library/internal/domain"
+	"github.com/yourusername/online-library/internal/notification"
+)
+
+// SMTPSender emails notifications as plain text
+type SMTPSender struct {
+	cfg       config.SMTPConfig
+	publicURL string
+}
+
+func NewSMTPSender(cfg config.SMTPConfig, publicURL string) *SMTPSender {
+	return &SMTPSender{cfg: cfg, publicURL: publicURL}
+}
+
+func (s *SMTPSender) Channel() domain.NotificationChannel {
+	return domain.ChannelEmail
+}
+
+func (s *SMTPSender) Send(ctx context.Context, to *notification.Recipient, d *domain.NotificationDelivery) error {
+	if to.Email == "" {
+		return notification.ErrNoAddress
+	}
+	from, err := mail.ParseAddress(s.cfg.From)
+	if err != nil {
+		return fmt.Errorf("invalid SMTP_FROM: %w", err)
+	}
+
+	body := d.Message
+	if link := absoluteLink(s.publicURL, d.Link); link != "" {
+		body += "\r\n\r\n" + link
+	}
+	recipient := mail.Address{Name: to.Name, Address: to.Email}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", from.String())
+	fmt.Fprintf(&msg, "To: %s\r\n", recipient.String())
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", d.Title))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(body)
+	msg.WriteString("\r\n")
+
+	var auth smtp.Auth
+	if s.cfg.Username != "" {
+		auth = smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)
+	}
+	addr := s.cfg.Host + ":" + s.cfg.Port
+	return smtp.SendMail(addr, auth, from.Address, []string{to.Email}, []byte(msg.String()))
+}
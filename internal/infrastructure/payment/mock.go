@@ -0,0 +1,204 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/online-library/internal/config"
+	"github.com/yourusername/online-library/internal/donation"
+	"go.uber.org/zap"
+)
+
+// signatureTolerance is how old a signed webhook may be
+const signatureTolerance = 5 * time.Minute
+
+// MockProvider stands in for a real gateway during development. Checkout
+// returns a fake payment page and, shortly after, the mock posts a signed
+// webhook back to the API with the configured outcome:
+//
+//	success  payment.succeeded after about a second
+//	failure  payment.failed after about a second
+//	delayed  payment.succeeded after MockDelay, to exercise pending donations
+//
+// Webhooks are signed like most real gateways: the Mock-Signature header is
+// "t=<unix time>,v1=<hex HMAC-SHA256 of "<t>.<body>">" keyed with
+// WebhookSecret.
+type MockProvider struct {
+	cfg    config.PaymentConfig
+	client *http.Client
+	log    *zap.Logger
+}
+
+func NewMockProvider(cfg config.PaymentConfig, log *zap.Logger) (*MockProvider, error) {
+	switch cfg.MockOutcome {
+	case "success", "failure", "delayed":
+	default:
+		return nil, fmt.Errorf("unsupported mock payment outcome %q", cfg.MockOutcome)
+	}
+	if cfg.WebhookSecret == "" {
+		return nil, fmt.Errorf("mock payment provider needs a webhook secret")
+	}
+	return &MockProvider{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 10 * time.Second},
+		log:    log,
+	}, nil
+}
+
+func (p *MockProvider) Name() string {
+	return "mock"
+}
+
+func (p *MockProvider) SignatureHeader() string {
+	return "Mock-Signature"
+}
+
+func (p *MockProvider) CreateCheckout(ctx context.Context, req donation.CheckoutRequest) (*donation.CheckoutSession, error) {
+	id := "cs_mock_" + strings.ReplaceAll(uuid.New().String(), "-", "")
+	session := &donation.CheckoutSession{
+		ID:  id,
+		URL: strings.TrimRight(p.cfg.PublicURL, "/") + "/donations/checkout/" + id,
+	}
+
+	event := mockEvent{
+		SessionID: session.ID,
+		Amount:    req.Amount,
+		Currency:  req.Currency,
+	}
+	delay := time.Second
+	switch p.cfg.MockOutcome {
+	case "failure":
+		event.Type = string(donation.PaymentFailed)
+		event.Reason = "card declined (mock)"
+	case "delayed":
+		event.Type = string(donation.PaymentSucceeded)
+		delay = p.cfg.MockDelay
+	default:
+		event.Type = string(donation.PaymentSucceeded)
+	}
+	if event.Type == string(donation.PaymentSucceeded) {
+		event.PaymentRef = "pi_mock_" + strings.ReplaceAll(uuid.New().String(), "-", "")
+	}
+
+	// The request context ends with the checkout call; the callback
+	// arrives on its own like a real gateway's would
+	go p.deliver(event, delay)
+	return session, nil
+}
+
+func (p *MockProvider) ParseWebhook(payload []byte, signature string) (*donation.PaymentEvent, error) {
+	if !p.verify(payload, signature, time.Now()) {
+		return nil, donation.ErrInvalidSignature
+	}
+	var event mockEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("decode mock webhook: %w", err)
+	}
+	return &donation.PaymentEvent{
+		Type:       donation.PaymentEventType(event.Type),
+		SessionID:  event.SessionID,
+		PaymentRef: event.PaymentRef,
+		Amount:     event.Amount,
+		Currency:   event.Currency,
+		Reason:     event.Reason,
+	}, nil
+}
+
+func (p *MockProvider) Refund(ctx context.Context, paymentRef string, amount float64, currency string) (*donation.PaymentRefund, error) {
+	p.log.Info("mock refund issued",
+		zap.String("payment_ref", paymentRef),
+		zap.Float64("amount", amount),
+		zap.String("currency", currency))
+	return &donation.PaymentRefund{ID: "re_mock_" + strings.ReplaceAll(uuid.New().String(), "-", "")}, nil
+}
+
+// mockEvent is the webhook body the mock sends
+type mockEvent struct {
+	Type       string  `json:"type"`
+	SessionID  string  `json:"session_id"`
+	PaymentRef string  `json:"payment_ref,omitempty"`
+	Amount     float64 `json:"amount"`
+	Currency   string  `json:"currency"`
+	Reason     string  `json:"reason,omitempty"`
+}
+
+func (p *MockProvider) deliver(event mockEvent, delay time.Duration) {
+	time.Sleep(delay)
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		p.log.Error("failed to encode mock webhook", zap.Error(err))
+		return
+	}
+	req, err := http.NewRequest(http.MethodPost, p.cfg.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		p.log.Error("failed to build mock webhook", zap.Error(err))
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(p.SignatureHeader(), p.sign(body, time.Now()))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		p.log.Error("failed to deliver mock webhook", zap.String("session_id", event.SessionID), zap.Error(err))
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		p.log.Error("mock webhook rejected",
+			zap.String("session_id", event.SessionID),
+			zap.Int("status", resp.StatusCode),
+			zap.ByteString("body", bytes.TrimSpace(msg)))
+		return
+	}
+	p.log.Info("mock webhook delivered", zap.String("session_id", event.SessionID), zap.String("type", event.Type))
+}
+
+func (p *MockProvider) sign(payload []byte, at time.Time) string {
+	ts := strconv.FormatInt(at.Unix(), 10)
+	return "t=" + ts + ",v1=" + p.mac(ts, payload)
+}
+
+func (p *MockProvider) verify(payload []byte, signature string, now time.Time) bool {
+	var ts, sig string
+	for _, part := range strings.Split(signature, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch k {
+		case "t":
+			ts = v
+		case "v1":
+			sig = v
+		}
+	}
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || sig == "" {
+		return false
+	}
+	if age := now.Sub(time.Unix(unix, 0)); age > signatureTolerance || age < -signatureTolerance {
+		return false
+	}
+	return hmac.Equal([]byte(sig), []byte(p.mac(ts, payload)))
+}
+
+func (p *MockProvider) mac(ts string, payload []byte) string {
+	h := hmac.New(sha256.New, []byte(p.cfg.WebhookSecret))
+	h.Write([]byte(ts))
+	h.Write([]byte("."))
+	h.Write(payload)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,21 @@
+// Package payment holds the payment gateway adapters used for money
+// donations. Only the mock gateway ships today; a real one implements
+// donation.PaymentProvider the same way.
+package payment
+
+import (
+	"fmt"
+
+	"github.com/yourusername/online-library/internal/config"
+	"github.com/yourusername/online-library/internal/donation"
+	"go.uber.org/zap"
+)
+
+// NewProvider builds the gateway selected by cfg.Provider
+func NewProvider(cfg config.PaymentConfig, log *zap.Logger) (donation.PaymentProvider, error) {
+	switch cfg.Provider {
+	case "mock":
+		return NewMockProvider(cfg, log)
+	}
+	return nil, fmt.Errorf("unsupported payment provider %q", cfg.Provider)
+}
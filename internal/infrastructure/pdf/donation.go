@@ -0,0 +1,162 @@
+package pdf
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/yourusername/online-library/internal/domain"
+	"github.com/yourusername/online-library/internal/donation"
+	"github.com/yourusername/online-library/internal/i18n"
+)
+
+const (
+	margin       = 56.0
+	lineHeight   = 18.0
+	organisation = "Amar Pathagar"
+	// statementRows fit on a page below the statement header
+	statementRows = 30
+)
+
+// DonationRenderer lays out donation receipts and annual statements. Text
+// is in English since the built-in fonts can't show Bengali script.
+type DonationRenderer struct{}
+
+var _ donation.DocumentRenderer = (*DonationRenderer)(nil)
+
+func NewDonationRenderer() *DonationRenderer {
+	return &DonationRenderer{}
+}
+
+func (r *DonationRenderer) Receipt(rc *domain.DonationReceipt) ([]byte, error) {
+	doc := New("Donation receipt " + rc.Code)
+	y := header(doc, "Donation Receipt")
+
+	rows := [][2]string{
+		{"Receipt no.", rc.Code},
+		{"Issued", formatDate(rc.IssuedAt)},
+		{"Donor", rc.DonorName},
+		{"Date of donation", formatDate(rc.DonatedAt)},
+	}
+	if rc.DonationType == domain.DonationTypeMoney {
+		rows = append(rows, [2]string{"Donation", "Money"}, [2]string{"Amount", formatAmount(rc.Amount, rc.Currency)})
+	} else {
+		rows = append(rows, [2]string{"Donation", "Book"}, [2]string{"Title", rc.BookTitle})
+	}
+	if rc.RegeneratedAt != nil {
+		rows = append(rows, [2]string{"Reissued", formatDate(*rc.RegeneratedAt)})
+	}
+	for _, row := range rows {
+		doc.Text(margin, y, Bold, 11, row[0])
+		doc.Text(margin+140, y, Regular, 11, row[1])
+		y -= lineHeight
+	}
+
+	if rc.VoidedAt != nil {
+		y -= lineHeight
+		doc.Text(margin, y, Bold, 16, "VOID")
+		y -= lineHeight
+		doc.Text(margin, y, Regular, 10, "This donation was refunded on "+formatDate(*rc.VoidedAt)+".")
+		y -= lineHeight
+		doc.Text(margin, y, Regular, 10, truncate(rc.VoidReason, 90))
+		y -= lineHeight
+	}
+
+	y -= lineHeight
+	doc.Text(margin, y, Regular, 10, "Thank you for supporting "+organisation+". Please keep this receipt for your records.")
+	return doc.Bytes(), nil
+}
+
+func (r *DonationRenderer) Statement(st *domain.DonorStatement) ([]byte, error) {
+	doc := New(fmt.Sprintf("Donation statement %d", st.Year))
+	y := header(doc, fmt.Sprintf("Donation Statement %d", st.Year))
+
+	doc.Text(margin, y, Bold, 11, "Donor")
+	doc.Text(margin+140, y, Regular, 11, st.DonorName)
+	y -= lineHeight
+	doc.Text(margin, y, Bold, 11, "Generated")
+	doc.Text(margin+140, y, Regular, 11, formatDate(st.GeneratedAt))
+	y -= 2 * lineHeight
+
+	if len(st.Receipts) == 0 {
+		doc.Text(margin, y, Regular, 11, fmt.Sprintf("No donations were recorded in %d.", st.Year))
+		return doc.Bytes(), nil
+	}
+
+	y = statementColumns(doc, y)
+	for i, rc := range st.Receipts {
+		if i > 0 && i%statementRows == 0 {
+			doc.AddPage()
+			y = statementColumns(doc, PageHeight-margin)
+		}
+		detail := rc.BookTitle
+		if rc.DonationType == domain.DonationTypeMoney {
+			detail = formatAmount(rc.Amount, rc.Currency)
+		}
+		doc.Text(margin, y, Regular, 10, rc.Code)
+		doc.Text(margin+90, y, Regular, 10, formatDate(rc.DonatedAt))
+		doc.Text(margin+190, y, Regular, 10, string(rc.DonationType))
+		doc.Text(margin+250, y, Regular, 10, truncate(detail, 45))
+		y -= lineHeight
+	}
+
+	y -= lineHeight
+	doc.Line(margin, y+lineHeight-4, PageWidth-margin, y+lineHeight-4)
+	currencies := make([]string, 0, len(st.Totals))
+	for c := range st.Totals {
+		currencies = append(currencies, c)
+	}
+	sort.Strings(currencies)
+	for _, c := range currencies {
+		total := st.Totals[c]
+		doc.Text(margin, y, Bold, 11, "Total donated")
+		doc.Text(margin+140, y, Regular, 11, formatAmount(&total, c))
+		y -= lineHeight
+	}
+	if st.Books > 0 {
+		doc.Text(margin, y, Bold, 11, "Books donated")
+		doc.Text(margin+140, y, Regular, 11, i18n.FormatNumber(i18n.English, int64(st.Books)))
+	}
+	return doc.Bytes(), nil
+}
+
+// header draws the organisation and document title and returns the y
+// position below them
+func header(doc *Document, title string) float64 {
+	y := PageHeight - margin
+	doc.Text(margin, y, Bold, 18, organisation)
+	y -= 24
+	doc.Text(margin, y, Regular, 14, title)
+	y -= 10
+	doc.Line(margin, y, PageWidth-margin, y)
+	return y - 2*lineHeight
+}
+
+func statementColumns(doc *Document, y float64) float64 {
+	doc.Text(margin, y, Bold, 10, "Receipt")
+	doc.Text(margin+90, y, Bold, 10, "Date")
+	doc.Text(margin+190, y, Bold, 10, "Type")
+	doc.Text(margin+250, y, Bold, 10, "Amount / book")
+	return y - lineHeight
+}
+
+func formatDate(t time.Time) string {
+	return i18n.FormatDate(i18n.English, t)
+}
+
+func formatAmount(amount *float64, currency string) string {
+	if amount == nil {
+		return ""
+	}
+	cents := int64(math.Round(*amount * 100))
+	return fmt.Sprintf("%s %s.%02d", currency, i18n.FormatNumber(i18n.English, cents/100), cents%100)
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-3]) + "..."
+}
@@ -0,0 +1,41 @@
+// Package scheduler runs background jobs on a fixed interval for the
+// lifetime of the server.
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Every runs job once per interval until ctx is done. A failing or
+// panicking run is logged and the next one goes ahead as scheduled; jobs
+// must therefore be safe to repeat.
+func Every(ctx context.Context, name string, interval time.Duration, job func(context.Context) error, log *zap.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := run(ctx, job); err != nil && ctx.Err() == nil {
+				log.Error("scheduled job failed", zap.String("job", name), zap.Error(err))
+			}
+		}
+	}
+}
+
+// run calls job, turning a panic into an error so that one bad run can't
+// take the server down
+func run(ctx context.Context, job func(context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return job(ctx)
+}
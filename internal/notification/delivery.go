@@ -0,0 +1,235 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/online-library/internal/domain"
+	"github.com/yourusername/online-library/internal/i18n"
+	"go.uber.org/zap"
+)
+
+const (
+	deliveryBatchSize   = 50
+	deliveryLease       = 5 * time.Minute
+	maxDeliveryAttempts = 5
+)
+
+// externalChannels are delivered through the outbox
+var externalChannels = []domain.NotificationChannel{
+	domain.ChannelEmail,
+	domain.ChannelSMS,
+	domain.ChannelPush,
+}
+
+// typeSettings holds the delivery defaults of a notification type
+type typeSettings struct {
+	email  bool // emailed unless the user opts out
+	digest bool // low priority: batched into digests for users who opt in
+}
+
+// notificationTypes lists every type the service sends. Every type is shown
+// in-app by default; SMS and push are opt-in.
+var notificationTypes = map[domain.NotificationType]typeSettings{
+	domain.NotificationIdeaVote:              {digest: true},
+	domain.NotificationReviewReceived:        {digest: true},
+	domain.NotificationReviewDisputeResolved: {email: true},
+	domain.NotificationBookAvailable:         {email: true},
+	domain.NotificationRequestApproved:       {email: true},
+	domain.NotificationReturnDue:             {email: true},
+	domain.NotificationBookInTransit:         {email: true},
+	domain.NotificationBookDelivered:         {},
+	domain.NotificationHandoverThread:        {email: true},
+	domain.NotificationHandoverMessage:       {digest: true},
+	domain.NotificationHandoverCancelled:     {email: true},
+	domain.NotificationCampaignLaunched:      {digest: true},
+	domain.NotificationIdeaComment:           {digest: true},
+	domain.NotificationMention:               {digest: true},
+	domain.NotificationDonationReceived:      {digest: true},
+}
+
+// create renders a notification in the user's locale and stores it for the
+// channels the user has enabled: the in-app row is written and announced
+// immediately, the rest are queued in the outbox and held back during the
+// user's quiet hours. Low-priority types skip the outbox for users who
+// receive digests.
+func (s *service) create(ctx context.Context, userID string, notifType domain.NotificationType, messageKey string, params map[string]interface{}, payload domain.NotificationPayload, link string) error {
+	channels, err := s.enabledChannels(ctx, userID, notifType)
+	if err != nil {
+		return err
+	}
+	if !anyEnabled(channels) {
+		return nil
+	}
+	settings, err := s.settings(ctx, userID)
+	if err != nil {
+		return err
+	}
+	locale, err := s.notificationRepo.GetUserLocale(ctx, userID)
+	if err != nil {
+		return err
+	}
+	title := i18n.T(i18n.Parse(locale), "notification."+string(notifType)+".title", params)
+	message := i18n.T(i18n.Parse(locale), messageKey, params)
+
+	var notificationID *string
+	if channels[domain.ChannelInApp] {
+		n := &domain.Notification{
+			UserID:  userID,
+			Type:    notifType,
+			Version: domain.NotificationVersion,
+			Title:   title,
+			Message: message,
+			Link:    link,
+			Payload: payload,
+		}
+		if err := s.notificationRepo.Create(ctx, n); err != nil {
+			return err
+		}
+		if err := s.notificationRepo.Announce(ctx, UserChannel(userID), n.ID); err != nil {
+			s.log.Warn("failed to publish notification", zap.String("notification_id", n.ID), zap.Error(err))
+		}
+		notificationID = &n.ID
+	}
+
+	if notificationTypes[notifType].digest && settings.DigestFrequency != domain.DigestOff {
+		return s.notificationRepo.EnqueueDigestItem(ctx, &domain.DigestItem{
+			UserID:  userID,
+			Type:    notifType,
+			Title:   title,
+			Message: message,
+			Link:    link,
+		})
+	}
+
+	deliverAt := quietHoursEnd(settings, time.Now())
+	for _, ch := range externalChannels {
+		if !channels[ch] {
+			continue
+		}
+		if _, ok := s.senders[ch]; !ok {
+			continue
+		}
+		d := &domain.NotificationDelivery{
+			NotificationID: notificationID,
+			UserID:         userID,
+			Channel:        ch,
+			Type:           notifType,
+			Title:          title,
+			Message:        message,
+			Link:           link,
+			NextAttemptAt:  deliverAt,
+		}
+		if err := s.notificationRepo.EnqueueDelivery(ctx, d); err != nil {
+			s.log.Error("failed to queue notification delivery",
+				zap.String("user_id", userID),
+				zap.String("channel", string(ch)),
+				zap.Error(err),
+			)
+			return err
+		}
+	}
+	return nil
+}
+
+// enabledChannels overlays the user's stored preferences for notifType on
+// the defaults
+func (s *service) enabledChannels(ctx context.Context, userID string, notifType domain.NotificationType) (map[domain.NotificationChannel]bool, error) {
+	channels := map[domain.NotificationChannel]bool{
+		domain.ChannelInApp: true,
+		domain.ChannelEmail: notificationTypes[notifType].email,
+	}
+	prefs, err := s.notificationRepo.GetPreferences(ctx, userID)
+	if err != nil {
+		s.log.Error("failed to load notification preferences", zap.String("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+	for _, p := range prefs {
+		if p.Type == notifType {
+			channels[p.Channel] = p.Enabled
+		}
+	}
+	return channels, nil
+}
+
+func anyEnabled(channels map[domain.NotificationChannel]bool) bool {
+	for _, enabled := range channels {
+		if enabled {
+			return true
+		}
+	}
+	return false
+}
+
+// DeliverPending claims a batch of due outbox entries and hands each to its
+// channel's sender. Failures are retried with exponential backoff until
+// maxDeliveryAttempts; a recipient without an address fails immediately.
+func (s *service) DeliverPending(ctx context.Context) (int, error) {
+	deliveries, err := s.notificationRepo.ClaimDueDeliveries(ctx, deliveryBatchSize, deliveryLease)
+	if err != nil {
+		s.log.Error("failed to claim notification deliveries", zap.Error(err))
+		return 0, err
+	}
+
+	sent := 0
+	recipients := make(map[string]*Recipient)
+	for _, d := range deliveries {
+		err := s.deliver(ctx, d, recipients)
+		if err == nil {
+			if err := s.notificationRepo.MarkDeliverySent(ctx, d.ID); err != nil {
+				s.log.Error("failed to mark delivery sent", zap.String("delivery_id", d.ID), zap.Error(err))
+				continue
+			}
+			sent++
+			continue
+		}
+
+		var retryAt *time.Time
+		if !errors.Is(err, ErrNoAddress) && d.Attempts < maxDeliveryAttempts {
+			next := time.Now().UTC().Add(retryDelay(d.Attempts))
+			retryAt = &next
+		}
+		s.log.Warn("notification delivery failed",
+			zap.String("delivery_id", d.ID),
+			zap.String("channel", string(d.Channel)),
+			zap.Int("attempts", d.Attempts),
+			zap.Bool("will_retry", retryAt != nil),
+			zap.Error(err),
+		)
+		if err := s.notificationRepo.MarkDeliveryFailed(ctx, d.ID, err.Error(), retryAt); err != nil {
+			s.log.Error("failed to record delivery failure", zap.String("delivery_id", d.ID), zap.Error(err))
+		}
+	}
+	return sent, nil
+}
+
+func (s *service) deliver(ctx context.Context, d *domain.NotificationDelivery, recipients map[string]*Recipient) error {
+	sender, ok := s.senders[d.Channel]
+	if !ok {
+		return fmt.Errorf("no sender configured for channel %s", d.Channel)
+	}
+	to, ok := recipients[d.UserID]
+	if !ok {
+		var err error
+		to, err = s.notificationRepo.GetRecipient(ctx, d.UserID)
+		if err != nil {
+			return err
+		}
+		recipients[d.UserID] = to
+	}
+	return sender.Send(ctx, to, d)
+}
+
+func (s *service) ListDeliveries(ctx context.Context, status domain.OutboxStatus, limit int) ([]*domain.NotificationDelivery, error) {
+	return s.notificationRepo.ListDeliveries(ctx, status, limit)
+}
+
+// retryDelay grows fourfold per attempt: 1m, 4m, 16m, 64m
+func retryDelay(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	return time.Minute << (2 * (attempts - 1))
+}
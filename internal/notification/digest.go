@@ -0,0 +1,165 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/online-library/internal/domain"
+	"github.com/yourusername/online-library/internal/i18n"
+	"go.uber.org/zap"
+)
+
+const (
+	// digestHour is the local hour digests go out; weekly ones on
+	// weeklyDigestDay
+	digestHour      = 9
+	weeklyDigestDay = time.Friday
+	digestLink      = "/notifications"
+)
+
+// digestSectionOrder orders the sections of a digest; other types are
+// listed last under a common heading
+var digestSectionOrder = []domain.NotificationType{
+	domain.NotificationHandoverMessage,
+	domain.NotificationReviewReceived,
+	domain.NotificationIdeaVote,
+	domain.NotificationIdeaComment,
+	domain.NotificationMention,
+	domain.NotificationDonationReceived,
+}
+
+type digestSection struct {
+	Heading string
+	Items   []*domain.DigestItem
+}
+
+// SendDigests queues a digest email for every user whose digest period has
+// reached its send time and who has items waiting. A period is keyed by
+// local date (daily) or ISO week (weekly) and stored with a unique
+// constraint, so overlapping or repeated runs never send it twice. Users
+// who switched digests off get what was still waiting in a final daily
+// digest.
+func (s *service) SendDigests(ctx context.Context) (int, error) {
+	if _, ok := s.senders[domain.ChannelEmail]; !ok {
+		return 0, nil
+	}
+	users, err := s.notificationRepo.ListDigestSettings(ctx)
+	if err != nil {
+		s.log.Error("failed to list digest recipients", zap.Error(err))
+		return 0, err
+	}
+
+	queued := 0
+	now := time.Now()
+	for _, settings := range users {
+		frequency := settings.DigestFrequency
+		if frequency == domain.DigestOff {
+			frequency = domain.DigestDaily
+		}
+		periodKey, due := digestPeriod(frequency, settings.Timezone, now)
+		if !due {
+			continue
+		}
+		err := s.sendDigest(ctx, settings.UserID, frequency, periodKey)
+		if err == domain.ErrAlreadyExists {
+			continue
+		}
+		if err != nil {
+			s.log.Error("failed to send digest", zap.String("user_id", settings.UserID), zap.Error(err))
+			continue
+		}
+		queued++
+	}
+	return queued, nil
+}
+
+func (s *service) sendDigest(ctx context.Context, userID string, frequency domain.DigestFrequency, periodKey string) error {
+	items, err := s.notificationRepo.GetPendingDigestItems(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	to, err := s.notificationRepo.GetRecipient(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	locale := i18n.Parse(to.Locale)
+	body := renderDigest(locale, to.Name, frequency, items)
+	itemIDs := make([]string, len(items))
+	for i, item := range items {
+		itemIDs[i] = item.ID
+	}
+
+	digest := &domain.NotificationDigest{
+		UserID:    userID,
+		Frequency: frequency,
+		PeriodKey: periodKey,
+		ItemCount: len(items),
+	}
+	delivery := &domain.NotificationDelivery{
+		UserID:        userID,
+		Channel:       domain.ChannelEmail,
+		Type:          "digest",
+		Title:         i18n.T(locale, "digest.title."+string(frequency), map[string]interface{}{"count": len(items)}),
+		Message:       body,
+		Link:          digestLink,
+		NextAttemptAt: time.Now().UTC(),
+	}
+	return s.notificationRepo.SaveDigest(ctx, digest, itemIDs, delivery)
+}
+
+func renderDigest(locale i18n.Locale, name string, frequency domain.DigestFrequency, items []*domain.DigestItem) string {
+	byType := make(map[domain.NotificationType][]*domain.DigestItem)
+	for _, item := range items {
+		byType[item.Type] = append(byType[item.Type], item)
+	}
+
+	var sections []digestSection
+	for _, t := range digestSectionOrder {
+		if len(byType[t]) > 0 {
+			heading := i18n.T(locale, "digest.heading."+string(t), nil)
+			sections = append(sections, digestSection{Heading: heading, Items: byType[t]})
+			delete(byType, t)
+		}
+	}
+	// Types without a heading of their own
+	var other []*domain.DigestItem
+	for _, item := range items {
+		if _, ok := byType[item.Type]; ok {
+			other = append(other, item)
+		}
+	}
+	if len(other) > 0 {
+		sections = append(sections, digestSection{Heading: i18n.T(locale, "digest.heading.other", nil), Items: other})
+	}
+
+	return i18n.T(locale, "digest.body", map[string]interface{}{
+		"name":      name,
+		"frequency": string(frequency),
+		"sections":  sections,
+	})
+}
+
+// digestPeriod returns the key of the period now falls in for the user's
+// timezone and whether that period's send time has passed
+func digestPeriod(frequency domain.DigestFrequency, timezone string, now time.Time) (string, bool) {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	local := now.In(loc)
+
+	if frequency == domain.DigestWeekly {
+		year, week := local.ISOWeek()
+		// Days since Monday, the first day of an ISO week
+		sinceMonday := (int(local.Weekday()) + 6) % 7
+		sendDay := (int(weeklyDigestDay) + 6) % 7
+		due := sinceMonday > sendDay || (sinceMonday == sendDay && local.Hour() >= digestHour)
+		return fmt.Sprintf("%d-W%02d", year, week), due
+	}
+	return local.Format("2006-01-02"), local.Hour() >= digestHour
+}
@@ -0,0 +1,109 @@
+package notification
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/online-library/internal/domain"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxBulkIDs bounds one bulk request
+	maxBulkIDs = 200
+
+	// readRetention is how long read notifications are kept
+	readRetention  = 90 * 24 * time.Hour
+	pruneBatchSize = 1000
+)
+
+func (s *service) GetUserNotifications(ctx context.Context, userID string, archived bool, limit int) ([]*domain.Notification, error) {
+	return s.notificationRepo.GetByUserID(ctx, userID, archived, limit)
+}
+
+func (s *service) MarkAsRead(ctx context.Context, userID, notificationID string) error {
+	return s.single(ctx, userID, BulkMarkRead, notificationID)
+}
+
+func (s *service) MarkAllAsRead(ctx context.Context, userID string) error {
+	return s.notificationRepo.MarkAllAsRead(ctx, userID)
+}
+
+func (s *service) Archive(ctx context.Context, userID, notificationID string) error {
+	return s.single(ctx, userID, BulkArchive, notificationID)
+}
+
+func (s *service) Delete(ctx context.Context, userID, notificationID string) error {
+	return s.single(ctx, userID, BulkDelete, notificationID)
+}
+
+// single applies action to one notification, reporting a missing or
+// foreign one as not found
+func (s *service) single(ctx context.Context, userID string, action BulkAction, notificationID string) error {
+	n, err := s.Bulk(ctx, userID, action, []string{notificationID})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (s *service) Bulk(ctx context.Context, userID string, action BulkAction, ids []string) (int, error) {
+	if len(ids) == 0 || len(ids) > maxBulkIDs {
+		return 0, domain.ErrInvalidInput
+	}
+	for _, id := range ids {
+		if _, err := uuid.Parse(id); err != nil {
+			return 0, domain.ErrInvalidInput
+		}
+	}
+
+	var (
+		n   int
+		err error
+	)
+	switch action {
+	case BulkMarkRead, BulkMarkUnread:
+		n, err = s.notificationRepo.SetRead(ctx, userID, ids, action == BulkMarkRead)
+	case BulkArchive, BulkUnarchive:
+		n, err = s.notificationRepo.SetArchived(ctx, userID, ids, action == BulkArchive)
+	case BulkDelete:
+		n, err = s.notificationRepo.Delete(ctx, userID, ids)
+	default:
+		return 0, domain.ErrInvalidInput
+	}
+	if err != nil {
+		s.log.Error("failed to update notifications",
+			zap.String("user_id", userID),
+			zap.String("action", string(action)),
+			zap.Error(err),
+		)
+		return 0, err
+	}
+	return n, nil
+}
+
+// PruneRead deletes read notifications older than readRetention in batches
+// so a large backlog doesn't hold long locks
+func (s *service) PruneRead(ctx context.Context) (int, error) {
+	cutoff := time.Now().Add(-readRetention)
+	total := 0
+	for {
+		n, err := s.notificationRepo.DeleteReadBefore(ctx, cutoff, pruneBatchSize)
+		if err != nil {
+			s.log.Error("failed to prune notifications", zap.Error(err))
+			return total, err
+		}
+		total += n
+		if n < pruneBatchSize || ctx.Err() != nil {
+			break
+		}
+	}
+	if total > 0 {
+		s.log.Info("pruned read notifications", zap.Int("count", total))
+	}
+	return total, nil
+}
@@ -2,30 +2,192 @@ package notification
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/yourusername/online-library/internal/domain"
 )
 
 type Service interface {
-	NotifyIdeaVote(ctx context.Context, userID, voterName, ideaTitle string, isUpvote bool) error
-	NotifyReviewReceived(ctx context.Context, userID, reviewerName string) error
+	// NotifyIdeaVote tells an idea's author about a vote on it. The voter's
+	// name is looked up from voterID.
+	NotifyIdeaVote(ctx context.Context, userID, ideaID, voterID, ideaTitle string, isUpvote bool) error
+	NotifyReviewReceived(ctx context.Context, userID, reviewID, reviewerName string) error
+	NotifyReviewDisputeResolved(ctx context.Context, userID, reviewID string, outcome domain.DisputeStatus, isReviewer bool) error
 	NotifyBookAvailable(ctx context.Context, userID, bookID, bookTitle string) error
+	// NotifyBookAutoRequested tells a watcher their wishlisted book freed up
+	// and was requested for them
+	NotifyBookAutoRequested(ctx context.Context, userID, bookID, bookTitle string) error
 	NotifyRequestApproved(ctx context.Context, userID, bookID, bookTitle string) error
 	NotifyReturnDue(ctx context.Context, userID, bookID, bookTitle string, daysLeft int) error
-	GetUserNotifications(ctx context.Context, userID string, limit int) ([]*domain.Notification, error)
-	MarkAsRead(ctx context.Context, notificationID string) error
+
+	// Inbox management; every operation is scoped to the user's own
+	// notifications
+	GetUserNotifications(ctx context.Context, userID string, archived bool, limit int) ([]*domain.Notification, error)
+	MarkAsRead(ctx context.Context, userID, notificationID string) error
 	MarkAllAsRead(ctx context.Context, userID string) error
+	Archive(ctx context.Context, userID, notificationID string) error
+	Delete(ctx context.Context, userID, notificationID string) error
+	// Bulk applies action to the given notifications and returns how many
+	// were affected
+	Bulk(ctx context.Context, userID string, action BulkAction, ids []string) (int, error)
+	CountUnread(ctx context.Context, userID string) (int, error)
+
+	// PruneRead deletes read notifications older than the retention period
+	// and returns how many were removed
+	PruneRead(ctx context.Context) (int, error)
+
+	// Stream pushes notifications created after lastEventID (or only new
+	// ones if empty) until ctx is done
+	Stream(ctx context.Context, userID, lastEventID string) (<-chan *domain.Notification, error)
 
 	// Handover notifications
 	NotifyBookInTransit(ctx context.Context, userID, bookID, bookTitle string) error
 	NotifyBookDelivered(ctx context.Context, userID, bookID, bookTitle string) error
-	NotifyHandoverThreadCreated(ctx context.Context, currentHolderID, nextHolderID, bookID, bookTitle string) error
-	NotifyHandoverMessage(ctx context.Context, userID, bookID, bookTitle string) error
+	NotifyHandoverThreadCreated(ctx context.Context, threadID, currentHolderID, nextHolderID, bookID, bookTitle string) error
+	NotifyHandoverMessage(ctx context.Context, userID, threadID, bookID, bookTitle string) error
+	NotifyHandoverCancelled(ctx context.Context, userID, threadID, bookID, bookTitle string, reason domain.HandoverCancelReason) error
+
+	// NotifyCampaignLaunched tells a user about a new donation campaign
+	// matching their interests
+	NotifyCampaignLaunched(ctx context.Context, userID, campaignID, campaignTitle string) error
+	// NotifyDonationReceived tells a campaign's creator about a confirmed
+	// donation to it
+	NotifyDonationReceived(ctx context.Context, userID, campaignID, campaignTitle string) error
+
+	// NotifyIdeaComment tells an idea's author about a new comment, or a
+	// commenter about a reply to their comment
+	NotifyIdeaComment(ctx context.Context, userID, ideaID, commentID, commenterName, ideaTitle string, isReply bool) error
+	// NotifyMention tells a user that authorID mentioned them in the text
+	// source locates
+	NotifyMention(ctx context.Context, userID, authorID string, source domain.MentionSource) error
+
+	// Channel preferences
+	GetPreferences(ctx context.Context, userID string) (*Preferences, error)
+	UpdatePreferences(ctx context.Context, userID string, prefs []*domain.NotificationPreference) error
+	UpdateSettings(ctx context.Context, settings *domain.NotificationSettings) error
+	RegisterPushToken(ctx context.Context, userID, token, platform string) error
+	UnregisterPushToken(ctx context.Context, userID, token string) error
+
+	// DeliverPending sends due outbox entries and returns how many were sent
+	DeliverPending(ctx context.Context) (int, error)
+	ListDeliveries(ctx context.Context, status domain.OutboxStatus, limit int) ([]*domain.NotificationDelivery, error)
+
+	// SendDigests queues the digests that are due and returns how many
+	SendDigests(ctx context.Context) (int, error)
 }
 
 type NotificationRepo interface {
-	Create(ctx context.Context, userID, notifType, title, message, link string) error
-	GetByUserID(ctx context.Context, userID string, limit int) ([]*domain.Notification, error)
-	MarkAsRead(ctx context.Context, notificationID string) error
+	Create(ctx context.Context, n *domain.Notification) error
+	// Announce publishes payload on channel once the transaction in ctx
+	// commits, or straight away outside of one
+	Announce(ctx context.Context, channel, payload string) error
+	GetByUserID(ctx context.Context, userID string, archived bool, limit int) ([]*domain.Notification, error)
+	GetAfter(ctx context.Context, userID, afterID string, limit int) ([]*domain.Notification, error)
+	CountUnread(ctx context.Context, userID string) (int, error)
 	MarkAllAsRead(ctx context.Context, userID string) error
+	// SetRead, SetArchived and Delete only touch the user's own
+	// notifications and return how many rows changed
+	SetRead(ctx context.Context, userID string, ids []string, read bool) (int, error)
+	SetArchived(ctx context.Context, userID string, ids []string, archived bool) (int, error)
+	Delete(ctx context.Context, userID string, ids []string) (int, error)
+	// DeleteReadBefore removes up to limit read notifications created
+	// before cutoff
+	DeleteReadBefore(ctx context.Context, cutoff time.Time, limit int) (int, error)
+
+	// Preferences and delivery addresses
+	GetPreferences(ctx context.Context, userID string) ([]*domain.NotificationPreference, error)
+	SavePreferences(ctx context.Context, userID string, prefs []*domain.NotificationPreference) error
+	GetSettings(ctx context.Context, userID string) (*domain.NotificationSettings, error)
+	SaveSettings(ctx context.Context, settings *domain.NotificationSettings) error
+	AddPushToken(ctx context.Context, userID, token, platform string) error
+	RemovePushToken(ctx context.Context, userID, token string) error
+	GetRecipient(ctx context.Context, userID string) (*Recipient, error)
+	GetUserLocale(ctx context.Context, userID string) (string, error)
+	GetUsername(ctx context.Context, userID string) (string, error)
+
+	// Outbox
+	EnqueueDelivery(ctx context.Context, d *domain.NotificationDelivery) error
+	// ClaimDueDeliveries leases up to limit due entries by pushing their
+	// next attempt lease into the future and counting the attempt, so
+	// concurrent workers never pick up the same entry
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*domain.NotificationDelivery, error)
+	MarkDeliverySent(ctx context.Context, id string) error
+	// MarkDeliveryFailed records the error and schedules a retry at retryAt,
+	// or gives up when retryAt is nil
+	MarkDeliveryFailed(ctx context.Context, id, lastError string, retryAt *time.Time) error
+	ListDeliveries(ctx context.Context, status domain.OutboxStatus, limit int) ([]*domain.NotificationDelivery, error)
+
+	// Digests
+	EnqueueDigestItem(ctx context.Context, item *domain.DigestItem) error
+	// ListDigestSettings returns the settings of every user with items
+	// waiting for a digest
+	ListDigestSettings(ctx context.Context) ([]*domain.NotificationSettings, error)
+	GetPendingDigestItems(ctx context.Context, userID string) ([]*domain.DigestItem, error)
+	// SaveDigest records the digest, attaches the items to it and queues
+	// its delivery in one transaction. It returns domain.ErrAlreadyExists
+	// if the period was already sent or an item was claimed concurrently.
+	SaveDigest(ctx context.Context, digest *domain.NotificationDigest, itemIDs []string, d *domain.NotificationDelivery) error
+}
+
+// BulkAction is an operation on a set of notifications
+type BulkAction string
+
+const (
+	BulkMarkRead   BulkAction = "read"
+	BulkMarkUnread BulkAction = "unread"
+	BulkArchive    BulkAction = "archive"
+	BulkUnarchive  BulkAction = "unarchive"
+	BulkDelete     BulkAction = "delete"
+)
+
+// Preferences is a user's effective per-type, per-channel settings
+type Preferences struct {
+	Channels []*domain.NotificationPreference `json:"channels"`
+	Settings *domain.NotificationSettings     `json:"settings"`
+}
+
+// Sender delivers outbox entries over one external channel. Adapters live
+// in internal/infrastructure/notifier.
+type Sender interface {
+	Channel() domain.NotificationChannel
+	Send(ctx context.Context, to *Recipient, d *domain.NotificationDelivery) error
+}
+
+// Recipient holds the addresses a user can be reached at
+type Recipient struct {
+	UserID     string
+	Name       string
+	Locale     string
+	Email      string
+	SMSNumber  string
+	PushTokens []string
+}
+
+// Addresses returns where the recipient can be reached over ch
+func (r *Recipient) Addresses(ch domain.NotificationChannel) []string {
+	switch ch {
+	case domain.ChannelEmail:
+		if r.Email != "" {
+			return []string{r.Email}
+		}
+	case domain.ChannelSMS:
+		if r.SMSNumber != "" {
+			return []string{r.SMSNumber}
+		}
+	case domain.ChannelPush:
+		return r.PushTokens
+	}
+	return nil
+}
+
+// ErrNoAddress is returned by a Sender when the recipient cannot be reached
+// over its channel. Such deliveries fail without retrying.
+var ErrNoAddress = errors.New("recipient has no address for this channel")
+
+// PubSub delivers the announcements made by NotificationRepo.Announce to
+// subscribers on every instance. The Postgres adapter implements it with
+// LISTEN/NOTIFY.
+type PubSub interface {
+	Subscribe(ctx context.Context, channel string) (<-chan string, error)
 }
@@ -0,0 +1,183 @@
+package notification
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/yourusername/online-library/internal/domain"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultTimezone = "Asia/Dhaka"
+	clockLayout     = "15:04"
+)
+
+var smsNumberPattern = regexp.MustCompile(`^\+?[0-9]{6,15}$`)
+
+var pushPlatforms = map[string]bool{"web": true, "android": true, "ios": true}
+
+func (s *service) GetPreferences(ctx context.Context, userID string) (*Preferences, error) {
+	stored, err := s.notificationRepo.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	overrides := make(map[domain.NotificationType]map[domain.NotificationChannel]bool)
+	for _, p := range stored {
+		if overrides[p.Type] == nil {
+			overrides[p.Type] = make(map[domain.NotificationChannel]bool)
+		}
+		overrides[p.Type][p.Channel] = p.Enabled
+	}
+
+	types := make([]domain.NotificationType, 0, len(notificationTypes))
+	for t := range notificationTypes {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+
+	channels := append([]domain.NotificationChannel{domain.ChannelInApp}, externalChannels...)
+	prefs := make([]*domain.NotificationPreference, 0, len(types)*len(channels))
+	for _, t := range types {
+		for _, ch := range channels {
+			enabled := ch == domain.ChannelInApp || (ch == domain.ChannelEmail && notificationTypes[t].email)
+			if v, ok := overrides[t][ch]; ok {
+				enabled = v
+			}
+			prefs = append(prefs, &domain.NotificationPreference{Type: t, Channel: ch, Enabled: enabled})
+		}
+	}
+
+	settings, err := s.settings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &Preferences{Channels: prefs, Settings: settings}, nil
+}
+
+func (s *service) UpdatePreferences(ctx context.Context, userID string, prefs []*domain.NotificationPreference) error {
+	for _, p := range prefs {
+		// Only types that are still sent can be configured
+		if _, ok := notificationTypes[p.Type]; !ok {
+			return domain.ErrInvalidInput
+		}
+		switch p.Channel {
+		case domain.ChannelInApp, domain.ChannelEmail, domain.ChannelSMS, domain.ChannelPush:
+		default:
+			return domain.ErrInvalidInput
+		}
+	}
+	if err := s.notificationRepo.SavePreferences(ctx, userID, prefs); err != nil {
+		s.log.Error("failed to save notification preferences", zap.String("user_id", userID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (s *service) UpdateSettings(ctx context.Context, settings *domain.NotificationSettings) error {
+	if settings.SMSNumber != "" && !smsNumberPattern.MatchString(settings.SMSNumber) {
+		return domain.ErrInvalidInput
+	}
+	if (settings.QuietHoursStart == "") != (settings.QuietHoursEnd == "") {
+		return domain.ErrInvalidInput
+	}
+	if settings.QuietHoursStart != "" {
+		if _, err := time.Parse(clockLayout, settings.QuietHoursStart); err != nil {
+			return domain.ErrInvalidInput
+		}
+		if _, err := time.Parse(clockLayout, settings.QuietHoursEnd); err != nil {
+			return domain.ErrInvalidInput
+		}
+	}
+	switch settings.DigestFrequency {
+	case "":
+		settings.DigestFrequency = domain.DigestOff
+	case domain.DigestOff, domain.DigestDaily, domain.DigestWeekly:
+	default:
+		return domain.ErrInvalidInput
+	}
+	if settings.Timezone == "" {
+		settings.Timezone = defaultTimezone
+	}
+	if _, err := time.LoadLocation(settings.Timezone); err != nil {
+		return domain.ErrInvalidInput
+	}
+
+	if err := s.notificationRepo.SaveSettings(ctx, settings); err != nil {
+		s.log.Error("failed to save notification settings", zap.String("user_id", settings.UserID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (s *service) RegisterPushToken(ctx context.Context, userID, token, platform string) error {
+	if token == "" || !pushPlatforms[platform] {
+		return domain.ErrInvalidInput
+	}
+	return s.notificationRepo.AddPushToken(ctx, userID, token, platform)
+}
+
+func (s *service) UnregisterPushToken(ctx context.Context, userID, token string) error {
+	return s.notificationRepo.RemovePushToken(ctx, userID, token)
+}
+
+// settings returns the user's stored settings, or the defaults if none
+func (s *service) settings(ctx context.Context, userID string) (*domain.NotificationSettings, error) {
+	settings, err := s.notificationRepo.GetSettings(ctx, userID)
+	if err == domain.ErrNotFound {
+		return &domain.NotificationSettings{UserID: userID, Timezone: defaultTimezone, DigestFrequency: domain.DigestOff}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// quietHoursEnd returns now if it falls outside the user's quiet hours,
+// otherwise the moment they end. The result is in UTC like every outbox
+// time: they are stored without a zone, so one in the user's zone would be
+// read back as UTC and fire off by the difference.
+func quietHoursEnd(settings *domain.NotificationSettings, now time.Time) time.Time {
+	now = now.UTC()
+	if settings.QuietHoursStart == "" {
+		return now
+	}
+	start, err := time.Parse(clockLayout, settings.QuietHoursStart)
+	if err != nil {
+		return now
+	}
+	end, err := time.Parse(clockLayout, settings.QuietHoursEnd)
+	if err != nil {
+		return now
+	}
+	loc, err := time.LoadLocation(settings.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	startMin := start.Hour()*60 + start.Minute()
+	endMin := end.Hour()*60 + end.Minute()
+
+	var quiet bool
+	switch {
+	case startMin == endMin:
+		quiet = false
+	case startMin < endMin:
+		quiet = minute >= startMin && minute < endMin
+	default: // wraps past midnight, e.g. 22:00-07:00
+		quiet = minute >= startMin || minute < endMin
+	}
+	if !quiet {
+		return now
+	}
+
+	resume := time.Date(local.Year(), local.Month(), local.Day(), end.Hour(), end.Minute(), 0, 0, loc)
+	if !resume.After(local) {
+		resume = time.Date(local.Year(), local.Month(), local.Day()+1, end.Hour(), end.Minute(), 0, 0, loc)
+	}
+	return resume.UTC()
+}
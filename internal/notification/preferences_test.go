@@ -0,0 +1,43 @@
+package notification
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yourusername/online-library/internal/domain"
+)
+
+func TestQuietHoursEndIsUTC(t *testing.T) {
+	now := time.Date(2026, 3, 10, 23, 30, 0, 0, time.FixedZone("UTC+6", 6*60*60))
+
+	cases := map[string]struct {
+		settings *domain.NotificationSettings
+		want     time.Time
+	}{
+		"no quiet hours": {
+			settings: &domain.NotificationSettings{Timezone: "UTC"},
+			want:     now,
+		},
+		"unparsable quiet hours": {
+			settings: &domain.NotificationSettings{QuietHoursStart: "late", QuietHoursEnd: "07:00", Timezone: "UTC"},
+			want:     now,
+		},
+		"outside quiet hours": {
+			settings: &domain.NotificationSettings{QuietHoursStart: "22:00", QuietHoursEnd: "07:00", Timezone: "UTC"},
+			want:     now,
+		},
+		"inside quiet hours": {
+			settings: &domain.NotificationSettings{QuietHoursStart: "22:00", QuietHoursEnd: "07:00", Timezone: "Asia/Dhaka"},
+			want:     time.Date(2026, 3, 11, 1, 0, 0, 0, time.UTC),
+		},
+	}
+	for name, tc := range cases {
+		got := quietHoursEnd(tc.settings, now)
+		if got.Location() != time.UTC {
+			t.Errorf("%s: got location %s, want UTC", name, got.Location())
+		}
+		if !got.Equal(tc.want) {
+			t.Errorf("%s: got %s, want %s", name, got, tc.want)
+		}
+	}
+}
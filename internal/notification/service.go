@@ -10,141 +10,269 @@ import (
 
 type service struct {
 	notificationRepo NotificationRepo
+	pubsub           PubSub
+	senders          map[domain.NotificationChannel]Sender
 	log              *zap.Logger
 }
 
-func NewService(notificationRepo NotificationRepo, log *zap.Logger) Service {
+// NewService wires the in-app store and one Sender per external channel.
+// Channels without a sender are never queued.
+func NewService(notificationRepo NotificationRepo, pubsub PubSub, senders []Sender, log *zap.Logger) Service {
+	byChannel := make(map[domain.NotificationChannel]Sender, len(senders))
+	for _, sender := range senders {
+		byChannel[sender.Channel()] = sender
+	}
 	return &service{
 		notificationRepo: notificationRepo,
+		pubsub:           pubsub,
+		senders:          byChannel,
 		log:              log,
 	}
 }
 
-func (s *service) NotifyIdeaVote(ctx context.Context, userID, voterName, ideaTitle string, isUpvote bool) error {
-	voteType := "upvoted"
-	if !isUpvote {
-		voteType = "downvoted"
+func (s *service) NotifyIdeaVote(ctx context.Context, userID, ideaID, voterID, ideaTitle string, isUpvote bool) error {
+	voterName, err := s.notificationRepo.GetUsername(ctx, voterID)
+	if err != nil {
+		return err
 	}
-	return s.notificationRepo.Create(
+	return s.create(
 		ctx,
 		userID,
-		"idea_vote",
-		"Idea Vote",
-		fmt.Sprintf("%s %s your idea '%s'", voterName, voteType, ideaTitle),
+		domain.NotificationIdeaVote,
+		"notification.idea_vote.message",
+		map[string]interface{}{"voter": voterName, "idea": ideaTitle, "upvote": isUpvote},
+		domain.NotificationPayload{IdeaID: ideaID, Upvote: &isUpvote},
 		"/ideas",
 	)
 }
 
-func (s *service) NotifyReviewReceived(ctx context.Context, userID, reviewerName string) error {
-	return s.notificationRepo.Create(
+func (s *service) NotifyReviewReceived(ctx context.Context, userID, reviewID, reviewerName string) error {
+	return s.create(
 		ctx,
 		userID,
-		"review_received",
-		"New Review",
-		fmt.Sprintf("%s left you a review!", reviewerName),
+		domain.NotificationReviewReceived,
+		"notification.review_received.message",
+		map[string]interface{}{"reviewer": reviewerName},
+		domain.NotificationPayload{ReviewID: reviewID},
 		"/profile/reviews",
 	)
 }
 
-func (s *service) NotifyBookAvailable(ctx context.Context, userID, bookID, bookTitle string) error {
-	return s.notificationRepo.Create(
+func (s *service) NotifyReviewDisputeResolved(ctx context.Context, userID, reviewID string, outcome domain.DisputeStatus, isReviewer bool) error {
+	party := "disputer"
+	if isReviewer {
+		party = "reviewer"
+	}
+	return s.create(
 		ctx,
 		userID,
-		"book_available",
-		"Book Available",
-		fmt.Sprintf("The book '%s' is now available for you!", bookTitle),
-		fmt.Sprintf("/books/%s", bookID),
+		domain.NotificationReviewDisputeResolved,
+		fmt.Sprintf("notification.review_dispute_resolved.%s.%s", outcome, party),
+		nil,
+		domain.NotificationPayload{ReviewID: reviewID, Outcome: string(outcome)},
+		"/profile/reviews",
 	)
 }
 
-func (s *service) NotifyRequestApproved(ctx context.Context, userID, bookID, bookTitle string) error {
-	return s.notificationRepo.Create(
+func (s *service) NotifyBookAvailable(ctx context.Context, userID, bookID, bookTitle string) error {
+	return s.create(
 		ctx,
 		userID,
-		"request_approved",
-		"Request Approved",
-		fmt.Sprintf("Your request for '%s' has been approved!", bookTitle),
+		domain.NotificationBookAvailable,
+		"notification.book_available.message",
+		map[string]interface{}{"book": bookTitle},
+		domain.NotificationPayload{BookID: bookID},
 		fmt.Sprintf("/books/%s", bookID),
 	)
 }
 
-func (s *service) NotifyReturnDue(ctx context.Context, userID, bookID, bookTitle string, daysLeft int) error {
-	return s.notificationRepo.Create(
+func (s *service) NotifyBookAutoRequested(ctx context.Context, userID, bookID, bookTitle string) error {
+	return s.create(
 		ctx,
 		userID,
-		"return_due",
-		"Book Return Reminder",
-		fmt.Sprintf("Please return '%s' in %d days.", bookTitle, daysLeft),
+		domain.NotificationBookAvailable,
+		"notification.book_available.auto_requested",
+		map[string]interface{}{"book": bookTitle},
+		domain.NotificationPayload{BookID: bookID},
 		fmt.Sprintf("/books/%s", bookID),
 	)
 }
 
-func (s *service) GetUserNotifications(ctx context.Context, userID string, limit int) ([]*domain.Notification, error) {
-	return s.notificationRepo.GetByUserID(ctx, userID, limit)
-}
-
-func (s *service) MarkAsRead(ctx context.Context, notificationID string) error {
-	return s.notificationRepo.MarkAsRead(ctx, notificationID)
+func (s *service) NotifyRequestApproved(ctx context.Context, userID, bookID, bookTitle string) error {
+	return s.create(
+		ctx,
+		userID,
+		domain.NotificationRequestApproved,
+		"notification.request_approved.message",
+		map[string]interface{}{"book": bookTitle},
+		domain.NotificationPayload{BookID: bookID},
+		fmt.Sprintf("/books/%s", bookID),
+	)
 }
 
-func (s *service) MarkAllAsRead(ctx context.Context, userID string) error {
-	return s.notificationRepo.MarkAllAsRead(ctx, userID)
+func (s *service) NotifyReturnDue(ctx context.Context, userID, bookID, bookTitle string, daysLeft int) error {
+	return s.create(
+		ctx,
+		userID,
+		domain.NotificationReturnDue,
+		"notification.return_due.message",
+		map[string]interface{}{"book": bookTitle, "days": daysLeft},
+		domain.NotificationPayload{BookID: bookID, DaysLeft: &daysLeft},
+		fmt.Sprintf("/books/%s", bookID),
+	)
 }
 
 // Handover notifications
 func (s *service) NotifyBookInTransit(ctx context.Context, userID, bookID, bookTitle string) error {
-	return s.notificationRepo.Create(
+	return s.create(
 		ctx,
 		userID,
-		"book_in_transit",
-		"Book In Transit",
-		fmt.Sprintf("'%s' is being prepared for handover to you!", bookTitle),
+		domain.NotificationBookInTransit,
+		"notification.book_in_transit.message",
+		map[string]interface{}{"book": bookTitle},
+		domain.NotificationPayload{BookID: bookID},
 		fmt.Sprintf("/books/%s", bookID),
 	)
 }
 
 func (s *service) NotifyBookDelivered(ctx context.Context, userID, bookID, bookTitle string) error {
-	return s.notificationRepo.Create(
+	return s.create(
 		ctx,
 		userID,
-		"book_delivered",
-		"Book Delivered",
-		fmt.Sprintf("'%s' has been successfully delivered to the next reader!", bookTitle),
+		domain.NotificationBookDelivered,
+		"notification.book_delivered.message",
+		map[string]interface{}{"book": bookTitle},
+		domain.NotificationPayload{BookID: bookID},
 		fmt.Sprintf("/books/%s", bookID),
 	)
 }
 
-func (s *service) NotifyHandoverThreadCreated(ctx context.Context, currentHolderID, nextHolderID, bookID, bookTitle string) error {
+func (s *service) NotifyHandoverThreadCreated(ctx context.Context, threadID, currentHolderID, nextHolderID, bookID, bookTitle string) error {
+	params := map[string]interface{}{"book": bookTitle}
+	payload := domain.NotificationPayload{BookID: bookID, ThreadID: threadID}
+
 	// Notify current holder
-	if err := s.notificationRepo.Create(
+	if err := s.create(
 		ctx,
 		currentHolderID,
-		"handover_thread",
-		"Handover Thread Created",
-		fmt.Sprintf("A handover thread has been created for '%s'. Please coordinate the handover.", bookTitle),
+		domain.NotificationHandoverThread,
+		"notification.handover_thread.holder",
+		params,
+		payload,
 		fmt.Sprintf("/handover/%s", bookID),
 	); err != nil {
 		return err
 	}
 
 	// Notify next holder
-	return s.notificationRepo.Create(
+	return s.create(
 		ctx,
 		nextHolderID,
-		"handover_thread",
-		"Handover Thread Created",
-		fmt.Sprintf("You're next in line for '%s'! A handover thread has been created.", bookTitle),
+		domain.NotificationHandoverThread,
+		"notification.handover_thread.next",
+		params,
+		payload,
 		fmt.Sprintf("/handover/%s", bookID),
 	)
 }
 
-func (s *service) NotifyHandoverMessage(ctx context.Context, userID, bookID, bookTitle string) error {
-	return s.notificationRepo.Create(
+func (s *service) NotifyHandoverMessage(ctx context.Context, userID, threadID, bookID, bookTitle string) error {
+	return s.create(
 		ctx,
 		userID,
-		"handover_message",
-		"New Handover Message",
-		fmt.Sprintf("You have a new message in the handover thread for '%s'", bookTitle),
+		domain.NotificationHandoverMessage,
+		"notification.handover_message.message",
+		map[string]interface{}{"book": bookTitle},
+		domain.NotificationPayload{BookID: bookID, ThreadID: threadID},
 		fmt.Sprintf("/handover/%s", bookID),
 	)
 }
+
+func (s *service) NotifyHandoverCancelled(ctx context.Context, userID, threadID, bookID, bookTitle string, reason domain.HandoverCancelReason) error {
+	return s.create(
+		ctx,
+		userID,
+		domain.NotificationHandoverCancelled,
+		"notification.handover_cancelled."+string(reason),
+		map[string]interface{}{"book": bookTitle},
+		domain.NotificationPayload{BookID: bookID, ThreadID: threadID, Reason: string(reason)},
+		fmt.Sprintf("/books/%s", bookID),
+	)
+}
+
+func (s *service) NotifyCampaignLaunched(ctx context.Context, userID, campaignID, campaignTitle string) error {
+	return s.create(
+		ctx,
+		userID,
+		domain.NotificationCampaignLaunched,
+		"notification.campaign_launched.message",
+		map[string]interface{}{"campaign": campaignTitle},
+		domain.NotificationPayload{CampaignID: campaignID},
+		fmt.Sprintf("/campaigns/%s", campaignID),
+	)
+}
+
+func (s *service) NotifyDonationReceived(ctx context.Context, userID, campaignID, campaignTitle string) error {
+	return s.create(
+		ctx,
+		userID,
+		domain.NotificationDonationReceived,
+		"notification.donation_received.message",
+		map[string]interface{}{"campaign": campaignTitle},
+		domain.NotificationPayload{CampaignID: campaignID},
+		fmt.Sprintf("/campaigns/%s", campaignID),
+	)
+}
+
+func (s *service) NotifyIdeaComment(ctx context.Context, userID, ideaID, commentID, commenterName, ideaTitle string, isReply bool) error {
+	messageKey := "notification.idea_comment.message"
+	if isReply {
+		messageKey = "notification.idea_comment.reply"
+	}
+	return s.create(
+		ctx,
+		userID,
+		domain.NotificationIdeaComment,
+		messageKey,
+		map[string]interface{}{"commenter": commenterName, "idea": ideaTitle},
+		domain.NotificationPayload{IdeaID: ideaID, CommentID: commentID},
+		"/ideas",
+	)
+}
+
+func (s *service) NotifyMention(ctx context.Context, userID, authorID string, source domain.MentionSource) error {
+	authorName, err := s.notificationRepo.GetUsername(ctx, authorID)
+	if err != nil {
+		return err
+	}
+
+	params := map[string]interface{}{"author": authorName}
+	var messageKey, link string
+	var payload domain.NotificationPayload
+	switch source.Type {
+	case domain.MentionInIdea:
+		messageKey = "notification.mention.message"
+		if source.CommentID != "" {
+			messageKey = "notification.mention.comment"
+		}
+		params["idea"] = source.Title
+		payload = domain.NotificationPayload{IdeaID: source.ID, CommentID: source.CommentID}
+		link = "/ideas"
+	case domain.MentionInReview:
+		messageKey = "notification.mention.review"
+		payload = domain.NotificationPayload{ReviewID: source.ID}
+		link = fmt.Sprintf("/reviews/%s", source.ID)
+	case domain.MentionInHandover:
+		messageKey = "notification.mention.handover"
+		params["book"] = source.Title
+		payload = domain.NotificationPayload{ThreadID: source.ID, BookID: source.BookID}
+		link = fmt.Sprintf("/handover/%s", source.BookID)
+	case domain.MentionInBio:
+		messageKey = "notification.mention.bio"
+		payload = domain.NotificationPayload{UserID: source.ID}
+		link = fmt.Sprintf("/users/%s", source.ID)
+	default:
+		return domain.ErrInvalidInput
+	}
+	return s.create(ctx, userID, domain.NotificationMention, messageKey, params, payload, link)
+}
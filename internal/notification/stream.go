@@ -0,0 +1,84 @@
+package notification
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/online-library/internal/domain"
+	"go.uber.org/zap"
+)
+
+// streamCatchUpLimit bounds how many missed notifications a reconnecting
+// stream replays.
+const streamCatchUpLimit = 100
+
+// UserChannel is the pub/sub channel a user's new notifications are
+// announced on.
+func UserChannel(userID string) string {
+	return "notifications_" + userID
+}
+
+func (s *service) CountUnread(ctx context.Context, userID string) (int, error) {
+	return s.notificationRepo.CountUnread(ctx, userID)
+}
+
+func (s *service) Stream(ctx context.Context, userID, lastEventID string) (<-chan *domain.Notification, error) {
+	if _, err := uuid.Parse(lastEventID); err != nil {
+		lastEventID = ""
+	}
+
+	// Subscribe before catching up so nothing published in between is lost
+	announcements, err := s.pubsub.Subscribe(ctx, UserChannel(userID))
+	if err != nil {
+		s.log.Error("failed to subscribe to notifications", zap.String("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+
+	// Without a resume point only notifications created from now on are
+	// streamed; start the cursor at the newest existing one
+	cursor := lastEventID
+	if cursor == "" {
+		latest, err := s.notificationRepo.GetByUserID(ctx, userID, false, 1)
+		if err != nil {
+			return nil, err
+		}
+		if len(latest) > 0 {
+			cursor = latest[0].ID
+		}
+	}
+
+	out := make(chan *domain.Notification)
+	go func() {
+		defer close(out)
+
+		catchUp := func() bool {
+			notifications, err := s.notificationRepo.GetAfter(ctx, userID, cursor, streamCatchUpLimit)
+			if err != nil {
+				s.log.Warn("failed to load notifications", zap.String("user_id", userID), zap.Error(err))
+				return ctx.Err() == nil
+			}
+			for _, n := range notifications {
+				select {
+				case out <- n:
+					cursor = n.ID
+				case <-ctx.Done():
+					return false
+				}
+			}
+			return true
+		}
+
+		if lastEventID != "" && !catchUp() {
+			return
+		}
+		// Every announcement, including the empty resync hint, means
+		// "read from the cursor again"
+		for range announcements {
+			if !catchUp() {
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
@@ -37,7 +37,7 @@ func (r *AdminRepository) GetPendingRequests(ctx context.Context, limit, offset
 		ORDER BY br.priority_score DESC, br.requested_at ASC
 		LIMIT $1 OFFSET $2
 	`
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -91,7 +91,7 @@ func (r *AdminRepository) GetRequestsByBook(ctx context.Context, bookID string)
 		WHERE br.book_id = $1 AND br.status = 'pending'
 		ORDER BY br.priority_score DESC, br.requested_at ASC
 	`
-	rows, err := r.db.QueryContext(ctx, query, bookID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, bookID)
 	if err != nil {
 		return nil, err
 	}
@@ -131,7 +131,7 @@ func (r *AdminRepository) UpdateRequestStatus(ctx context.Context, requestID str
 		SET status = $1, processed_at = $2, due_date = $3
 		WHERE id = $4
 	`
-	_, err := r.db.ExecContext(ctx, query, status, processedAt, dueDate, requestID)
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, status, processedAt, dueDate, requestID)
 	return err
 }
 
@@ -146,7 +146,7 @@ func (r *AdminRepository) GetAllUsers(ctx context.Context, limit, offset int) ([
 		ORDER BY created_at DESC
 		LIMIT $1 OFFSET $2
 	`
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -168,7 +168,7 @@ func (r *AdminRepository) GetAllUsers(ctx context.Context, limit, offset int) ([
 
 func (r *AdminRepository) UpdateUserRole(ctx context.Context, userID string, role string) error {
 	query := `UPDATE users SET role = $1, updated_at = NOW() WHERE id = $2`
-	_, err := r.db.ExecContext(ctx, query, role, userID)
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, role, userID)
 	return err
 }
 
@@ -176,55 +176,55 @@ func (r *AdminRepository) GetSystemStats(ctx context.Context) (*admin.SystemStat
 	stats := &admin.SystemStats{}
 
 	// Total users
-	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM users").Scan(&stats.TotalUsers)
+	err := conn(ctx, r.db).QueryRowContext(ctx, "SELECT COUNT(*) FROM users").Scan(&stats.TotalUsers)
 	if err != nil {
 		return nil, err
 	}
 
 	// Total books
-	err = r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM books").Scan(&stats.TotalBooks)
+	err = conn(ctx, r.db).QueryRowContext(ctx, "SELECT COUNT(*) FROM books").Scan(&stats.TotalBooks)
 	if err != nil {
 		return nil, err
 	}
 
 	// Available books
-	err = r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM books WHERE status = 'available'").Scan(&stats.AvailableBooks)
+	err = conn(ctx, r.db).QueryRowContext(ctx, "SELECT COUNT(*) FROM books WHERE status = 'available'").Scan(&stats.AvailableBooks)
 	if err != nil {
 		return nil, err
 	}
 
 	// Books in circulation
-	err = r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM books WHERE status = 'reading'").Scan(&stats.BooksInCirculation)
+	err = conn(ctx, r.db).QueryRowContext(ctx, "SELECT COUNT(*) FROM books WHERE status = 'reading'").Scan(&stats.BooksInCirculation)
 	if err != nil {
 		return nil, err
 	}
 
 	// Pending requests
-	err = r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM book_requests WHERE status = 'pending'").Scan(&stats.PendingRequests)
+	err = conn(ctx, r.db).QueryRowContext(ctx, "SELECT COUNT(*) FROM book_requests WHERE status = 'pending'").Scan(&stats.PendingRequests)
 	if err != nil {
 		return nil, err
 	}
 
 	// Total donations
-	err = r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM donations").Scan(&stats.TotalDonations)
+	err = conn(ctx, r.db).QueryRowContext(ctx, "SELECT COUNT(*) FROM donations").Scan(&stats.TotalDonations)
 	if err != nil {
 		return nil, err
 	}
 
 	// Total ideas
-	err = r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM reading_ideas").Scan(&stats.TotalIdeas)
+	err = conn(ctx, r.db).QueryRowContext(ctx, "SELECT COUNT(*) FROM reading_ideas").Scan(&stats.TotalIdeas)
 	if err != nil {
 		return nil, err
 	}
 
 	// Total reviews
-	err = r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM user_reviews").Scan(&stats.TotalReviews)
+	err = conn(ctx, r.db).QueryRowContext(ctx, "SELECT COUNT(*) FROM user_reviews").Scan(&stats.TotalReviews)
 	if err != nil {
 		return nil, err
 	}
 
 	// Average success score
-	err = r.db.QueryRowContext(ctx, "SELECT COALESCE(AVG(success_score), 0) FROM users").Scan(&stats.AvgSuccessScore)
+	err = conn(ctx, r.db).QueryRowContext(ctx, "SELECT COALESCE(AVG(success_score), 0) FROM users").Scan(&stats.AvgSuccessScore)
 	if err != nil {
 		return nil, err
 	}
@@ -240,7 +240,7 @@ func (r *AdminRepository) GetAuditLogs(ctx context.Context, limit, offset int) (
 		ORDER BY created_at DESC
 		LIMIT $1 OFFSET $2
 	`
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -279,7 +279,7 @@ func (r *AdminRepository) CreateAuditLog(ctx context.Context, log *admin.AuditLo
 		INSERT INTO audit_logs (id, user_id, action, resource_type, resource_id, details, ip_address, user_agent, created_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
 	`
-	_, err := r.db.ExecContext(ctx, query, log.ID, log.UserID, log.Action, log.ResourceType,
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, log.ID, log.UserID, log.Action, log.ResourceType,
 		log.ResourceID, detailsJSON, log.IPAddress, log.UserAgent)
 	return err
 }
@@ -316,7 +316,7 @@ func (r *AdminRepository) GetAllBooks(ctx context.Context, limit, offset int, fi
 	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", argPos, argPos+1)
 	args = append(args, limit, offset)
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -336,18 +336,6 @@ func (r *AdminRepository) GetAllBooks(ctx context.Context, limit, offset int, fi
 	return books, nil
 }
 
-func (r *AdminRepository) UpdateBookStatus(ctx context.Context, bookID string, status string) error {
-	query := `UPDATE books SET status = $1, updated_at = NOW() WHERE id = $2`
-	_, err := r.db.ExecContext(ctx, query, status, bookID)
-	return err
-}
-
-func (r *AdminRepository) AssignBookToUser(ctx context.Context, bookID, userID string) error {
-	query := `UPDATE books SET status = 'reading', current_holder_id = $1, updated_at = NOW() WHERE id = $2`
-	_, err := r.db.ExecContext(ctx, query, userID, bookID)
-	return err
-}
-
 func (r *AdminRepository) CreateReadingHistory(ctx context.Context, bookID, userID, dueDate string) error {
 	// For initial handover, we don't create reading history yet
 	// The reading history will be created when the first reader marks the book as delivered
@@ -357,10 +345,15 @@ func (r *AdminRepository) CreateReadingHistory(ctx context.Context, bookID, user
 
 func (r *AdminRepository) IncrementUserBooksReceived(ctx context.Context, userID string) error {
 	query := `UPDATE users SET books_received = books_received + 1, updated_at = NOW() WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, userID)
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, userID)
 	return err
 }
 
+// LockBook holds the book row until the surrounding transaction ends
+func (r *AdminRepository) LockBook(ctx context.Context, bookID string) error {
+	return lockBook(ctx, conn(ctx, r.db), bookID)
+}
+
 func (r *AdminRepository) GetBookByID(ctx context.Context, bookID string) (*domain.Book, error) {
 	query := `
 		SELECT id, title, author, COALESCE(isbn, ''), COALESCE(cover_url, ''),
@@ -374,7 +367,7 @@ func (r *AdminRepository) GetBookByID(ctx context.Context, bookID string) (*doma
 	`
 	b := &domain.Book{}
 	var currentHolderID, createdBy sql.NullString
-	err := r.db.QueryRowContext(ctx, query, bookID).Scan(
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, bookID).Scan(
 		&b.ID, &b.Title, &b.Author, &b.ISBN, &b.CoverURL, &b.Description, &b.Category,
 		pq.Array(&b.Tags), pq.Array(&b.Topics), &b.PhysicalCode, &b.Status, &b.MaxReadingDays,
 		&currentHolderID, &createdBy, &b.IsDonated, &b.TotalReads, &b.AverageRating,
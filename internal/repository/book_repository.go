@@ -24,13 +24,18 @@ func NewBookRepository(db *sql.DB, log *zap.Logger) *BookRepository {
 func (r *BookRepository) Create(ctx context.Context, b *domain.Book) error {
 	query := `
 		INSERT INTO books (id, title, author, isbn, cover_url, description, category, 
-		                   tags, topics, physical_code, status, max_reading_days, created_by, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		                   tags, topics, physical_code, status, max_reading_days, created_by,
+		                   donated_by, is_donated, donation_date, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
 	`
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := conn(ctx, r.db).ExecContext(ctx, query,
 		b.ID, b.Title, b.Author, b.ISBN, b.CoverURL, b.Description, b.Category,
 		pq.Array(b.Tags), pq.Array(b.Topics), b.PhysicalCode, b.Status, b.MaxReadingDays,
-		b.CreatedBy, b.CreatedAt, b.UpdatedAt)
+		b.CreatedBy, nullString(b.DonatedBy), b.IsDonated, nullTime(b.DonationDate), b.CreatedAt, b.UpdatedAt)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		// physical_code is the only unique column callers choose
+		return domain.ErrAlreadyExists
+	}
 	return err
 }
 
@@ -41,20 +46,24 @@ func (r *BookRepository) FindByID(ctx context.Context, id string) (*domain.Book,
 		       COALESCE(description, ''), COALESCE(category, ''),
 		       COALESCE(tags, '{}'), COALESCE(topics, '{}'),
 		       COALESCE(physical_code, ''), status, COALESCE(max_reading_days, 14), current_holder_id,
-		       COALESCE(is_donated, false), COALESCE(total_reads, 0),
+		       created_by, donated_by, COALESCE(is_donated, false), donation_date, COALESCE(total_reads, 0),
 		       COALESCE(average_rating, 0), created_at, updated_at
 		FROM books WHERE id = $1
 	`
-	var currentHolderID sql.NullString
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	var currentHolderID, createdBy, donatedBy sql.NullString
+	var donationDate sql.NullTime
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
 		&b.ID, &b.Title, &b.Author, &b.ISBN, &b.CoverURL, &b.Description, &b.Category,
 		pq.Array(&b.Tags), pq.Array(&b.Topics), &b.PhysicalCode, &b.Status, &b.MaxReadingDays,
-		&currentHolderID, &b.IsDonated, &b.TotalReads, &b.AverageRating,
+		&currentHolderID, &createdBy, &donatedBy, &b.IsDonated, &donationDate, &b.TotalReads, &b.AverageRating,
 		&b.CreatedAt, &b.UpdatedAt)
 	if err == sql.ErrNoRows {
 		return nil, domain.ErrNotFound
 	}
 	b.CurrentHolderID = stringPtr(currentHolderID)
+	b.CreatedBy = stringPtr(createdBy)
+	b.DonatedBy = stringPtr(donatedBy)
+	b.DonationDate = timePtr(donationDate)
 	return b, err
 }
 
@@ -66,7 +75,7 @@ func (r *BookRepository) List(ctx context.Context, limit, offset int) ([]*domain
 		ORDER BY created_at DESC
 		LIMIT $1 OFFSET $2
 	`
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -89,17 +98,17 @@ func (r *BookRepository) Update(ctx context.Context, id string, b *domain.Book)
 	query := `
 		UPDATE books SET title = $1, author = $2, isbn = $3, cover_url = $4,
 		       description = $5, category = $6, tags = $7, topics = $8,
-		       status = $9, updated_at = $10
-		WHERE id = $11
+		       updated_at = $9
+		WHERE id = $10
 	`
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := conn(ctx, r.db).ExecContext(ctx, query,
 		b.Title, b.Author, b.ISBN, b.CoverURL, b.Description, b.Category,
-		pq.Array(b.Tags), pq.Array(b.Topics), b.Status, b.UpdatedAt, id)
+		pq.Array(b.Tags), pq.Array(b.Topics), b.UpdatedAt, id)
 	return err
 }
 
 func (r *BookRepository) Delete(ctx context.Context, id string) error {
-	_, err := r.db.ExecContext(ctx, "DELETE FROM books WHERE id = $1", id)
+	_, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM books WHERE id = $1", id)
 	return err
 }
 
@@ -109,7 +118,7 @@ func (r *BookRepository) CreateRequest(ctx context.Context, req *domain.BookRequ
 		                          interest_match_score, distance_km, requested_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	`
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := conn(ctx, r.db).ExecContext(ctx, query,
 		req.ID, req.BookID, req.UserID, req.Status, req.PriorityScore,
 		req.InterestMatchScore, req.DistanceKm, req.RequestedAt)
 	return err
@@ -127,7 +136,7 @@ func (r *BookRepository) FindRequestsByUserID(ctx context.Context, userID string
 		WHERE br.user_id = $1 AND br.status = 'pending'
 		ORDER BY br.requested_at DESC
 	`
-	rows, err := r.db.QueryContext(ctx, query, userID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -165,6 +174,16 @@ func (r *BookRepository) FindRequestsByUserID(ctx context.Context, userID string
 	return requests, nil
 }
 
+func (r *BookRepository) GetWishlistPriority(ctx context.Context, bookID, userID string) (int, error) {
+	query := `SELECT priority_level FROM user_bookmarks WHERE book_id = $1 AND user_id = $2 AND bookmark_type = 'wishlist'`
+	var priority int
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, bookID, userID).Scan(&priority)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return priority, err
+}
+
 func (r *BookRepository) FindRequestByBookAndUser(ctx context.Context, bookID, userID string) (*domain.BookRequest, error) {
 	query := `
 		SELECT id, book_id, user_id, status, priority_score,
@@ -177,7 +196,7 @@ func (r *BookRepository) FindRequestByBookAndUser(ctx context.Context, bookID, u
 	var distanceKm sql.NullFloat64
 	var processedAt, dueDate sql.NullTime
 
-	err := r.db.QueryRowContext(ctx, query, bookID, userID).Scan(
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, bookID, userID).Scan(
 		&req.ID, &req.BookID, &req.UserID, &req.Status, &req.PriorityScore,
 		&req.InterestMatchScore, &distanceKm, &req.RequestedAt, &processedAt, &dueDate,
 	)
@@ -203,7 +222,7 @@ func (r *BookRepository) FindRequestByBookAndUser(ctx context.Context, bookID, u
 
 func (r *BookRepository) CancelRequest(ctx context.Context, bookID, userID string) error {
 	query := `DELETE FROM book_requests WHERE book_id = $1 AND user_id = $2 AND status = 'pending'`
-	result, err := r.db.ExecContext(ctx, query, bookID, userID)
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, bookID, userID)
 	if err != nil {
 		return err
 	}
@@ -230,7 +249,7 @@ func (r *BookRepository) Search(ctx context.Context, query string, limit, offset
 		ORDER BY created_at DESC
 		LIMIT $2 OFFSET $3
 	`
-	rows, err := r.db.QueryContext(ctx, searchQuery, "%"+query+"%", limit, offset)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, searchQuery, "%"+query+"%", limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -258,7 +277,7 @@ func (r *BookRepository) FilterByStatus(ctx context.Context, status string, limi
 		ORDER BY created_at DESC
 		LIMIT $2 OFFSET $3
 	`
-	rows, err := r.db.QueryContext(ctx, query, status, limit, offset)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, status, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -277,12 +296,6 @@ func (r *BookRepository) FilterByStatus(ctx context.Context, status string, limi
 	return books, nil
 }
 
-func (r *BookRepository) ReturnBook(ctx context.Context, bookID string) error {
-	query := `UPDATE books SET status = 'available', current_holder_id = NULL, updated_at = NOW() WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, bookID)
-	return err
-}
-
 func (r *BookRepository) CompleteReadingHistory(ctx context.Context, bookID, userID string) error {
 	query := `
 		UPDATE reading_history 
@@ -291,7 +304,7 @@ func (r *BookRepository) CompleteReadingHistory(ctx context.Context, bookID, use
 		    updated_at = NOW()
 		WHERE book_id = $1 AND reader_id = $2 AND end_date IS NULL
 	`
-	_, err := r.db.ExecContext(ctx, query, bookID, userID)
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, bookID, userID)
 	return err
 }
 
@@ -306,7 +319,7 @@ func (r *BookRepository) GetReadingHistoryByUser(ctx context.Context, userID str
 		WHERE rh.reader_id = $1
 		ORDER BY rh.start_date DESC
 	`
-	rows, err := r.db.QueryContext(ctx, query, userID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -361,7 +374,7 @@ func (r *BookRepository) GetBooksOnHoldByUser(ctx context.Context, userID string
 		WHERE b.status = 'on_hold' AND b.current_holder_id = $1
 		ORDER BY b.updated_at DESC
 	`
-	rows, err := r.db.QueryContext(ctx, query, userID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -385,3 +398,83 @@ func (r *BookRepository) GetBooksOnHoldByUser(ctx context.Context, userID string
 	}
 	return books, nil
 }
+
+// GetTimeline merges everything that happened to a book, oldest first.
+// Each source contributes up to two actors; names come from users.
+func (r *BookRepository) GetTimeline(ctx context.Context, bookID string) ([]*domain.BookTimelineEvent, error) {
+	query := `
+		WITH events (kind, at, role1, user1, role2, user2, status, detail, ref_id) AS (
+			SELECT 'created', b.created_at, 'owner', b.created_by, NULL, NULL::uuid, NULL, NULL, NULL::uuid
+			FROM books b WHERE b.id = $1
+			UNION ALL
+			SELECT 'donated', COALESCE(d.received_at, d.created_at), 'donor', d.donor_id, NULL, NULL, NULL, NULL, d.id
+			FROM donations d WHERE d.book_id = $1 AND d.donation_type = 'book'
+			UNION ALL
+			SELECT 'requested', br.requested_at, 'requester', br.user_id, NULL, NULL, NULL, NULL, br.id
+			FROM book_requests br WHERE br.book_id = $1
+			UNION ALL
+			SELECT 'approved', br.processed_at, 'requester', br.user_id, NULL, NULL, NULL, NULL, br.id
+			FROM book_requests br
+			WHERE br.book_id = $1 AND br.status = 'approved' AND br.processed_at IS NOT NULL
+			UNION ALL
+			SELECT 'handover_started', ht.created_at, 'holder', ht.current_holder_id,
+			       'next_holder', ht.next_holder_id, NULL, NULL, ht.id
+			FROM handover_threads ht WHERE ht.book_id = $1
+			UNION ALL
+			SELECT CASE WHEN ht.cancel_reason = 'unresponsive' THEN 'reported' ELSE 'handover_cancelled' END,
+			       ht.cancelled_at, 'cancelled_by', ht.cancelled_by, 'at_fault', ht.at_fault_id,
+			       NULL, concat_ws(': ', ht.cancel_reason, NULLIF(ht.cancel_note, '')), ht.id
+			FROM handover_threads ht
+			WHERE ht.book_id = $1 AND ht.status = 'cancelled' AND ht.cancelled_at IS NOT NULL
+			UNION ALL
+			SELECT 'delivered', rh.start_date, 'reader', rh.reader_id,
+			       'previous_holder', COALESCE(LAG(rh.reader_id) OVER (ORDER BY rh.start_date), b.created_by),
+			       NULL, NULL, NULL
+			FROM reading_history rh JOIN books b ON b.id = rh.book_id
+			WHERE rh.book_id = $1
+			UNION ALL
+			SELECT 'completed', rh.completed_at, 'reader', rh.reader_id, NULL, NULL, NULL, NULL, NULL
+			FROM reading_history rh
+			WHERE rh.book_id = $1 AND rh.is_completed AND rh.completed_at IS NOT NULL
+			UNION ALL
+			SELECT CASE WHEN be.action = 'returned' THEN 'returned' ELSE 'status_changed' END,
+			       be.created_at, be.actor, be.actor_id, NULL, NULL, be.to_status, be.note, NULL
+			FROM book_events be
+			WHERE be.book_id = $1 AND be.action IN ('returned', 'admin_override')
+		)
+		SELECT e.kind, e.at, e.role1, e.user1, COALESCE(u1.username, ''),
+		       e.role2, e.user2, COALESCE(u2.username, ''),
+		       COALESCE(e.status, ''), COALESCE(e.detail, ''), e.ref_id
+		FROM events e
+		LEFT JOIN users u1 ON u1.id = e.user1
+		LEFT JOIN users u2 ON u2.id = e.user2
+		ORDER BY e.at, e.kind
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, bookID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*domain.BookTimelineEvent
+	for rows.Next() {
+		e := &domain.BookTimelineEvent{}
+		var role1, role2, user1, user2, refID sql.NullString
+		var name1, name2 string
+		err := rows.Scan(&e.Kind, &e.At, &role1, &user1, &name1, &role2, &user2, &name2,
+			&e.Status, &e.Detail, &refID)
+		if err != nil {
+			return nil, err
+		}
+		e.Actors = []domain.TimelineActor{}
+		if role1.Valid && user1.Valid {
+			e.Actors = append(e.Actors, domain.TimelineActor{Role: role1.String, UserID: stringPtr(user1), Username: name1})
+		}
+		if role2.Valid && user2.Valid {
+			e.Actors = append(e.Actors, domain.TimelineActor{Role: role2.String, UserID: stringPtr(user2), Username: name2})
+		}
+		e.RefID = refID.String
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/yourusername/online-library/internal/booklifecycle"
+	"github.com/yourusername/online-library/internal/domain"
+	"go.uber.org/zap"
+)
+
+type BookLifecycleRepository struct {
+	db  *sql.DB
+	log *zap.Logger
+}
+
+var _ booklifecycle.LifecycleRepo = (*BookLifecycleRepository)(nil)
+
+func NewBookLifecycleRepository(db *sql.DB, log *zap.Logger) *BookLifecycleRepository {
+	return &BookLifecycleRepository{db: db, log: log}
+}
+
+func (r *BookLifecycleRepository) GetBookState(ctx context.Context, bookID string) (domain.BookStatus, *string, error) {
+	var status domain.BookStatus
+	var holderID sql.NullString
+	query := `SELECT status, current_holder_id FROM books WHERE id = $1 FOR UPDATE`
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, bookID).Scan(&status, &holderID)
+	if err == sql.ErrNoRows {
+		return "", nil, domain.ErrBookNotFound
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	return status, stringPtr(holderID), nil
+}
+
+func (r *BookLifecycleRepository) SetBookState(ctx context.Context, bookID string, status domain.BookStatus, holderID *string) error {
+	query := `UPDATE books SET status = $1, current_holder_id = $2, updated_at = NOW() WHERE id = $3`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, status, holderID, bookID)
+	return err
+}
+
+func (r *BookLifecycleRepository) CreateBookEvent(ctx context.Context, e *domain.BookEvent) error {
+	var from *domain.BookStatus
+	if e.FromStatus != "" {
+		from = &e.FromStatus
+	}
+	query := `
+		INSERT INTO book_events (book_id, action, from_status, to_status, actor, actor_id, holder_id, note, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), $9)
+		RETURNING id
+	`
+	return conn(ctx, r.db).QueryRowContext(ctx, query,
+		e.BookID, e.Action, from, e.ToStatus, e.Actor, e.ActorID, e.HolderID, e.Note, e.CreatedAt,
+	).Scan(&e.ID)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 
+	"github.com/lib/pq"
 	"github.com/yourusername/online-library/internal/bookmark"
 	"github.com/yourusername/online-library/internal/domain"
 	"go.uber.org/zap"
@@ -21,12 +22,86 @@ func NewBookmarkRepository(db *sql.DB, log *zap.Logger) *BookmarkRepository {
 }
 
 func (r *BookmarkRepository) Create(ctx context.Context, b *domain.UserBookmark) error {
-	query := `INSERT INTO user_bookmarks (id, user_id, book_id, bookmark_type, priority_level, created_at)
-	          VALUES ($1, $2, $3, $4, $5, $6)`
-	_, err := r.db.ExecContext(ctx, query, b.ID, b.UserID, b.BookID, b.BookmarkType, b.PriorityLevel, b.CreatedAt)
+	query := `INSERT INTO user_bookmarks (id, user_id, book_id, bookmark_type, priority_level, auto_request, created_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	_, err := r.db.ExecContext(ctx, query, b.ID, b.UserID, b.BookID, b.BookmarkType, b.PriorityLevel, b.AutoRequest, b.CreatedAt)
+	if pqErr, ok := err.(*pq.Error); ok {
+		switch pqErr.Code {
+		case "23505":
+			return domain.ErrAlreadyExists
+		case "23503":
+			return domain.ErrBookNotFound
+		}
+	}
 	return err
 }
 
+func (r *BookmarkRepository) UpdateWatch(ctx context.Context, b *domain.UserBookmark) error {
+	query := `
+		UPDATE user_bookmarks
+		SET priority_level = $1, auto_request = $2
+		WHERE user_id = $3 AND book_id = $4 AND bookmark_type = 'wishlist'
+		RETURNING id, alerted_at, created_at
+	`
+	var alertedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, b.PriorityLevel, b.AutoRequest, b.UserID, b.BookID).
+		Scan(&b.ID, &alertedAt, &b.CreatedAt)
+	if err == sql.ErrNoRows {
+		return domain.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	b.AlertedAt = timePtr(alertedAt)
+	return nil
+}
+
+func (r *BookmarkRepository) ClaimWatchAlerts(ctx context.Context, limit int) ([]*bookmark.WatchAlert, error) {
+	// The EXISTS is re-checked against the claimed row, so a server that
+	// waited on another's claim skips the entry
+	query := `
+		UPDATE user_bookmarks ub
+		SET alerted_at = NOW()
+		FROM books b
+		WHERE ub.id IN (
+			SELECT w.id
+			FROM user_bookmarks w
+			JOIN books wb ON wb.id = w.book_id
+			WHERE w.bookmark_type = 'wishlist' AND wb.status IN ('available', 'on_hold')
+			  AND wb.current_holder_id IS DISTINCT FROM w.user_id
+			  AND EXISTS (
+				SELECT 1 FROM book_events e
+				WHERE e.book_id = w.book_id AND e.to_status IN ('available', 'on_hold')
+				  AND e.created_at > COALESCE(w.alerted_at, w.created_at)
+			  )
+			ORDER BY w.priority_level DESC, w.created_at ASC
+			LIMIT $1
+		)
+		  AND b.id = ub.book_id
+		  AND EXISTS (
+			SELECT 1 FROM book_events e
+			WHERE e.book_id = ub.book_id AND e.to_status IN ('available', 'on_hold')
+			  AND e.created_at > COALESCE(ub.alerted_at, ub.created_at)
+		  )
+		RETURNING ub.id, ub.user_id, ub.book_id, b.title, ub.auto_request
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var alerts []*bookmark.WatchAlert
+	for rows.Next() {
+		a := &bookmark.WatchAlert{}
+		if err := rows.Scan(&a.BookmarkID, &a.UserID, &a.BookID, &a.BookTitle, &a.AutoRequest); err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, a)
+	}
+	return alerts, rows.Err()
+}
+
 func (r *BookmarkRepository) Delete(ctx context.Context, userID, bookID, bookmarkType string) error {
 	query := `DELETE FROM user_bookmarks WHERE user_id = $1 AND book_id = $2 AND bookmark_type = $3`
 	_, err := r.db.ExecContext(ctx, query, userID, bookID, bookmarkType)
@@ -36,7 +111,7 @@ func (r *BookmarkRepository) Delete(ctx context.Context, userID, bookID, bookmar
 func (r *BookmarkRepository) FindByUserID(ctx context.Context, userID string) ([]*domain.UserBookmark, error) {
 	query := `
 		SELECT 
-			ub.id, ub.user_id, ub.book_id, ub.bookmark_type, ub.priority_level, ub.created_at,
+			ub.id, ub.user_id, ub.book_id, ub.bookmark_type, ub.priority_level, ub.auto_request, ub.alerted_at, ub.created_at,
 			b.id, b.title, b.author, COALESCE(b.cover_url, ''), COALESCE(b.category, ''), 
 			b.status, COALESCE(b.average_rating, 0)
 		FROM user_bookmarks ub
@@ -54,14 +129,16 @@ func (r *BookmarkRepository) FindByUserID(ctx context.Context, userID string) ([
 	for rows.Next() {
 		b := &domain.UserBookmark{}
 		book := &domain.Book{}
+		var alertedAt sql.NullTime
 		err := rows.Scan(
-			&b.ID, &b.UserID, &b.BookID, &b.BookmarkType, &b.PriorityLevel, &b.CreatedAt,
+			&b.ID, &b.UserID, &b.BookID, &b.BookmarkType, &b.PriorityLevel, &b.AutoRequest, &alertedAt, &b.CreatedAt,
 			&book.ID, &book.Title, &book.Author, &book.CoverURL, &book.Category,
 			&book.Status, &book.AverageRating,
 		)
 		if err != nil {
 			return nil, err
 		}
+		b.AlertedAt = timePtr(alertedAt)
 		b.Book = book
 		bookmarks = append(bookmarks, b)
 	}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/yourusername/online-library/internal/domain"
+	"github.com/yourusername/online-library/internal/donation"
+	"go.uber.org/zap"
+)
+
+type CampaignRepository struct {
+	db  *sql.DB
+	log *zap.Logger
+}
+
+var _ donation.CampaignRepo = (*CampaignRepository)(nil)
+
+func NewCampaignRepository(db *sql.DB, log *zap.Logger) *CampaignRepository {
+	return &CampaignRepository{db: db, log: log}
+}
+
+const campaignColumns = `id, title, COALESCE(description, ''), goal_type, goal_amount, goal_books, COALESCE(currency, ''),
+	COALESCE(category, ''), starts_at, ends_at, created_by, created_at, updated_at`
+
+func (r *CampaignRepository) Create(ctx context.Context, c *domain.Campaign) error {
+	query := `
+		INSERT INTO campaigns (id, title, description, goal_type, goal_amount, goal_books, currency, category,
+		                       starts_at, ends_at, created_by, created_at, updated_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''), $9, $10, $11, $12, $13)
+	`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, c.ID, c.Title, c.Description, c.GoalType,
+		nullFloat64(c.GoalAmount), nullInt64(c.GoalBooks), c.Currency, c.Category,
+		c.StartsAt, c.EndsAt, c.CreatedBy, c.CreatedAt, c.UpdatedAt)
+	return err
+}
+
+func (r *CampaignRepository) Update(ctx context.Context, c *domain.Campaign) error {
+	query := `
+		UPDATE campaigns
+		SET title = $1, description = NULLIF($2, ''), goal_amount = $3, goal_books = $4, currency = NULLIF($5, ''),
+		    category = NULLIF($6, ''), starts_at = $7, ends_at = $8, updated_at = $9
+		WHERE id = $10
+	`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, c.Title, c.Description, nullFloat64(c.GoalAmount),
+		nullInt64(c.GoalBooks), c.Currency, c.Category, c.StartsAt, c.EndsAt, c.UpdatedAt, c.ID)
+	if err != nil {
+		return err
+	}
+	n, err := rowsAffected(result)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *CampaignRepository) GetByID(ctx context.Context, id string) (*domain.Campaign, error) {
+	query := `SELECT ` + campaignColumns + ` FROM campaigns WHERE id = $1`
+	c, err := scanCampaign(conn(ctx, r.db).QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	return c, err
+}
+
+func (r *CampaignRepository) List(ctx context.Context, endsAfter time.Time) ([]*domain.Campaign, error) {
+	query := `SELECT ` + campaignColumns + ` FROM campaigns WHERE ends_at > $1 ORDER BY ends_at ASC`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, endsAfter)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var campaigns []*domain.Campaign
+	for rows.Next() {
+		c, err := scanCampaign(rows)
+		if err != nil {
+			return nil, err
+		}
+		campaigns = append(campaigns, c)
+	}
+	return campaigns, rows.Err()
+}
+
+func (r *CampaignRepository) Progress(ctx context.Context, campaignIDs []string) (map[string]*domain.CampaignProgress, error) {
+	query := `
+		SELECT campaign_id,
+		       COALESCE(SUM(amount) FILTER (WHERE donation_type = 'money' AND status = 'confirmed'), 0),
+		       COUNT(*) FILTER (WHERE donation_type = 'book' AND status = 'catalogued'),
+		       COUNT(DISTINCT donor_id) FILTER (WHERE status IN ('confirmed', 'catalogued'))
+		FROM donations
+		WHERE campaign_id = ANY($1)
+		GROUP BY campaign_id
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, pq.Array(campaignIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	progress := make(map[string]*domain.CampaignProgress)
+	for rows.Next() {
+		var id string
+		p := &domain.CampaignProgress{}
+		if err := rows.Scan(&id, &p.Raised, &p.BooksCatalogued, &p.Donors); err != nil {
+			return nil, err
+		}
+		progress[id] = p
+	}
+	return progress, rows.Err()
+}
+
+func (r *CampaignRepository) ListInterestedUsers(ctx context.Context, category string) ([]string, error) {
+	query := `
+		SELECT user_id FROM user_interests WHERE LOWER(interest) = LOWER($1)
+		UNION
+		SELECT ub.user_id
+		FROM user_bookmarks ub
+		JOIN books b ON b.id = ub.book_id
+		WHERE LOWER(b.category) = LOWER($1)
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, category)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var userIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, id)
+	}
+	return userIDs, rows.Err()
+}
+
+func scanCampaign(row rowScanner) (*domain.Campaign, error) {
+	c := &domain.Campaign{}
+	var goalAmount sql.NullFloat64
+	var goalBooks sql.NullInt64
+	err := row.Scan(&c.ID, &c.Title, &c.Description, &c.GoalType, &goalAmount, &goalBooks, &c.Currency,
+		&c.Category, &c.StartsAt, &c.EndsAt, &c.CreatedBy, &c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	c.GoalAmount = float64Ptr(goalAmount)
+	c.GoalBooks = intPtr(goalBooks)
+	return c, nil
+}
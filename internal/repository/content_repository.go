@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/yourusername/online-library/internal/content"
+	"github.com/yourusername/online-library/internal/domain"
+	"go.uber.org/zap"
+)
+
+type ContentRepository struct {
+	db  *sql.DB
+	log *zap.Logger
+}
+
+var _ content.Repo = (*ContentRepository)(nil)
+
+func NewContentRepository(db *sql.DB, log *zap.Logger) *ContentRepository {
+	return &ContentRepository{db: db, log: log}
+}
+
+// contentColumns says where each field's source and HTML are stored.
+// System handover messages are rendered per viewer from the i18n catalog
+// and have no HTML copy.
+var contentColumns = map[content.Field]struct {
+	table, source, html, filter string
+}{
+	content.FieldIdea:            {"reading_ideas", "content", "content_html", "true"},
+	content.FieldIdeaRevision:    {"idea_revisions", "content", "content_html", "true"},
+	content.FieldIdeaComment:     {"idea_comments", "content", "content_html", "true"},
+	content.FieldHandoverMessage: {"handover_messages", "message", "message_html", "is_system_message = false"},
+	content.FieldReviewComment:   {"user_reviews", "comment", "comment_html", "true"},
+	content.FieldBio:             {"users", "bio", "bio_html", "true"},
+}
+
+func (r *ContentRepository) FindUserIDs(ctx context.Context, usernames []string) (map[string]string, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx,
+		`SELECT LOWER(username), id FROM users WHERE LOWER(username) = ANY($1)`, pq.Array(usernames))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make(map[string]string)
+	for rows.Next() {
+		var username, id string
+		if err := rows.Scan(&username, &id); err != nil {
+			return nil, err
+		}
+		ids[username] = id
+	}
+	return ids, rows.Err()
+}
+
+func (r *ContentRepository) FindBookTitles(ctx context.Context, ids []string) (map[string]string, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx,
+		`SELECT id, title FROM books WHERE id = ANY($1::uuid[])`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	titles := make(map[string]string)
+	for rows.Next() {
+		var id, title string
+		if err := rows.Scan(&id, &title); err != nil {
+			return nil, err
+		}
+		titles[strings.ToLower(id)] = title
+	}
+	return titles, rows.Err()
+}
+
+func (r *ContentRepository) ListUnrendered(ctx context.Context, field content.Field, limit int) ([]*content.Unrendered, error) {
+	cols, ok := contentColumns[field]
+	if !ok {
+		return nil, domain.ErrInvalidInput
+	}
+	query := fmt.Sprintf(`SELECT id, COALESCE(%s, '') FROM %s WHERE %s IS NULL AND %s LIMIT $1`,
+		cols.source, cols.table, cols.html, cols.filter)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pending []*content.Unrendered
+	for rows.Next() {
+		text := &content.Unrendered{}
+		if err := rows.Scan(&text.ID, &text.Source); err != nil {
+			return nil, err
+		}
+		pending = append(pending, text)
+	}
+	return pending, rows.Err()
+}
+
+func (r *ContentRepository) SaveRendered(ctx context.Context, field content.Field, id, html string) error {
+	cols, ok := contentColumns[field]
+	if !ok {
+		return domain.ErrInvalidInput
+	}
+	query := fmt.Sprintf(`UPDATE %s SET %s = $1 WHERE id = $2`, cols.table, cols.html)
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, html, id)
+	return err
+}
@@ -3,6 +3,8 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"time"
 
 	"github.com/yourusername/online-library/internal/domain"
 	"github.com/yourusername/online-library/internal/donation"
@@ -20,35 +22,251 @@ func NewDonationRepository(db *sql.DB, log *zap.Logger) *DonationRepository {
 	return &DonationRepository{db: db, log: log}
 }
 
+const donationColumns = `id, donor_id, donation_type, status, pledge, book_id, amount, currency, message, is_public, campaign_id,
+	received_by, received_at, catalogued_at, COALESCE(cancel_reason, ''), scored_at,
+	COALESCE(payment_provider, ''), COALESCE(payment_session_id, ''), COALESCE(payment_ref, ''),
+	confirmed_at, refunded_at, COALESCE(refund_id, ''), created_at`
+
 func (r *DonationRepository) Create(ctx context.Context, d *domain.Donation) error {
-	query := `INSERT INTO donations (id, donor_id, donation_type, book_id, amount, currency, message, is_public, created_at)
-	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
-	_, err := r.db.ExecContext(ctx, query, d.ID, d.DonorID, d.DonationType,
-		nullString(d.BookID), nullFloat64(d.Amount), d.Currency, d.Message, d.IsPublic, d.CreatedAt)
+	pledge, err := marshalPledge(d.Pledge)
+	if err != nil {
+		return err
+	}
+	query := `INSERT INTO donations (id, donor_id, donation_type, status, pledge, book_id, amount, currency, message, is_public,
+	                                 campaign_id, payment_provider, payment_session_id, created_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NULLIF($12, ''), NULLIF($13, ''), $14)`
+	_, err = conn(ctx, r.db).ExecContext(ctx, query, d.ID, d.DonorID, d.DonationType, d.Status, pledge,
+		nullString(d.BookID), nullFloat64(d.Amount), d.Currency, d.Message, d.IsPublic, nullString(d.CampaignID),
+		d.PaymentProvider, d.PaymentSessionID, d.CreatedAt)
 	return err
 }
 
+func (r *DonationRepository) GetByID(ctx context.Context, id string) (*domain.Donation, error) {
+	query := `SELECT ` + donationColumns + ` FROM donations WHERE id = $1`
+	d, err := scanDonation(conn(ctx, r.db).QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	return d, err
+}
+
+func (r *DonationRepository) GetByPaymentSession(ctx context.Context, provider, sessionID string) (*domain.Donation, error) {
+	query := `SELECT ` + donationColumns + ` FROM donations WHERE payment_provider = $1 AND payment_session_id = $2`
+	d, err := scanDonation(conn(ctx, r.db).QueryRowContext(ctx, query, provider, sessionID))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	return d, err
+}
+
+// List returns public donations that have been fulfilled; open pledges and
+// cancelled ones stay off the public list
 func (r *DonationRepository) List(ctx context.Context, limit, offset int) ([]*domain.Donation, error) {
-	query := `SELECT id, donor_id, donation_type, book_id, amount, currency, message, is_public, created_at
-	          FROM donations WHERE is_public = true ORDER BY created_at DESC LIMIT $1 OFFSET $2`
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	query := `SELECT ` + donationColumns + `
+	          FROM donations WHERE is_public = true AND status IN ('catalogued', 'confirmed')
+	          ORDER BY created_at DESC LIMIT $1 OFFSET $2`
+	return r.query(ctx, query, limit, offset)
+}
+
+func (r *DonationRepository) ListByDonor(ctx context.Context, donorID string) ([]*domain.Donation, error) {
+	query := `SELECT ` + donationColumns + ` FROM donations WHERE donor_id = $1 ORDER BY created_at DESC`
+	return r.query(ctx, query, donorID)
+}
+
+func (r *DonationRepository) ListByStatus(ctx context.Context, status domain.DonationStatus, limit, offset int) ([]*domain.Donation, error) {
+	query := `SELECT ` + donationColumns + `
+	          FROM donations WHERE donation_type = 'book' AND status = $1
+	          ORDER BY created_at ASC LIMIT $2 OFFSET $3`
+	return r.query(ctx, query, status, limit, offset)
+}
+
+func (r *DonationRepository) UpdateIntake(ctx context.Context, d *domain.Donation, from domain.DonationStatus) error {
+	query := `
+		UPDATE donations
+		SET status = $1, book_id = $2, received_by = $3, received_at = $4, catalogued_at = $5,
+		    cancel_reason = NULLIF($6, '')
+		WHERE id = $7 AND status = $8
+	`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, d.Status, nullString(d.BookID),
+		nullString(d.ReceivedBy), nullTime(d.ReceivedAt), nullTime(d.CataloguedAt), d.CancelReason, d.ID, from)
+	if err != nil {
+		return err
+	}
+	n, err := rowsAffected(result)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrDonationStatus
+	}
+	return nil
+}
+
+func (r *DonationRepository) UpdatePayment(ctx context.Context, d *domain.Donation, from domain.DonationStatus) error {
+	query := `
+		UPDATE donations
+		SET status = $1, payment_ref = NULLIF($2, ''), confirmed_at = $3, refunded_at = $4,
+		    refund_id = NULLIF($5, ''), cancel_reason = NULLIF($6, '')
+		WHERE id = $7 AND status = $8
+	`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, d.Status, d.PaymentRef, nullTime(d.ConfirmedAt),
+		nullTime(d.RefundedAt), d.RefundID, d.CancelReason, d.ID, from)
+	if err != nil {
+		return err
+	}
+	n, err := rowsAffected(result)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrDonationStatus
+	}
+	return nil
+}
+
+// wallFilter picks the donations shown on the donor wall, dated by when
+// they were fulfilled. $1 is the start of the period or NULL.
+const wallFilter = `d.is_public = true AND d.status IN ('confirmed', 'catalogued')
+	  AND ($1::timestamp IS NULL OR COALESCE(d.confirmed_at, d.catalogued_at, d.created_at) >= $1::timestamp)`
+
+func (r *DonationRepository) ListWall(ctx context.Context, since *time.Time, limit int) ([]*domain.WallDonation, error) {
+	query := `
+		SELECT d.id, d.donation_type, u.id, u.username, COALESCE(u.full_name, ''), COALESCE(u.avatar_url, ''),
+		       d.amount, COALESCE(d.currency, ''), COALESCE(d.message, ''), d.campaign_id,
+		       COALESCE(d.confirmed_at, d.catalogued_at, d.created_at),
+		       b.id, COALESCE(b.title, ''), COALESCE(b.author, ''), COALESCE(b.cover_url, '')
+		FROM donations d
+		JOIN users u ON u.id = d.donor_id
+		LEFT JOIN books b ON b.id = d.book_id
+		WHERE ` + wallFilter + `
+		ORDER BY 11 DESC
+		LIMIT $2
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, nullTime(since), limit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var donations []*domain.Donation
+	var donations []*domain.WallDonation
 	for rows.Next() {
-		d := &domain.Donation{}
-		var bookID sql.NullString
+		d := &domain.WallDonation{}
 		var amount sql.NullFloat64
-		err := rows.Scan(&d.ID, &d.DonorID, &d.DonationType, &bookID, &amount, &d.Currency, &d.Message, &d.IsPublic, &d.CreatedAt)
+		var campaignID, bookID sql.NullString
+		book := &domain.WallBook{}
+		err := rows.Scan(&d.ID, &d.DonationType, &d.Donor.UserID, &d.Donor.Username, &d.Donor.FullName,
+			&d.Donor.AvatarURL, &amount, &d.Currency, &d.Message, &campaignID, &d.DonatedAt,
+			&bookID, &book.Title, &book.Author, &book.CoverURL)
 		if err != nil {
 			return nil, err
 		}
-		d.BookID = stringPtr(bookID)
 		d.Amount = float64Ptr(amount)
+		d.CampaignID = stringPtr(campaignID)
+		if bookID.Valid {
+			book.ID = bookID.String
+			d.Book = book
+		}
 		donations = append(donations, d)
 	}
-	return donations, nil
+	return donations, rows.Err()
+}
+
+func (r *DonationRepository) TopDonors(ctx context.Context, since *time.Time, currency string, limit int) ([]*domain.TopDonor, error) {
+	query := `
+		SELECT u.id, u.username, COALESCE(u.full_name, ''), COALESCE(u.avatar_url, ''),
+		       COALESCE(SUM(d.amount) FILTER (WHERE d.donation_type = 'money' AND UPPER(d.currency) = $3), 0),
+		       COUNT(*) FILTER (WHERE d.donation_type = 'book'),
+		       COUNT(*)
+		FROM donations d
+		JOIN users u ON u.id = d.donor_id
+		WHERE ` + wallFilter + `
+		GROUP BY u.id, u.username, u.full_name, u.avatar_url
+		HAVING COALESCE(SUM(d.amount) FILTER (WHERE d.donation_type = 'money' AND UPPER(d.currency) = $3), 0) > 0
+		    OR COUNT(*) FILTER (WHERE d.donation_type = 'book') > 0
+		ORDER BY 5 DESC, 6 DESC, 7 DESC
+		LIMIT $2
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, nullTime(since), limit, currency)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var donors []*domain.TopDonor
+	for rows.Next() {
+		t := &domain.TopDonor{}
+		err := rows.Scan(&t.Donor.UserID, &t.Donor.Username, &t.Donor.FullName, &t.Donor.AvatarURL,
+			&t.Amount, &t.Books, &t.Donations)
+		if err != nil {
+			return nil, err
+		}
+		donors = append(donors, t)
+	}
+	return donors, rows.Err()
+}
+
+func (r *DonationRepository) MarkScored(ctx context.Context, donationID string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE donations SET scored_at = NOW() WHERE id = $1`, donationID)
+	return err
+}
+
+func (r *DonationRepository) query(ctx context.Context, query string, args ...interface{}) ([]*domain.Donation, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var donations []*domain.Donation
+	for rows.Next() {
+		d, err := scanDonation(rows)
+		if err != nil {
+			return nil, err
+		}
+		donations = append(donations, d)
+	}
+	return donations, rows.Err()
+}
+
+func scanDonation(row rowScanner) (*domain.Donation, error) {
+	d := &domain.Donation{}
+	var bookID, campaignID, receivedBy, currency, message sql.NullString
+	var amount sql.NullFloat64
+	var receivedAt, cataloguedAt, scoredAt, confirmedAt, refundedAt sql.NullTime
+	var pledge []byte
+	err := row.Scan(&d.ID, &d.DonorID, &d.DonationType, &d.Status, &pledge, &bookID, &amount, &currency,
+		&message, &d.IsPublic, &campaignID, &receivedBy, &receivedAt, &cataloguedAt, &d.CancelReason, &scoredAt,
+		&d.PaymentProvider, &d.PaymentSessionID, &d.PaymentRef, &confirmedAt, &refundedAt, &d.RefundID, &d.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if len(pledge) > 0 {
+		d.Pledge = &domain.BookPledge{}
+		if err := json.Unmarshal(pledge, d.Pledge); err != nil {
+			return nil, err
+		}
+	}
+	d.BookID = stringPtr(bookID)
+	d.Amount = float64Ptr(amount)
+	d.Currency = currency.String
+	d.Message = message.String
+	d.CampaignID = stringPtr(campaignID)
+	d.ReceivedBy = stringPtr(receivedBy)
+	d.ReceivedAt = timePtr(receivedAt)
+	d.CataloguedAt = timePtr(cataloguedAt)
+	d.ScoredAt = timePtr(scoredAt)
+	d.ConfirmedAt = timePtr(confirmedAt)
+	d.RefundedAt = timePtr(refundedAt)
+	return d, nil
+}
+
+func marshalPledge(p *domain.BookPledge) (interface{}, error) {
+	if p == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
 }
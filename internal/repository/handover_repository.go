@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/yourusername/online-library/internal/domain"
@@ -41,7 +42,7 @@ func (r *HandoverRepository) GetActiveReadingHistory(ctx context.Context, bookID
 		LIMIT 1
 	`
 
-	row := r.db.QueryRowContext(ctx, query, bookID)
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, bookID)
 
 	history := &domain.ReadingHistoryExtended{
 		ReadingHistory: &domain.ReadingHistory{
@@ -115,7 +116,7 @@ func (r *HandoverRepository) GetLastCompletedReadingHistory(ctx context.Context,
 		LIMIT 1
 	`
 
-	row := r.db.QueryRowContext(ctx, query, bookID)
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, bookID)
 
 	history := &domain.ReadingHistoryExtended{
 		ReadingHistory: &domain.ReadingHistory{
@@ -166,7 +167,7 @@ func (r *HandoverRepository) UpdateReadingHistoryCompleted(ctx context.Context,
 		SET is_completed = true, completed_at = $1, updated_at = NOW()
 		WHERE id = $2
 	`
-	_, err := r.db.ExecContext(ctx, query, completedAt, historyID)
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, completedAt, historyID)
 	return err
 }
 
@@ -176,7 +177,7 @@ func (r *HandoverRepository) UpdateReadingHistoryDeliveryStatus(ctx context.Cont
 		SET delivery_status = $1, marked_delivered_at = $2, updated_at = NOW()
 		WHERE id = $3
 	`
-	_, err := r.db.ExecContext(ctx, query, status, deliveredAt, historyID)
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, status, deliveredAt, historyID)
 	return err
 }
 
@@ -195,7 +196,7 @@ func (r *HandoverRepository) GetReadingHistoriesDueSoon(ctx context.Context, day
 		  AND (rh.next_reader_id IS NULL OR rh.next_reader_id = '')
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, daysThreshold)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, daysThreshold)
 	if err != nil {
 		return nil, err
 	}
@@ -241,7 +242,19 @@ func (r *HandoverRepository) UpdateReadingHistoryNextReader(ctx context.Context,
 		SET next_reader_id = $1, updated_at = NOW()
 		WHERE id = $2
 	`
-	_, err := r.db.ExecContext(ctx, query, nextReaderID, historyID)
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, nextReaderID, historyID)
+	return err
+}
+
+// ClearReadingHistoryNextReader detaches the next reader from a history and
+// resets its delivery status
+func (r *HandoverRepository) ClearReadingHistoryNextReader(ctx context.Context, historyID string) error {
+	query := `
+		UPDATE reading_history
+		SET next_reader_id = NULL, delivery_status = 'not_started', marked_delivered_at = NULL, updated_at = NOW()
+		WHERE id = $1
+	`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, historyID)
 	return err
 }
 
@@ -256,7 +269,7 @@ func (r *HandoverRepository) CreateHandoverThread(ctx context.Context, thread *d
 		) RETURNING id
 	`
 
-	return r.db.QueryRowContext(ctx, query,
+	return conn(ctx, r.db).QueryRowContext(ctx, query,
 		thread.BookID, thread.CurrentHolderID, thread.NextHolderID, thread.ReadingHistoryID,
 		thread.Status, thread.HandoverDueDate, thread.IsPublic, thread.CreatedAt, thread.UpdatedAt,
 	).Scan(&thread.ID)
@@ -268,6 +281,7 @@ func (r *HandoverRepository) GetHandoverThreadByID(ctx context.Context, threadID
 			ht.id, ht.book_id, ht.current_holder_id, ht.next_holder_id,
 			ht.reading_history_id, ht.status, ht.handover_due_date, ht.is_public,
 			ht.created_at, ht.completed_at, ht.updated_at,
+			ht.cancelled_by, COALESCE(ht.cancel_reason, ''), COALESCE(ht.cancel_note, ''), ht.at_fault_id, ht.cancelled_at,
 			b.title, b.author, b.cover_url,
 			u1.username as current_username, u1.full_name as current_full_name,
 			u2.username as next_username, u2.full_name as next_full_name
@@ -284,14 +298,15 @@ func (r *HandoverRepository) GetHandoverThreadByID(ctx context.Context, threadID
 		NextHolder:    &domain.User{},
 	}
 
-	var readingHistoryID sql.NullString
-	var completedAt sql.NullTime
+	var readingHistoryID, cancelledBy, atFaultID sql.NullString
+	var completedAt, cancelledAt sql.NullTime
 	var coverURL sql.NullString
 
-	err := r.db.QueryRowContext(ctx, query, threadID).Scan(
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, threadID).Scan(
 		&thread.ID, &thread.BookID, &thread.CurrentHolderID, &thread.NextHolderID,
 		&readingHistoryID, &thread.Status, &thread.HandoverDueDate, &thread.IsPublic,
 		&thread.CreatedAt, &completedAt, &thread.UpdatedAt,
+		&cancelledBy, &thread.CancelReason, &thread.CancelNote, &atFaultID, &cancelledAt,
 		&thread.Book.Title, &thread.Book.Author, &coverURL,
 		&thread.CurrentHolder.Username, &thread.CurrentHolder.FullName,
 		&thread.NextHolder.Username, &thread.NextHolder.FullName,
@@ -310,6 +325,9 @@ func (r *HandoverRepository) GetHandoverThreadByID(ctx context.Context, threadID
 	if completedAt.Valid {
 		thread.CompletedAt = &completedAt.Time
 	}
+	thread.CancelledBy = stringPtr(cancelledBy)
+	thread.AtFaultID = stringPtr(atFaultID)
+	thread.CancelledAt = timePtr(cancelledAt)
 	if coverURL.Valid {
 		thread.Book.CoverURL = coverURL.String
 	}
@@ -327,6 +345,7 @@ func (r *HandoverRepository) GetActiveHandoverThreadByBook(ctx context.Context,
 			ht.id, ht.book_id, ht.current_holder_id, ht.next_holder_id,
 			ht.reading_history_id, ht.status, ht.handover_due_date, ht.is_public,
 			ht.created_at, ht.completed_at, ht.updated_at,
+			ht.cancelled_by, COALESCE(ht.cancel_reason, ''), COALESCE(ht.cancel_note, ''), ht.at_fault_id, ht.cancelled_at,
 			b.title, b.author, b.cover_url,
 			u1.username as current_username, u1.full_name as current_full_name,
 			u2.username as next_username, u2.full_name as next_full_name
@@ -345,14 +364,15 @@ func (r *HandoverRepository) GetActiveHandoverThreadByBook(ctx context.Context,
 		NextHolder:    &domain.User{},
 	}
 
-	var readingHistoryID sql.NullString
-	var completedAt sql.NullTime
+	var readingHistoryID, cancelledBy, atFaultID sql.NullString
+	var completedAt, cancelledAt sql.NullTime
 	var coverURL sql.NullString
 
-	err := r.db.QueryRowContext(ctx, query, bookID).Scan(
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, bookID).Scan(
 		&thread.ID, &thread.BookID, &thread.CurrentHolderID, &thread.NextHolderID,
 		&readingHistoryID, &thread.Status, &thread.HandoverDueDate, &thread.IsPublic,
 		&thread.CreatedAt, &completedAt, &thread.UpdatedAt,
+		&cancelledBy, &thread.CancelReason, &thread.CancelNote, &atFaultID, &cancelledAt,
 		&thread.Book.Title, &thread.Book.Author, &coverURL,
 		&thread.CurrentHolder.Username, &thread.CurrentHolder.FullName,
 		&thread.NextHolder.Username, &thread.NextHolder.FullName,
@@ -371,6 +391,9 @@ func (r *HandoverRepository) GetActiveHandoverThreadByBook(ctx context.Context,
 	if completedAt.Valid {
 		thread.CompletedAt = &completedAt.Time
 	}
+	thread.CancelledBy = stringPtr(cancelledBy)
+	thread.AtFaultID = stringPtr(atFaultID)
+	thread.CancelledAt = timePtr(cancelledAt)
 	if coverURL.Valid {
 		thread.Book.CoverURL = coverURL.String
 	}
@@ -388,6 +411,7 @@ func (r *HandoverRepository) GetHandoverThreadsByUser(ctx context.Context, userI
 			ht.id, ht.book_id, ht.current_holder_id, ht.next_holder_id,
 			ht.status, ht.handover_due_date, ht.is_public,
 			ht.created_at, ht.completed_at,
+			COALESCE(ht.cancel_reason, ''), ht.cancelled_at,
 			b.title, b.author, b.cover_url,
 			u1.username as current_username, u1.full_name as current_full_name,
 			u2.username as next_username, u2.full_name as next_full_name
@@ -399,7 +423,7 @@ func (r *HandoverRepository) GetHandoverThreadsByUser(ctx context.Context, userI
 		ORDER BY ht.created_at DESC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, userID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -413,13 +437,14 @@ func (r *HandoverRepository) GetHandoverThreadsByUser(ctx context.Context, userI
 			NextHolder:    &domain.User{},
 		}
 
-		var completedAt sql.NullTime
+		var completedAt, cancelledAt sql.NullTime
 		var coverURL sql.NullString
 
 		err := rows.Scan(
 			&thread.ID, &thread.BookID, &thread.CurrentHolderID, &thread.NextHolderID,
 			&thread.Status, &thread.HandoverDueDate, &thread.IsPublic,
 			&thread.CreatedAt, &completedAt,
+			&thread.CancelReason, &cancelledAt,
 			&thread.Book.Title, &thread.Book.Author, &coverURL,
 			&thread.CurrentHolder.Username, &thread.CurrentHolder.FullName,
 			&thread.NextHolder.Username, &thread.NextHolder.FullName,
@@ -431,6 +456,7 @@ func (r *HandoverRepository) GetHandoverThreadsByUser(ctx context.Context, userI
 		if completedAt.Valid {
 			thread.CompletedAt = &completedAt.Time
 		}
+		thread.CancelledAt = timePtr(cancelledAt)
 		if coverURL.Valid {
 			thread.Book.CoverURL = coverURL.String
 		}
@@ -451,37 +477,89 @@ func (r *HandoverRepository) UpdateHandoverThreadStatus(ctx context.Context, thr
 		SET status = $1, completed_at = $2, updated_at = NOW()
 		WHERE id = $3
 	`
-	_, err := r.db.ExecContext(ctx, query, status, completedAt, threadID)
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, status, completedAt, threadID)
 	return err
 }
 
+// CancelHandoverThread records the cancellation set on thread. Only an
+// active thread can be cancelled, so two racing cancellations can't both
+// go through.
+func (r *HandoverRepository) CancelHandoverThread(ctx context.Context, thread *domain.HandoverThread) error {
+	query := `
+		UPDATE handover_threads
+		SET status = 'cancelled', cancelled_by = $2, cancel_reason = $3, cancel_note = NULLIF($4, ''),
+		    at_fault_id = $5, cancelled_at = $6, updated_at = NOW()
+		WHERE id = $1 AND status = 'active'
+	`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query,
+		thread.ID, thread.CancelledBy, thread.CancelReason, thread.CancelNote, thread.AtFaultID, thread.CancelledAt)
+	if err != nil {
+		return err
+	}
+	n, err := rowsAffected(result)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrHandoverNotActive
+	}
+	return nil
+}
+
 // Handover message operations
 func (r *HandoverRepository) CreateHandoverMessage(ctx context.Context, message *domain.HandoverMessage) error {
 	query := `
 		INSERT INTO handover_messages (
-			id, thread_id, user_id, message, is_system_message, created_at
+			id, thread_id, user_id, message, is_system_message, created_at, message_key, message_params, message_html
 		) VALUES (
-			gen_random_uuid(), $1, $2, $3, $4, $5
+			gen_random_uuid(), $1, $2, $3, $4, $5, NULLIF($6, ''), $7, NULLIF($8, '')
 		) RETURNING id
 	`
 
-	return r.db.QueryRowContext(ctx, query,
+	var params []byte
+	if len(message.MessageParams) > 0 {
+		var err error
+		if params, err = json.Marshal(message.MessageParams); err != nil {
+			return err
+		}
+	}
+
+	return conn(ctx, r.db).QueryRowContext(ctx, query,
 		message.ThreadID, message.UserID, message.Message, message.IsSystemMessage, message.CreatedAt,
+		message.MessageKey, params, message.MessageHTML,
 	).Scan(&message.ID)
 }
 
+func (r *HandoverRepository) GetUserLocale(ctx context.Context, userID string) (string, error) {
+	var locale string
+	err := conn(ctx, r.db).QueryRowContext(ctx, `SELECT preferred_locale FROM users WHERE id = $1`, userID).Scan(&locale)
+	if err == sql.ErrNoRows {
+		return "", domain.ErrUserNotFound
+	}
+	return locale, err
+}
+
 func (r *HandoverRepository) GetHandoverMessagesByThread(ctx context.Context, threadID string) ([]domain.HandoverMessage, error) {
+	return r.GetHandoverMessagesAfter(ctx, threadID, "")
+}
+
+// GetHandoverMessagesAfter returns the thread's messages that come after
+// afterID. An empty or unknown afterID returns the whole thread.
+func (r *HandoverRepository) GetHandoverMessagesAfter(ctx context.Context, threadID, afterID string) ([]domain.HandoverMessage, error) {
 	query := `
 		SELECT 
 			hm.id, hm.thread_id, hm.user_id, hm.message, hm.is_system_message, hm.created_at,
+			COALESCE(hm.message_key, ''), hm.message_params, COALESCE(hm.message_html, ''),
 			u.username, u.full_name, u.avatar_url
 		FROM handover_messages hm
 		LEFT JOIN users u ON hm.user_id = u.id
+		LEFT JOIN handover_messages prev ON prev.id = NULLIF($2, '')::uuid AND prev.thread_id = hm.thread_id
 		WHERE hm.thread_id = $1
-		ORDER BY hm.created_at ASC
+		  AND (prev.id IS NULL OR (hm.created_at, hm.id) > (prev.created_at, prev.id))
+		ORDER BY hm.created_at ASC, hm.id ASC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, threadID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, threadID, afterID)
 	if err != nil {
 		return nil, err
 	}
@@ -494,14 +572,21 @@ func (r *HandoverRepository) GetHandoverMessagesByThread(ctx context.Context, th
 		}
 
 		var avatarURL sql.NullString
+		var params []byte
 
 		err := rows.Scan(
 			&msg.ID, &msg.ThreadID, &msg.UserID, &msg.Message, &msg.IsSystemMessage, &msg.CreatedAt,
+			&msg.MessageKey, &params, &msg.MessageHTML,
 			&msg.User.Username, &msg.User.FullName, &avatarURL,
 		)
 		if err != nil {
 			return nil, err
 		}
+		if len(params) > 0 {
+			if err := json.Unmarshal(params, &msg.MessageParams); err != nil {
+				return nil, err
+			}
+		}
 
 		if avatarURL.Valid {
 			msg.User.AvatarURL = avatarURL.String
@@ -534,7 +619,7 @@ func (r *HandoverRepository) GetNextApprovedRequest(ctx context.Context, bookID
 
 	var processedAt, dueDate sql.NullTime
 
-	err := r.db.QueryRowContext(ctx, query, bookID).Scan(
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, bookID).Scan(
 		&req.ID, &req.BookID, &req.UserID, &req.Status, &req.PriorityScore,
 		&req.RequestedAt, &processedAt, &dueDate,
 		&req.User.Username, &req.User.FullName, &req.User.SuccessScore,
@@ -559,19 +644,30 @@ func (r *HandoverRepository) GetNextApprovedRequest(ctx context.Context, bookID
 	return req, nil
 }
 
-func (r *HandoverRepository) UpdateBookStatus(ctx context.Context, bookID string, status domain.BookStatus) error {
-	query := `UPDATE books SET status = $1, updated_at = NOW() WHERE id = $2`
-	_, err := r.db.ExecContext(ctx, query, status, bookID)
+// CancelApprovedRequest withdraws a user's approved request for a book so
+// the next one in the queue moves up
+func (r *HandoverRepository) CancelApprovedRequest(ctx context.Context, bookID, userID string) error {
+	query := `
+		UPDATE book_requests
+		SET status = 'cancelled', processed_at = NOW()
+		WHERE book_id = $1 AND user_id = $2 AND status = 'approved'
+	`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, bookID, userID)
 	return err
 }
 
+// LockBook holds the book row until the surrounding transaction ends
+func (r *HandoverRepository) LockBook(ctx context.Context, bookID string) error {
+	return lockBook(ctx, conn(ctx, r.db), bookID)
+}
+
 func (r *HandoverRepository) CloseReadingHistory(ctx context.Context, historyID string, endDate time.Time) error {
 	query := `
 		UPDATE reading_history 
 		SET end_date = $1, updated_at = NOW()
 		WHERE id = $2
 	`
-	_, err := r.db.ExecContext(ctx, query, endDate, historyID)
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, endDate, historyID)
 	return err
 }
 
@@ -583,12 +679,6 @@ func (r *HandoverRepository) StartNewReadingHistory(ctx context.Context, bookID,
 			gen_random_uuid(), $1, $2, NOW(), NOW(), NOW()
 		)
 	`
-	_, err := r.db.ExecContext(ctx, query, bookID, userID)
-	return err
-}
-
-func (r *HandoverRepository) AssignBookToUser(ctx context.Context, bookID, userID string) error {
-	query := `UPDATE books SET current_holder_id = $1, updated_at = NOW() WHERE id = $2`
-	_, err := r.db.ExecContext(ctx, query, userID, bookID)
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, bookID, userID)
 	return err
 }
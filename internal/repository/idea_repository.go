@@ -3,7 +3,9 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"time"
 
+	"github.com/lib/pq"
 	"github.com/yourusername/online-library/internal/domain"
 	"github.com/yourusername/online-library/internal/idea"
 	"go.uber.org/zap"
@@ -21,19 +23,20 @@ func NewIdeaRepository(db *sql.DB, log *zap.Logger) *IdeaRepository {
 }
 
 func (r *IdeaRepository) Create(ctx context.Context, i *domain.ReadingIdea) error {
-	query := `INSERT INTO reading_ideas (id, book_id, user_id, title, content, created_at, updated_at)
-	          VALUES ($1, $2, $3, $4, $5, $6, $7)`
-	_, err := r.db.ExecContext(ctx, query, i.ID, i.BookID, i.UserID, i.Title, i.Content, i.CreatedAt, i.UpdatedAt)
+	query := `INSERT INTO reading_ideas (id, book_id, user_id, title, content, content_html, created_at, updated_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	_, err := r.db.ExecContext(ctx, query, i.ID, i.BookID, i.UserID, i.Title, i.Content, i.ContentHTML, i.CreatedAt, i.UpdatedAt)
 	return err
 }
 
 func (r *IdeaRepository) FindByBookID(ctx context.Context, bookID string) ([]*domain.ReadingIdea, error) {
 	query := `
 		SELECT 
-			ri.id, ri.book_id, ri.user_id, ri.title, ri.content, 
+			ri.id, ri.book_id, ri.user_id, ri.title, ri.content, COALESCE(ri.content_html, ''),
 			COALESCE(ri.upvotes, 0), COALESCE(ri.downvotes, 0), 
-			ri.created_at, ri.updated_at,
-			u.username, u.full_name, u.avatar_url
+			ri.created_at, ri.updated_at, ri.edited_at,
+			u.username, u.full_name, u.avatar_url,
+			(SELECT COUNT(*) FROM idea_comments c WHERE c.idea_id = ri.id AND c.deleted_at IS NULL)
 		FROM reading_ideas ri
 		LEFT JOIN users u ON ri.user_id = u.id
 		WHERE ri.book_id = $1 
@@ -51,14 +54,16 @@ func (r *IdeaRepository) FindByBookID(ctx context.Context, bookID string) ([]*do
 			User: &domain.User{},
 		}
 		var avatarURL sql.NullString
+		var editedAt sql.NullTime
 		err := rows.Scan(
-			&i.ID, &i.BookID, &i.UserID, &i.Title, &i.Content,
-			&i.Upvotes, &i.Downvotes, &i.CreatedAt, &i.UpdatedAt,
-			&i.User.Username, &i.User.FullName, &avatarURL,
+			&i.ID, &i.BookID, &i.UserID, &i.Title, &i.Content, &i.ContentHTML,
+			&i.Upvotes, &i.Downvotes, &i.CreatedAt, &i.UpdatedAt, &editedAt,
+			&i.User.Username, &i.User.FullName, &avatarURL, &i.CommentCount,
 		)
 		if err != nil {
 			return nil, err
 		}
+		i.EditedAt = timePtr(editedAt)
 		if avatarURL.Valid {
 			i.User.AvatarURL = avatarURL.String
 		}
@@ -68,84 +73,508 @@ func (r *IdeaRepository) FindByBookID(ctx context.Context, bookID string) ([]*do
 	return ideas, nil
 }
 
-func (r *IdeaRepository) AddVote(ctx context.Context, vote *domain.IdeaVote) error {
+func (r *IdeaRepository) FindByID(ctx context.Context, id string) (*domain.ReadingIdea, error) {
+	query := `
+		SELECT id, book_id, user_id, title, content, COALESCE(content_html, ''),
+		       COALESCE(upvotes, 0), COALESCE(downvotes, 0), created_at, updated_at, edited_at
+		FROM reading_ideas WHERE id = $1
+	`
+	i := &domain.ReadingIdea{}
+	var editedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&i.ID, &i.BookID, &i.UserID, &i.Title, &i.Content, &i.ContentHTML,
+		&i.Upvotes, &i.Downvotes, &i.CreatedAt, &i.UpdatedAt, &editedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	i.EditedAt = timePtr(editedAt)
+	return i, nil
+}
+
+// ideaFeedOrder maps each feed to its ORDER BY. "best" is the lower bound
+// of the 95% Wilson score interval for the share of upvotes.
+var ideaFeedOrder = map[domain.IdeaFeedSort]string{
+	domain.IdeaFeedTop: `COALESCE(ri.upvotes, 0) - COALESCE(ri.downvotes, 0) DESC, ri.created_at DESC`,
+	domain.IdeaFeedNew: `ri.created_at DESC`,
+	domain.IdeaFeedBest: `CASE WHEN COALESCE(ri.upvotes, 0) + COALESCE(ri.downvotes, 0) = 0 THEN 0 ELSE
+		((COALESCE(ri.upvotes, 0) + 1.9208) / (COALESCE(ri.upvotes, 0) + COALESCE(ri.downvotes, 0))
+		 - 1.96 * SQRT((COALESCE(ri.upvotes, 0)::float * COALESCE(ri.downvotes, 0)) / (COALESCE(ri.upvotes, 0) + COALESCE(ri.downvotes, 0)) + 0.9604)
+		   / (COALESCE(ri.upvotes, 0) + COALESCE(ri.downvotes, 0)))
+		/ (1 + 3.8416 / (COALESCE(ri.upvotes, 0) + COALESCE(ri.downvotes, 0))) END DESC, ri.created_at DESC`,
+}
+
+func (r *IdeaRepository) Feed(ctx context.Context, sort domain.IdeaFeedSort, since *time.Time, limit, offset int) ([]*domain.ReadingIdea, error) {
+	order, ok := ideaFeedOrder[sort]
+	if !ok {
+		return nil, domain.ErrInvalidInput
+	}
+	query := `
+		SELECT
+			ri.id, ri.book_id, ri.user_id, ri.title, ri.content, COALESCE(ri.content_html, ''),
+			COALESCE(ri.upvotes, 0), COALESCE(ri.downvotes, 0),
+			ri.created_at, ri.updated_at, ri.edited_at,
+			u.username, COALESCE(u.full_name, ''), COALESCE(u.avatar_url, ''),
+			b.title, b.author, COALESCE(b.cover_url, ''),
+			(SELECT COUNT(*) FROM idea_comments c WHERE c.idea_id = ri.id AND c.deleted_at IS NULL)
+		FROM reading_ideas ri
+		JOIN users u ON ri.user_id = u.id
+		JOIN books b ON ri.book_id = b.id
+		WHERE $1::timestamp IS NULL OR ri.created_at >= $1::timestamp
+		ORDER BY ` + order + `
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.db.QueryContext(ctx, query, nullTime(since), limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ideas []*domain.ReadingIdea
+	for rows.Next() {
+		i := &domain.ReadingIdea{User: &domain.User{}, Book: &domain.Book{}}
+		var editedAt sql.NullTime
+		err := rows.Scan(
+			&i.ID, &i.BookID, &i.UserID, &i.Title, &i.Content, &i.ContentHTML,
+			&i.Upvotes, &i.Downvotes, &i.CreatedAt, &i.UpdatedAt, &editedAt,
+			&i.User.Username, &i.User.FullName, &i.User.AvatarURL,
+			&i.Book.Title, &i.Book.Author, &i.Book.CoverURL, &i.CommentCount,
+		)
+		if err != nil {
+			return nil, err
+		}
+		i.EditedAt = timePtr(editedAt)
+		i.User.ID = i.UserID
+		i.Book.ID = i.BookID
+		ideas = append(ideas, i)
+	}
+	return ideas, rows.Err()
+}
+
+func (r *IdeaRepository) Update(ctx context.Context, i *domain.ReadingIdea, revision *domain.IdeaRevision, editedBy string) error {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	// Check if user already voted
-	var existingVoteType string
-	err = tx.QueryRowContext(ctx, `SELECT vote_type FROM idea_votes WHERE idea_id = $1 AND user_id = $2`,
-		vote.IdeaID, vote.UserID).Scan(&existingVoteType)
+	_, err = tx.ExecContext(ctx, `INSERT INTO idea_revisions (id, idea_id, title, content, content_html, edited_by, created_at)
+	                              VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7)`,
+		revision.ID, revision.IdeaID, revision.Title, revision.Content, revision.ContentHTML, editedBy, revision.CreatedAt)
+	if err != nil {
+		return err
+	}
+	result, err := tx.ExecContext(ctx, `UPDATE reading_ideas SET title = $1, content = $2, content_html = $3, updated_at = $4, edited_at = $5
+	                                    WHERE id = $6`,
+		i.Title, i.Content, i.ContentHTML, i.UpdatedAt, nullTime(i.EditedAt), i.ID)
+	if err != nil {
+		return err
+	}
+	n, err := rowsAffected(result)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrNotFound
+	}
+	return tx.Commit()
+}
 
-	if err == sql.ErrNoRows {
-		// No existing vote, insert new one
-		_, err = tx.ExecContext(ctx, `INSERT INTO idea_votes (id, idea_id, user_id, vote_type, created_at) VALUES ($1, $2, $3, $4, $5)`,
-			vote.ID, vote.IdeaID, vote.UserID, vote.VoteType, vote.CreatedAt)
-		if err != nil {
-			return err
-		}
+func (r *IdeaRepository) ListRevisions(ctx context.Context, ideaID string) ([]*domain.IdeaRevision, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, idea_id, title, content, COALESCE(content_html, ''), created_at
+	                                     FROM idea_revisions WHERE idea_id = $1 ORDER BY created_at DESC`, ideaID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-		// Update vote counts
-		if vote.VoteType == domain.VoteTypeUp {
-			_, err = tx.ExecContext(ctx, `UPDATE reading_ideas SET upvotes = upvotes + 1 WHERE id = $1`, vote.IdeaID)
-		} else {
-			_, err = tx.ExecContext(ctx, `UPDATE reading_ideas SET downvotes = downvotes + 1 WHERE id = $1`, vote.IdeaID)
-		}
-		if err != nil {
-			return err
+	var revisions []*domain.IdeaRevision
+	for rows.Next() {
+		rev := &domain.IdeaRevision{}
+		if err := rows.Scan(&rev.ID, &rev.IdeaID, &rev.Title, &rev.Content, &rev.ContentHTML, &rev.CreatedAt); err != nil {
+			return nil, err
 		}
-	} else if err != nil {
+		revisions = append(revisions, rev)
+	}
+	return revisions, rows.Err()
+}
+
+func (r *IdeaRepository) Delete(ctx context.Context, id string) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM reading_ideas WHERE id = $1`, id)
+	if err != nil {
 		return err
-	} else {
-		// User already voted
-		if existingVoteType == string(vote.VoteType) {
-			// Same vote type, remove the vote (toggle off)
-			_, err = tx.ExecContext(ctx, `DELETE FROM idea_votes WHERE idea_id = $1 AND user_id = $2`, vote.IdeaID, vote.UserID)
+	}
+	n, err := rowsAffected(result)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *IdeaRepository) AddVote(ctx context.Context, vote *domain.IdeaVote) (*domain.IdeaVote, error) {
+	var previous *domain.IdeaVote
+	err := inTx(ctx, r.db, func(tx *sql.Tx) error {
+		// Check if user already voted
+		var existingVoteType string
+		var existingScore int
+		err := tx.QueryRowContext(ctx, `SELECT vote_type, score_awarded FROM idea_votes WHERE idea_id = $1 AND user_id = $2 FOR UPDATE`,
+			vote.IdeaID, vote.UserID).Scan(&existingVoteType, &existingScore)
+
+		if err == nil {
+			previous = &domain.IdeaVote{
+				IdeaID:       vote.IdeaID,
+				UserID:       vote.UserID,
+				VoteType:     domain.VoteType(existingVoteType),
+				ScoreAwarded: existingScore,
+			}
+		}
+
+		if err == sql.ErrNoRows {
+			// No existing vote, insert new one
+			_, err = tx.ExecContext(ctx, `INSERT INTO idea_votes (id, idea_id, user_id, vote_type, created_at) VALUES ($1, $2, $3, $4, $5)`,
+				vote.ID, vote.IdeaID, vote.UserID, vote.VoteType, vote.CreatedAt)
 			if err != nil {
 				return err
 			}
 
-			// Decrement vote count
+			// Update vote counts
 			if vote.VoteType == domain.VoteTypeUp {
-				_, err = tx.ExecContext(ctx, `UPDATE reading_ideas SET upvotes = GREATEST(upvotes - 1, 0) WHERE id = $1`, vote.IdeaID)
+				_, err = tx.ExecContext(ctx, `UPDATE reading_ideas SET upvotes = upvotes + 1 WHERE id = $1`, vote.IdeaID)
 			} else {
-				_, err = tx.ExecContext(ctx, `UPDATE reading_ideas SET downvotes = GREATEST(downvotes - 1, 0) WHERE id = $1`, vote.IdeaID)
+				_, err = tx.ExecContext(ctx, `UPDATE reading_ideas SET downvotes = downvotes + 1 WHERE id = $1`, vote.IdeaID)
 			}
 			if err != nil {
 				return err
 			}
+		} else if err != nil {
+			return err
 		} else {
-			// Different vote type, update the vote
-			_, err = tx.ExecContext(ctx, `UPDATE idea_votes SET vote_type = $1 WHERE idea_id = $2 AND user_id = $3`,
-				vote.VoteType, vote.IdeaID, vote.UserID)
-			if err != nil {
-				return err
-			}
+			// User already voted
+			if existingVoteType == string(vote.VoteType) {
+				// Same vote type, remove the vote (toggle off)
+				_, err = tx.ExecContext(ctx, `DELETE FROM idea_votes WHERE idea_id = $1 AND user_id = $2`, vote.IdeaID, vote.UserID)
+				if err != nil {
+					return err
+				}
 
-			// Update vote counts (decrement old, increment new)
-			if vote.VoteType == domain.VoteTypeUp {
-				_, err = tx.ExecContext(ctx, `UPDATE reading_ideas SET upvotes = upvotes + 1, downvotes = GREATEST(downvotes - 1, 0) WHERE id = $1`, vote.IdeaID)
+				// Decrement vote count
+				if vote.VoteType == domain.VoteTypeUp {
+					_, err = tx.ExecContext(ctx, `UPDATE reading_ideas SET upvotes = GREATEST(upvotes - 1, 0) WHERE id = $1`, vote.IdeaID)
+				} else {
+					_, err = tx.ExecContext(ctx, `UPDATE reading_ideas SET downvotes = GREATEST(downvotes - 1, 0) WHERE id = $1`, vote.IdeaID)
+				}
+				if err != nil {
+					return err
+				}
 			} else {
-				_, err = tx.ExecContext(ctx, `UPDATE reading_ideas SET downvotes = downvotes + 1, upvotes = GREATEST(upvotes - 1, 0) WHERE id = $1`, vote.IdeaID)
-			}
-			if err != nil {
-				return err
+				// Different vote type, update the vote
+				_, err = tx.ExecContext(ctx, `UPDATE idea_votes SET vote_type = $1, score_awarded = 0 WHERE idea_id = $2 AND user_id = $3`,
+					vote.VoteType, vote.IdeaID, vote.UserID)
+				if err != nil {
+					return err
+				}
+
+				// Update vote counts (decrement old, increment new)
+				if vote.VoteType == domain.VoteTypeUp {
+					_, err = tx.ExecContext(ctx, `UPDATE reading_ideas SET upvotes = upvotes + 1, downvotes = GREATEST(downvotes - 1, 0) WHERE id = $1`, vote.IdeaID)
+				} else {
+					_, err = tx.ExecContext(ctx, `UPDATE reading_ideas SET downvotes = downvotes + 1, upvotes = GREATEST(upvotes - 1, 0) WHERE id = $1`, vote.IdeaID)
+				}
+				if err != nil {
+					return err
+				}
 			}
 		}
-	}
+		return nil
+	})
+	return previous, err
+}
 
-	return tx.Commit()
+func (r *IdeaRepository) UpsertVote(ctx context.Context, vote *domain.IdeaVote) (*domain.IdeaVote, bool, error) {
+	var previous *domain.IdeaVote
+	var changed bool
+	err := inTx(ctx, r.db, func(tx *sql.Tx) error {
+		// Lock the current vote, if any, so its awarded points stay put
+		prev := &domain.IdeaVote{IdeaID: vote.IdeaID, UserID: vote.UserID}
+		err := tx.QueryRowContext(ctx, `SELECT id, vote_type, score_awarded, created_at FROM idea_votes
+		                               WHERE idea_id = $1 AND user_id = $2 FOR UPDATE`, vote.IdeaID, vote.UserID).
+			Scan(&prev.ID, &prev.VoteType, &prev.ScoreAwarded, &prev.CreatedAt)
+		if err == nil {
+			previous = prev
+		} else if err != sql.ErrNoRows {
+			return err
+		}
+
+		// A vote of the same type returns no row; a switch resets its points
+		var inserted bool
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO idea_votes (id, idea_id, user_id, vote_type, created_at) VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (idea_id, user_id) DO UPDATE SET vote_type = EXCLUDED.vote_type, score_awarded = 0
+			WHERE idea_votes.vote_type <> EXCLUDED.vote_type
+			RETURNING xmax = 0
+		`, vote.ID, vote.IdeaID, vote.UserID, vote.VoteType, vote.CreatedAt).Scan(&inserted)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case inserted && vote.VoteType == domain.VoteTypeUp:
+			_, err = tx.ExecContext(ctx, `UPDATE reading_ideas SET upvotes = upvotes + 1 WHERE id = $1`, vote.IdeaID)
+		case inserted:
+			_, err = tx.ExecContext(ctx, `UPDATE reading_ideas SET downvotes = downvotes + 1 WHERE id = $1`, vote.IdeaID)
+		case vote.VoteType == domain.VoteTypeUp:
+			_, err = tx.ExecContext(ctx, `UPDATE reading_ideas SET upvotes = upvotes + 1, downvotes = GREATEST(downvotes - 1, 0) WHERE id = $1`, vote.IdeaID)
+		default:
+			_, err = tx.ExecContext(ctx, `UPDATE reading_ideas SET downvotes = downvotes + 1, upvotes = GREATEST(upvotes - 1, 0) WHERE id = $1`, vote.IdeaID)
+		}
+		if err != nil {
+			return err
+		}
+		changed = true
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return previous, changed, nil
 }
 
-func (r *IdeaRepository) RemoveVote(ctx context.Context, ideaID, userID string) error {
-	_, err := r.db.ExecContext(ctx, `DELETE FROM idea_votes WHERE idea_id = $1 AND user_id = $2`, ideaID, userID)
-	return err
+func (r *IdeaRepository) RemoveVote(ctx context.Context, ideaID, userID string) (*domain.IdeaVote, error) {
+	var removed *domain.IdeaVote
+	err := inTx(ctx, r.db, func(tx *sql.Tx) error {
+		vote := &domain.IdeaVote{IdeaID: ideaID, UserID: userID}
+		err := tx.QueryRowContext(ctx, `DELETE FROM idea_votes WHERE idea_id = $1 AND user_id = $2
+		                               RETURNING id, vote_type, score_awarded, created_at`, ideaID, userID).
+			Scan(&vote.ID, &vote.VoteType, &vote.ScoreAwarded, &vote.CreatedAt)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if vote.VoteType == domain.VoteTypeUp {
+			_, err = tx.ExecContext(ctx, `UPDATE reading_ideas SET upvotes = GREATEST(upvotes - 1, 0) WHERE id = $1`, ideaID)
+		} else {
+			_, err = tx.ExecContext(ctx, `UPDATE reading_ideas SET downvotes = GREATEST(downvotes - 1, 0) WHERE id = $1`, ideaID)
+		}
+		if err != nil {
+			return err
+		}
+		removed = vote
+		return nil
+	})
+	return removed, err
 }
 
 func (r *IdeaRepository) UpdateVoteCounts(ctx context.Context, ideaID string, upvotes, downvotes int) error {
 	_, err := r.db.ExecContext(ctx, `UPDATE reading_ideas SET upvotes = $1, downvotes = $2 WHERE id = $3`, upvotes, downvotes, ideaID)
 	return err
 }
+
+func (r *IdeaRepository) SetVoteScore(ctx context.Context, ideaID, userID string, points int) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE idea_votes SET score_awarded = $1 WHERE idea_id = $2 AND user_id = $3`, points, ideaID, userID)
+	return err
+}
+
+func (r *IdeaRepository) ListUpvoteEdges(ctx context.Context, since time.Time, minVotes int) ([]*idea.VoteEdge, error) {
+	query := `
+		SELECT v.user_id, ri.user_id, COUNT(*)
+		FROM idea_votes v
+		JOIN reading_ideas ri ON v.idea_id = ri.id
+		WHERE v.vote_type = 'upvote' AND v.created_at >= $1 AND v.user_id <> ri.user_id
+		GROUP BY v.user_id, ri.user_id
+		HAVING COUNT(*) >= $2
+	`
+	rows, err := r.db.QueryContext(ctx, query, since, minVotes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var edges []*idea.VoteEdge
+	for rows.Next() {
+		e := &idea.VoteEdge{}
+		if err := rows.Scan(&e.VoterID, &e.AuthorID, &e.Votes); err != nil {
+			return nil, err
+		}
+		edges = append(edges, e)
+	}
+	return edges, nil
+}
+
+// SaveVoteRing inserts a new pending flag, or refreshes the open flag for
+// the same member set so repeated detection runs do not pile up duplicates.
+func (r *IdeaRepository) SaveVoteRing(ctx context.Context, ring *domain.VoteRing, ringKey string) error {
+	query := `
+		INSERT INTO vote_rings (ring_key, member_ids, vote_count, status, detected_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (ring_key) WHERE status = 'pending'
+		DO UPDATE SET vote_count = EXCLUDED.vote_count, detected_at = EXCLUDED.detected_at
+		RETURNING id
+	`
+	return r.db.QueryRowContext(ctx, query,
+		ringKey, pq.Array(ring.MemberIDs), ring.VoteCount, ring.Status, ring.DetectedAt,
+	).Scan(&ring.ID)
+}
+
+func (r *IdeaRepository) ListVoteRings(ctx context.Context, status domain.VoteRingStatus) ([]*domain.VoteRing, error) {
+	query := `
+		SELECT vr.id, vr.member_ids,
+		       ARRAY(SELECT u.username FROM users u WHERE u.id = ANY(vr.member_ids) ORDER BY u.username),
+		       vr.vote_count, vr.status, vr.detected_at, vr.reviewed_by, vr.reviewed_at
+		FROM vote_rings vr
+		WHERE vr.status = $1
+		ORDER BY vr.detected_at DESC
+	`
+	rows, err := r.db.QueryContext(ctx, query, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rings []*domain.VoteRing
+	for rows.Next() {
+		ring := &domain.VoteRing{}
+		var reviewedBy sql.NullString
+		var reviewedAt sql.NullTime
+		err := rows.Scan(
+			&ring.ID, pq.Array(&ring.MemberIDs), pq.Array(&ring.Usernames),
+			&ring.VoteCount, &ring.Status, &ring.DetectedAt, &reviewedBy, &reviewedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		ring.ReviewedBy = stringPtr(reviewedBy)
+		ring.ReviewedAt = timePtr(reviewedAt)
+		rings = append(rings, ring)
+	}
+	return rings, nil
+}
+
+func (r *IdeaRepository) UpdateVoteRingStatus(ctx context.Context, ringID, adminID string, status domain.VoteRingStatus) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE vote_rings SET status = $1, reviewed_by = $2, reviewed_at = CURRENT_TIMESTAMP
+		WHERE id = $3 AND status = 'pending'
+	`, status, adminID, ringID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+const ideaCommentColumns = `c.id, c.idea_id, c.parent_id, c.user_id, u.username, c.depth, c.content,
+	COALESCE(c.content_html, ''), c.edited_at, c.deleted_at, c.created_at`
+
+func (r *IdeaRepository) CreateComment(ctx context.Context, c *domain.IdeaComment) error {
+	query := `INSERT INTO idea_comments (id, idea_id, parent_id, user_id, depth, content, content_html, created_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	_, err := r.db.ExecContext(ctx, query, c.ID, c.IdeaID, nullString(c.ParentID), c.UserID, c.Depth, c.Content,
+		c.ContentHTML, c.CreatedAt)
+	return err
+}
+
+func (r *IdeaRepository) FindCommentByID(ctx context.Context, id string) (*domain.IdeaComment, error) {
+	query := `SELECT ` + ideaCommentColumns + `
+	          FROM idea_comments c JOIN users u ON u.id = c.user_id
+	          WHERE c.id = $1`
+	c, err := scanIdeaComment(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	return c, err
+}
+
+func (r *IdeaRepository) ListComments(ctx context.Context, ideaID string) ([]*domain.IdeaComment, error) {
+	query := `SELECT ` + ideaCommentColumns + `
+	          FROM idea_comments c JOIN users u ON u.id = c.user_id
+	          WHERE c.idea_id = $1
+	          ORDER BY c.created_at ASC`
+	rows, err := r.db.QueryContext(ctx, query, ideaID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var comments []*domain.IdeaComment
+	for rows.Next() {
+		c, err := scanIdeaComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, c)
+	}
+	return comments, rows.Err()
+}
+
+func (r *IdeaRepository) UpdateComment(ctx context.Context, c *domain.IdeaComment) error {
+	result, err := r.db.ExecContext(ctx, `UPDATE idea_comments SET content = $1, content_html = $2, edited_at = $3
+	                                      WHERE id = $4 AND deleted_at IS NULL`,
+		c.Content, c.ContentHTML, nullTime(c.EditedAt), c.ID)
+	if err != nil {
+		return err
+	}
+	n, err := rowsAffected(result)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *IdeaRepository) SoftDeleteComment(ctx context.Context, id, deletedBy string, deletedAt time.Time) error {
+	result, err := r.db.ExecContext(ctx, `UPDATE idea_comments SET deleted_at = $1, deleted_by = $2 WHERE id = $3 AND deleted_at IS NULL`,
+		deletedAt, deletedBy, id)
+	if err != nil {
+		return err
+	}
+	n, err := rowsAffected(result)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *IdeaRepository) HasReadBook(ctx context.Context, userID, bookID string) (bool, error) {
+	var read bool
+	err := r.db.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM reading_history WHERE reader_id = $1 AND book_id = $2)`,
+		userID, bookID).Scan(&read)
+	return read, err
+}
+
+func scanIdeaComment(row rowScanner) (*domain.IdeaComment, error) {
+	c := &domain.IdeaComment{}
+	var parentID sql.NullString
+	var editedAt, deletedAt sql.NullTime
+	err := row.Scan(&c.ID, &c.IdeaID, &parentID, &c.UserID, &c.Username, &c.Depth, &c.Content,
+		&c.ContentHTML, &editedAt, &deletedAt, &c.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	c.ParentID = stringPtr(parentID)
+	c.EditedAt = timePtr(editedAt)
+	c.DeletedAt = timePtr(deletedAt)
+	return c, nil
+}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/yourusername/online-library/internal/domain"
+	"github.com/yourusername/online-library/internal/donation"
+	"go.uber.org/zap"
+)
+
+type LedgerRepository struct {
+	db  *sql.DB
+	log *zap.Logger
+}
+
+var _ donation.LedgerRepo = (*LedgerRepository)(nil)
+
+func NewLedgerRepository(db *sql.DB, log *zap.Logger) *LedgerRepository {
+	return &LedgerRepository{db: db, log: log}
+}
+
+const outflowColumns = `id, amount, currency, category, description, spent_on, campaign_id,
+	COALESCE(recorded_by::text, ''), voided_at, voided_by, COALESCE(void_reason, ''), created_at`
+
+func (r *LedgerRepository) MonthlyInflows(ctx context.Context, from, to time.Time) ([]*donation.LedgerTotal, error) {
+	query := `
+		SELECT TO_CHAR(confirmed_at, 'YYYY-MM'), UPPER(currency), SUM(amount), COUNT(*)
+		FROM donations
+		WHERE donation_type = 'money' AND status = 'confirmed'
+		  AND confirmed_at >= $1 AND confirmed_at < $2
+		GROUP BY 1, 2
+		ORDER BY 1, 2
+	`
+	return r.totals(ctx, query, from, to)
+}
+
+func (r *LedgerRepository) MonthlyBooks(ctx context.Context, from, to time.Time) (map[string]int, error) {
+	query := `
+		SELECT TO_CHAR(catalogued_at, 'YYYY-MM'), COUNT(*)
+		FROM donations
+		WHERE donation_type = 'book' AND status = 'catalogued'
+		  AND catalogued_at >= $1 AND catalogued_at < $2
+		GROUP BY 1
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	books := make(map[string]int)
+	for rows.Next() {
+		var month string
+		var n int
+		if err := rows.Scan(&month, &n); err != nil {
+			return nil, err
+		}
+		books[month] = n
+	}
+	return books, rows.Err()
+}
+
+func (r *LedgerRepository) MonthlyOutflows(ctx context.Context, from, to time.Time) ([]*donation.LedgerTotal, error) {
+	query := `
+		SELECT TO_CHAR(spent_on, 'YYYY-MM'), currency, SUM(amount), COUNT(*)
+		FROM ledger_outflows
+		WHERE voided_at IS NULL AND spent_on >= $1::date AND spent_on < $2::date
+		GROUP BY 1, 2
+		ORDER BY 1, 2
+	`
+	return r.totals(ctx, query, from, to)
+}
+
+func (r *LedgerRepository) ListOutflows(ctx context.Context, from, to time.Time) ([]*domain.LedgerOutflow, error) {
+	query := `SELECT ` + outflowColumns + `
+	          FROM ledger_outflows
+	          WHERE spent_on >= $1::date AND spent_on < $2::date
+	          ORDER BY spent_on ASC, created_at ASC`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var outflows []*domain.LedgerOutflow
+	for rows.Next() {
+		o, err := scanOutflow(rows)
+		if err != nil {
+			return nil, err
+		}
+		outflows = append(outflows, o)
+	}
+	return outflows, rows.Err()
+}
+
+func (r *LedgerRepository) CreateOutflow(ctx context.Context, o *domain.LedgerOutflow) error {
+	query := `
+		INSERT INTO ledger_outflows (id, amount, currency, category, description, spent_on, campaign_id,
+		                             recorded_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, o.ID, o.Amount, o.Currency, o.Category, o.Description,
+		o.SpentOn, nullString(o.CampaignID), o.RecordedBy, o.CreatedAt)
+	return err
+}
+
+func (r *LedgerRepository) GetOutflow(ctx context.Context, id string) (*domain.LedgerOutflow, error) {
+	query := `SELECT ` + outflowColumns + ` FROM ledger_outflows WHERE id = $1`
+	o, err := scanOutflow(conn(ctx, r.db).QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	return o, err
+}
+
+func (r *LedgerRepository) VoidOutflow(ctx context.Context, o *domain.LedgerOutflow) error {
+	query := `
+		UPDATE ledger_outflows
+		SET voided_at = $1, voided_by = $2, void_reason = NULLIF($3, '')
+		WHERE id = $4 AND voided_at IS NULL
+	`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, nullTime(o.VoidedAt), nullString(o.VoidedBy),
+		o.VoidReason, o.ID)
+	if err != nil {
+		return err
+	}
+	n, err := rowsAffected(result)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrOutflowVoided
+	}
+	return nil
+}
+
+func (r *LedgerRepository) totals(ctx context.Context, query string, args ...interface{}) ([]*donation.LedgerTotal, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var totals []*donation.LedgerTotal
+	for rows.Next() {
+		t := &donation.LedgerTotal{}
+		if err := rows.Scan(&t.Month, &t.Currency, &t.Amount, &t.Count); err != nil {
+			return nil, err
+		}
+		totals = append(totals, t)
+	}
+	return totals, rows.Err()
+}
+
+func scanOutflow(row rowScanner) (*domain.LedgerOutflow, error) {
+	o := &domain.LedgerOutflow{}
+	var campaignID, voidedBy sql.NullString
+	var voidedAt sql.NullTime
+	err := row.Scan(&o.ID, &o.Amount, &o.Currency, &o.Category, &o.Description, &o.SpentOn, &campaignID,
+		&o.RecordedBy, &voidedAt, &voidedBy, &o.VoidReason, &o.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	o.CampaignID = stringPtr(campaignID)
+	o.VoidedAt = timePtr(voidedAt)
+	o.VoidedBy = stringPtr(voidedBy)
+	return o, nil
+}
@@ -5,6 +5,11 @@ import (
 	"time"
 )
 
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
 // Helper functions to convert between sql.Null* and pointers
 
 func stringPtr(s sql.NullString) *string {
@@ -63,3 +68,9 @@ func nullTime(t *time.Time) sql.NullTime {
 	}
 	return sql.NullTime{}
 }
+
+// rowsAffected reports how many rows an Exec changed
+func rowsAffected(result sql.Result) (int, error) {
+	n, err := result.RowsAffected()
+	return int(n), err
+}
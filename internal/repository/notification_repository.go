@@ -3,7 +3,10 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"time"
 
+	"github.com/lib/pq"
 	"github.com/yourusername/online-library/internal/domain"
 	"github.com/yourusername/online-library/internal/notification"
 	"go.uber.org/zap"
@@ -20,45 +23,459 @@ func NewNotificationRepository(db *sql.DB, log *zap.Logger) *NotificationReposit
 	return &NotificationRepository{db: db, log: log}
 }
 
-func (r *NotificationRepository) Create(ctx context.Context, userID, notifType, title, message, link string) error {
-	_, err := r.db.ExecContext(ctx, `INSERT INTO notifications (user_id, type, title, message, link, created_at)
-	                                  VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP)`,
-		userID, notifType, title, message, link)
+func (r *NotificationRepository) Create(ctx context.Context, n *domain.Notification) error {
+	payload, err := json.Marshal(n.Payload)
+	if err != nil {
+		return err
+	}
+	return conn(ctx, r.db).QueryRowContext(ctx, `INSERT INTO notifications (user_id, type, version, title, message, link, payload, created_at)
+	                                  VALUES ($1, $2, $3, $4, $5, $6, $7, CURRENT_TIMESTAMP)
+	                                  RETURNING id, COALESCE(is_read, false), created_at`,
+		n.UserID, n.Type, n.Version, n.Title, n.Message, n.Link, payload).Scan(&n.ID, &n.IsRead, &n.CreatedAt)
+}
+
+// Announce issues NOTIFY on the caller's transaction, so subscribers only
+// hear of a notification once it has been committed
+func (r *NotificationRepository) Announce(ctx context.Context, channel, payload string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `SELECT pg_notify($1, $2)`, channel, payload)
 	return err
 }
 
-func (r *NotificationRepository) GetByUserID(ctx context.Context, userID string, limit int) ([]*domain.Notification, error) {
+// GetByUserID returns the user's newest notifications from the inbox, or
+// from the archive if archived is set
+func (r *NotificationRepository) GetByUserID(ctx context.Context, userID string, archived bool, limit int) ([]*domain.Notification, error) {
+	query := `
+		SELECT ` + notificationColumns + `
+		FROM notifications n
+		WHERE n.user_id = $1 AND (n.archived_at IS NOT NULL) = $2
+		ORDER BY n.created_at DESC
+		LIMIT $3
+	`
+	rows, err := r.db.QueryContext(ctx, query, userID, archived, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanNotifications(rows)
+}
+
+// GetAfter returns up to limit of the user's unarchived notifications
+// created after afterID, oldest first. An unknown afterID returns the
+// oldest ones.
+func (r *NotificationRepository) GetAfter(ctx context.Context, userID, afterID string, limit int) ([]*domain.Notification, error) {
 	query := `
-		SELECT id, user_id, type, title, message, COALESCE(link, ''), is_read, created_at
-		FROM notifications
+		SELECT ` + notificationColumns + `
+		FROM notifications n
+		LEFT JOIN notifications prev ON prev.id = NULLIF($2, '')::uuid AND prev.user_id = n.user_id
+		WHERE n.user_id = $1 AND n.archived_at IS NULL
+		  AND (prev.id IS NULL OR (n.created_at, n.id) > (prev.created_at, prev.id))
+		ORDER BY n.created_at ASC, n.id ASC
+		LIMIT $3
+	`
+	rows, err := r.db.QueryContext(ctx, query, userID, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanNotifications(rows)
+}
+
+func (r *NotificationRepository) CountUnread(ctx context.Context, userID string) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND is_read = FALSE AND archived_at IS NULL`, userID).Scan(&count)
+	return count, err
+}
+
+func (r *NotificationRepository) MarkAllAsRead(ctx context.Context, userID string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE notifications SET is_read = true WHERE user_id = $1 AND is_read = false`, userID)
+	return err
+}
+
+func (r *NotificationRepository) SetRead(ctx context.Context, userID string, ids []string, read bool) (int, error) {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE notifications SET is_read = $3 WHERE user_id = $1 AND id = ANY($2::uuid[])`,
+		userID, pq.Array(ids), read)
+	if err != nil {
+		return 0, err
+	}
+	return rowsAffected(result)
+}
+
+func (r *NotificationRepository) SetArchived(ctx context.Context, userID string, ids []string, archived bool) (int, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE notifications
+		SET archived_at = CASE WHEN $3 THEN COALESCE(archived_at, CURRENT_TIMESTAMP) END
+		WHERE user_id = $1 AND id = ANY($2::uuid[])
+	`, userID, pq.Array(ids), archived)
+	if err != nil {
+		return 0, err
+	}
+	return rowsAffected(result)
+}
+
+func (r *NotificationRepository) Delete(ctx context.Context, userID string, ids []string) (int, error) {
+	result, err := r.db.ExecContext(ctx,
+		`DELETE FROM notifications WHERE user_id = $1 AND id = ANY($2::uuid[])`,
+		userID, pq.Array(ids))
+	if err != nil {
+		return 0, err
+	}
+	return rowsAffected(result)
+}
+
+func (r *NotificationRepository) DeleteReadBefore(ctx context.Context, cutoff time.Time, limit int) (int, error) {
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM notifications
+		WHERE id IN (
+			SELECT id FROM notifications
+			WHERE is_read = true AND created_at < $1
+			LIMIT $2
+		)
+	`, cutoff, limit)
+	if err != nil {
+		return 0, err
+	}
+	return rowsAffected(result)
+}
+
+func (r *NotificationRepository) GetPreferences(ctx context.Context, userID string) ([]*domain.NotificationPreference, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT type, channel, enabled FROM notification_preferences WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var prefs []*domain.NotificationPreference
+	for rows.Next() {
+		p := &domain.NotificationPreference{}
+		if err := rows.Scan(&p.Type, &p.Channel, &p.Enabled); err != nil {
+			return nil, err
+		}
+		prefs = append(prefs, p)
+	}
+	return prefs, nil
+}
+
+func (r *NotificationRepository) SavePreferences(ctx context.Context, userID string, prefs []*domain.NotificationPreference) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, p := range prefs {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO notification_preferences (user_id, type, channel, enabled, updated_at)
+			VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP)
+			ON CONFLICT (user_id, type, channel) DO UPDATE
+			SET enabled = EXCLUDED.enabled, updated_at = CURRENT_TIMESTAMP
+		`, userID, p.Type, p.Channel, p.Enabled)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *NotificationRepository) GetSettings(ctx context.Context, userID string) (*domain.NotificationSettings, error) {
+	s := &domain.NotificationSettings{UserID: userID}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COALESCE(sms_number, ''),
+		       COALESCE(to_char(quiet_hours_start, 'HH24:MI'), ''),
+		       COALESCE(to_char(quiet_hours_end, 'HH24:MI'), ''),
+		       timezone, digest_frequency
+		FROM notification_settings
 		WHERE user_id = $1
+	`, userID).Scan(&s.SMSNumber, &s.QuietHoursStart, &s.QuietHoursEnd, &s.Timezone, &s.DigestFrequency)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (r *NotificationRepository) SaveSettings(ctx context.Context, s *domain.NotificationSettings) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO notification_settings (user_id, sms_number, quiet_hours_start, quiet_hours_end, timezone, digest_frequency, updated_at)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, '')::time, NULLIF($4, '')::time, $5, $6, CURRENT_TIMESTAMP)
+		ON CONFLICT (user_id) DO UPDATE
+		SET sms_number = EXCLUDED.sms_number,
+		    quiet_hours_start = EXCLUDED.quiet_hours_start,
+		    quiet_hours_end = EXCLUDED.quiet_hours_end,
+		    timezone = EXCLUDED.timezone,
+		    digest_frequency = EXCLUDED.digest_frequency,
+		    updated_at = CURRENT_TIMESTAMP
+	`, s.UserID, s.SMSNumber, s.QuietHoursStart, s.QuietHoursEnd, s.Timezone, s.DigestFrequency)
+	return err
+}
+
+// AddPushToken registers a device token. A token that moves to another
+// account (shared device, new login) is reassigned.
+func (r *NotificationRepository) AddPushToken(ctx context.Context, userID, token, platform string) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO push_subscriptions (user_id, token, platform)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (token) DO UPDATE
+		SET user_id = EXCLUDED.user_id, platform = EXCLUDED.platform, created_at = CURRENT_TIMESTAMP
+	`, userID, token, platform)
+	return err
+}
+
+func (r *NotificationRepository) RemovePushToken(ctx context.Context, userID, token string) error {
+	_, err := r.db.ExecContext(ctx,
+		`DELETE FROM push_subscriptions WHERE user_id = $1 AND token = $2`, userID, token)
+	return err
+}
+
+func (r *NotificationRepository) GetRecipient(ctx context.Context, userID string) (*notification.Recipient, error) {
+	to := &notification.Recipient{UserID: userID}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COALESCE(NULLIF(u.full_name, ''), u.username), u.preferred_locale, u.email, COALESCE(ns.sms_number, ''),
+		       ARRAY(SELECT token FROM push_subscriptions WHERE user_id = u.id ORDER BY created_at)
+		FROM users u
+		LEFT JOIN notification_settings ns ON ns.user_id = u.id
+		WHERE u.id = $1
+	`, userID).Scan(&to.Name, &to.Locale, &to.Email, &to.SMSNumber, pq.Array(&to.PushTokens))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return to, nil
+}
+
+func (r *NotificationRepository) GetUserLocale(ctx context.Context, userID string) (string, error) {
+	var locale string
+	err := r.db.QueryRowContext(ctx, `SELECT preferred_locale FROM users WHERE id = $1`, userID).Scan(&locale)
+	if err == sql.ErrNoRows {
+		return "", domain.ErrUserNotFound
+	}
+	return locale, err
+}
+
+func (r *NotificationRepository) GetUsername(ctx context.Context, userID string) (string, error) {
+	var username string
+	err := r.db.QueryRowContext(ctx, `SELECT username FROM users WHERE id = $1`, userID).Scan(&username)
+	if err == sql.ErrNoRows {
+		return "", domain.ErrUserNotFound
+	}
+	return username, err
+}
+
+func (r *NotificationRepository) EnqueueDelivery(ctx context.Context, d *domain.NotificationDelivery) error {
+	return conn(ctx, r.db).QueryRowContext(ctx, `
+		INSERT INTO notification_outbox (notification_id, user_id, channel, type, title, message, link, next_attempt_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, status, created_at
+	`, nullString(d.NotificationID), d.UserID, d.Channel, d.Type, d.Title, d.Message, d.Link, d.NextAttemptAt,
+	).Scan(&d.ID, &d.Status, &d.CreatedAt)
+}
+
+func (r *NotificationRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*domain.NotificationDelivery, error) {
+	now := time.Now().UTC()
+	rows, err := r.db.QueryContext(ctx, `
+		UPDATE notification_outbox
+		SET attempts = attempts + 1, next_attempt_at = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id IN (
+			SELECT id FROM notification_outbox
+			WHERE status = 'pending' AND next_attempt_at <= $1
+			ORDER BY next_attempt_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+deliveryColumns,
+		now, now.Add(lease), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanDeliveries(rows)
+}
+
+func (r *NotificationRepository) MarkDeliverySent(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE notification_outbox
+		SET status = 'sent', sent_at = CURRENT_TIMESTAMP, last_error = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, id)
+	return err
+}
+
+func (r *NotificationRepository) MarkDeliveryFailed(ctx context.Context, id, lastError string, retryAt *time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE notification_outbox
+		SET status = CASE WHEN $3::timestamp IS NULL THEN 'failed' ELSE 'pending' END,
+		    next_attempt_at = COALESCE($3::timestamp, next_attempt_at),
+		    last_error = $2,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, id, lastError, nullTime(retryAt))
+	return err
+}
+
+func (r *NotificationRepository) ListDeliveries(ctx context.Context, status domain.OutboxStatus, limit int) ([]*domain.NotificationDelivery, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+deliveryColumns+`
+		FROM notification_outbox
+		WHERE $1 = '' OR status = $1
 		ORDER BY created_at DESC
 		LIMIT $2
-	`
-	rows, err := r.db.QueryContext(ctx, query, userID, limit)
+	`, status, limit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
+	return scanDeliveries(rows)
+}
+
+func (r *NotificationRepository) EnqueueDigestItem(ctx context.Context, item *domain.DigestItem) error {
+	return conn(ctx, r.db).QueryRowContext(ctx, `
+		INSERT INTO notification_digest_items (user_id, type, title, message, link)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`, item.UserID, item.Type, item.Title, item.Message, item.Link).Scan(&item.ID, &item.CreatedAt)
+}
+
+func (r *NotificationRepository) ListDigestSettings(ctx context.Context) ([]*domain.NotificationSettings, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT ns.user_id, ns.timezone, ns.digest_frequency
+		FROM notification_settings ns
+		WHERE EXISTS (
+			SELECT 1 FROM notification_digest_items di
+			WHERE di.user_id = ns.user_id AND di.digest_id IS NULL
+		)
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var settings []*domain.NotificationSettings
+	for rows.Next() {
+		s := &domain.NotificationSettings{}
+		if err := rows.Scan(&s.UserID, &s.Timezone, &s.DigestFrequency); err != nil {
+			return nil, err
+		}
+		settings = append(settings, s)
+	}
+	return settings, nil
+}
 
+func (r *NotificationRepository) GetPendingDigestItems(ctx context.Context, userID string) ([]*domain.DigestItem, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, user_id, type, title, message, COALESCE(link, ''), created_at
+		FROM notification_digest_items
+		WHERE user_id = $1 AND digest_id IS NULL
+		ORDER BY created_at
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*domain.DigestItem
+	for rows.Next() {
+		item := &domain.DigestItem{}
+		err := rows.Scan(&item.ID, &item.UserID, &item.Type, &item.Title, &item.Message, &item.Link, &item.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (r *NotificationRepository) SaveDigest(ctx context.Context, digest *domain.NotificationDigest, itemIDs []string, d *domain.NotificationDelivery) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO notification_digests (user_id, frequency, period_key, item_count)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, period_key) DO NOTHING
+		RETURNING id, created_at
+	`, digest.UserID, digest.Frequency, digest.PeriodKey, digest.ItemCount).Scan(&digest.ID, &digest.CreatedAt)
+	if err == sql.ErrNoRows {
+		return domain.ErrAlreadyExists
+	}
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE notification_digest_items SET digest_id = $1
+		WHERE id = ANY($2) AND user_id = $3 AND digest_id IS NULL
+	`, digest.ID, pq.Array(itemIDs), digest.UserID)
+	if err != nil {
+		return err
+	}
+	if claimed, err := result.RowsAffected(); err != nil {
+		return err
+	} else if claimed != int64(len(itemIDs)) {
+		return domain.ErrAlreadyExists
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO notification_outbox (user_id, channel, type, title, message, link, next_attempt_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, status, created_at
+	`, d.UserID, d.Channel, d.Type, d.Title, d.Message, d.Link, d.NextAttemptAt).Scan(&d.ID, &d.Status, &d.CreatedAt)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+const notificationColumns = `n.id, n.user_id, n.type, n.version, n.title, n.message, COALESCE(n.link, ''),
+		n.payload, n.is_read, n.archived_at, n.created_at`
+
+func scanNotifications(rows *sql.Rows) ([]*domain.Notification, error) {
 	var notifications []*domain.Notification
 	for rows.Next() {
 		n := &domain.Notification{}
-		err := rows.Scan(&n.ID, &n.UserID, &n.Type, &n.Title, &n.Message, &n.Link, &n.IsRead, &n.CreatedAt)
+		var payload []byte
+		var archivedAt sql.NullTime
+		err := rows.Scan(&n.ID, &n.UserID, &n.Type, &n.Version, &n.Title, &n.Message, &n.Link,
+			&payload, &n.IsRead, &archivedAt, &n.CreatedAt)
 		if err != nil {
 			return nil, err
 		}
+		if len(payload) > 0 {
+			if err := json.Unmarshal(payload, &n.Payload); err != nil {
+				return nil, err
+			}
+		}
+		n.ArchivedAt = timePtr(archivedAt)
 		notifications = append(notifications, n)
 	}
 	return notifications, nil
 }
 
-func (r *NotificationRepository) MarkAsRead(ctx context.Context, notificationID string) error {
-	_, err := r.db.ExecContext(ctx, `UPDATE notifications SET is_read = true WHERE id = $1`, notificationID)
-	return err
-}
+const deliveryColumns = `id, notification_id, user_id, channel, type, title, message, COALESCE(link, ''),
+		status, attempts, COALESCE(last_error, ''), next_attempt_at, sent_at, created_at`
 
-func (r *NotificationRepository) MarkAllAsRead(ctx context.Context, userID string) error {
-	_, err := r.db.ExecContext(ctx, `UPDATE notifications SET is_read = true WHERE user_id = $1`, userID)
-	return err
+func scanDeliveries(rows *sql.Rows) ([]*domain.NotificationDelivery, error) {
+	var deliveries []*domain.NotificationDelivery
+	for rows.Next() {
+		d := &domain.NotificationDelivery{}
+		var notificationID sql.NullString
+		var sentAt sql.NullTime
+		err := rows.Scan(&d.ID, &notificationID, &d.UserID, &d.Channel, &d.Type, &d.Title, &d.Message, &d.Link,
+			&d.Status, &d.Attempts, &d.LastError, &d.NextAttemptAt, &sentAt, &d.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		d.NotificationID = stringPtr(notificationID)
+		d.SentAt = timePtr(sentAt)
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, nil
 }
@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+	"github.com/yourusername/online-library/internal/domain"
+	"github.com/yourusername/online-library/internal/donation"
+	"go.uber.org/zap"
+)
+
+// receiptCounter names the receipt_counters row donation receipts are
+// numbered from
+const receiptCounter = "donation_receipt"
+
+type ReceiptRepository struct {
+	db  *sql.DB
+	log *zap.Logger
+}
+
+var _ donation.ReceiptRepo = (*ReceiptRepository)(nil)
+
+func NewReceiptRepository(db *sql.DB, log *zap.Logger) *ReceiptRepository {
+	return &ReceiptRepository{db: db, log: log}
+}
+
+const receiptColumns = `id, number, donation_id, donor_id, donor_name, donation_type, amount, COALESCE(currency, ''),
+	COALESCE(book_title, ''), donated_at, issued_at, regenerated_at, voided_at, COALESCE(void_reason, '')`
+
+func (r *ReceiptRepository) ReceiptSource(ctx context.Context, donationID string) (*domain.DonationReceipt, error) {
+	query := `
+		SELECT d.id, d.donor_id, COALESCE(NULLIF(u.full_name, ''), u.username), d.donation_type,
+		       d.amount, COALESCE(d.currency, ''), COALESCE(b.title, d.pledge->>'title', ''),
+		       COALESCE(d.confirmed_at, d.catalogued_at, d.created_at)
+		FROM donations d
+		JOIN users u ON u.id = d.donor_id
+		LEFT JOIN books b ON b.id = d.book_id
+		WHERE d.id = $1
+	`
+	rc := &domain.DonationReceipt{}
+	var amount sql.NullFloat64
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, donationID).Scan(&rc.DonationID, &rc.DonorID, &rc.DonorName,
+		&rc.DonationType, &amount, &rc.Currency, &rc.BookTitle, &rc.DonatedAt)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	rc.Amount = float64Ptr(amount)
+	return rc, nil
+}
+
+func (r *ReceiptRepository) NextReceiptNumber(ctx context.Context) (int64, error) {
+	var number int64
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+		UPDATE receipt_counters SET last_value = last_value + 1 WHERE name = $1 RETURNING last_value
+	`, receiptCounter).Scan(&number)
+	return number, err
+}
+
+func (r *ReceiptRepository) CreateReceipt(ctx context.Context, rc *domain.DonationReceipt) error {
+	query := `
+		INSERT INTO donation_receipts (number, donation_id, donor_id, donor_name, donation_type, amount, currency,
+		                               book_title, donated_at, issued_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''), $9, $10)
+		RETURNING id
+	`
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, rc.Number, rc.DonationID, rc.DonorID, rc.DonorName,
+		rc.DonationType, nullFloat64(rc.Amount), rc.Currency, rc.BookTitle, rc.DonatedAt, rc.IssuedAt).Scan(&rc.ID)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		return domain.ErrAlreadyExists
+	}
+	return err
+}
+
+func (r *ReceiptRepository) UpdateReceipt(ctx context.Context, rc *domain.DonationReceipt) error {
+	query := `
+		UPDATE donation_receipts
+		SET donor_name = $1, amount = $2, currency = NULLIF($3, ''), book_title = NULLIF($4, ''), donated_at = $5,
+		    regenerated_at = $6, voided_at = $7, void_reason = NULLIF($8, '')
+		WHERE id = $9
+	`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, rc.DonorName, nullFloat64(rc.Amount), rc.Currency,
+		rc.BookTitle, rc.DonatedAt, nullTime(rc.RegeneratedAt), nullTime(rc.VoidedAt), rc.VoidReason, rc.ID)
+	if err != nil {
+		return err
+	}
+	n, err := rowsAffected(result)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *ReceiptRepository) GetReceiptByDonation(ctx context.Context, donationID string) (*domain.DonationReceipt, error) {
+	query := `SELECT ` + receiptColumns + ` FROM donation_receipts WHERE donation_id = $1`
+	rc, err := scanReceipt(conn(ctx, r.db).QueryRowContext(ctx, query, donationID))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	return rc, err
+}
+
+func (r *ReceiptRepository) ListReceipts(ctx context.Context, donorID string, year int) ([]*domain.DonationReceipt, error) {
+	query := `
+		SELECT ` + receiptColumns + `
+		FROM donation_receipts
+		WHERE donor_id = $1 AND ($2::int = 0 OR EXTRACT(YEAR FROM donated_at)::int = $2::int)
+		ORDER BY number ASC
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, donorID, year)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var receipts []*domain.DonationReceipt
+	for rows.Next() {
+		rc, err := scanReceipt(rows)
+		if err != nil {
+			return nil, err
+		}
+		receipts = append(receipts, rc)
+	}
+	return receipts, rows.Err()
+}
+
+func (r *ReceiptRepository) GetReceiptPDF(ctx context.Context, receiptID string) ([]byte, error) {
+	var pdf []byte
+	err := conn(ctx, r.db).QueryRowContext(ctx, `SELECT pdf FROM donation_receipts WHERE id = $1`, receiptID).Scan(&pdf)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	return pdf, err
+}
+
+func (r *ReceiptRepository) SaveReceiptPDF(ctx context.Context, receiptID string, pdf []byte) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE donation_receipts SET pdf = $1 WHERE id = $2`, pdf, receiptID)
+	return err
+}
+
+func (r *ReceiptRepository) GetDonorName(ctx context.Context, donorID string) (string, error) {
+	var name string
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+		SELECT COALESCE(NULLIF(full_name, ''), username) FROM users WHERE id = $1
+	`, donorID).Scan(&name)
+	if err == sql.ErrNoRows {
+		return "", domain.ErrUserNotFound
+	}
+	return name, err
+}
+
+func (r *ReceiptRepository) GetStatementPDF(ctx context.Context, donorID string, year int) ([]byte, error) {
+	var pdf []byte
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+		SELECT pdf FROM donor_statements WHERE donor_id = $1 AND year = $2
+	`, donorID, year).Scan(&pdf)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return pdf, err
+}
+
+func (r *ReceiptRepository) SaveStatement(ctx context.Context, st *domain.DonorStatement, pdf []byte) error {
+	query := `
+		INSERT INTO donor_statements (donor_id, year, receipt_count, pdf, generated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (donor_id, year) DO UPDATE
+		SET receipt_count = EXCLUDED.receipt_count, pdf = EXCLUDED.pdf, generated_at = EXCLUDED.generated_at
+	`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, st.DonorID, st.Year, len(st.Receipts), pdf, st.GeneratedAt)
+	return err
+}
+
+func (r *ReceiptRepository) DeleteStatement(ctx context.Context, donorID string, year int) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM donor_statements WHERE donor_id = $1 AND year = $2`, donorID, year)
+	return err
+}
+
+func scanReceipt(row rowScanner) (*domain.DonationReceipt, error) {
+	rc := &domain.DonationReceipt{}
+	var donationID, donorID sql.NullString
+	var amount sql.NullFloat64
+	var regeneratedAt, voidedAt sql.NullTime
+	err := row.Scan(&rc.ID, &rc.Number, &donationID, &donorID, &rc.DonorName, &rc.DonationType, &amount, &rc.Currency,
+		&rc.BookTitle, &rc.DonatedAt, &rc.IssuedAt, &regeneratedAt, &voidedAt, &rc.VoidReason)
+	if err != nil {
+		return nil, err
+	}
+	rc.Code = domain.ReceiptCode(rc.Number)
+	rc.DonationID = donationID.String
+	rc.DonorID = donorID.String
+	rc.Amount = float64Ptr(amount)
+	rc.RegeneratedAt = timePtr(regeneratedAt)
+	rc.VoidedAt = timePtr(voidedAt)
+	return rc, nil
+}
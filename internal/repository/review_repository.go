@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 
+	"github.com/lib/pq"
 	"github.com/yourusername/online-library/internal/domain"
 	"github.com/yourusername/online-library/internal/review"
 	"go.uber.org/zap"
@@ -21,20 +22,63 @@ func NewReviewRepository(db *sql.DB, log *zap.Logger) *ReviewRepository {
 }
 
 func (r *ReviewRepository) Create(ctx context.Context, rev *domain.UserReview) error {
-	query := `INSERT INTO user_reviews (id, reviewer_id, reviewee_id, book_id, behavior_rating, 
-	                                     book_condition_rating, communication_rating, comment, created_at)
-	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
-	_, err := r.db.ExecContext(ctx, query, rev.ID, rev.ReviewerID, rev.RevieweeID,
-		nullString(rev.BookID), nullInt64(rev.BehaviorRating), nullInt64(rev.BookConditionRating),
-		nullInt64(rev.CommunicationRating), rev.Comment, rev.CreatedAt)
+	return inTx(ctx, r.db, func(tx *sql.Tx) error {
+		// Reviews from users with a higher success score carry more weight:
+		// the reviewer's score is clamped to 50-200 and scaled to 0.5-2.0.
+		query := `INSERT INTO user_reviews (id, reviewer_id, reviewee_id, book_id, handover_thread_id, behavior_rating,
+		                                     book_condition_rating, communication_rating, comment, comment_html, created_at, reviewer_weight)
+		          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11,
+		                  (SELECT LEAST(GREATEST(COALESCE(success_score, 100), 50), 200) / 100.0 FROM users WHERE id = $2))`
+		_, err := tx.ExecContext(ctx, query, rev.ID, rev.ReviewerID, rev.RevieweeID,
+			nullString(rev.BookID), nullString(rev.HandoverThreadID),
+			nullInt64(rev.BehaviorRating), nullInt64(rev.BookConditionRating),
+			nullInt64(rev.CommunicationRating), rev.Comment, rev.CommentHTML, rev.CreatedAt)
+		if err != nil {
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+				return domain.ErrAlreadyReviewed
+			}
+			return err
+		}
+
+		return refreshRatingAverages(ctx, tx, rev.RevieweeID)
+	})
+}
+
+// refreshRatingAverages recomputes the review count and weighted rating
+// averages stored on the user.
+func refreshRatingAverages(ctx context.Context, tx *sql.Tx, userID string) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE users u SET
+			reviews_received = s.total,
+			avg_behavior_rating = s.behavior,
+			avg_book_condition_rating = s.book_condition,
+			avg_communication_rating = s.communication
+		FROM (
+			SELECT COUNT(*) AS total,
+			       SUM(behavior_rating * reviewer_weight) / NULLIF(SUM(reviewer_weight) FILTER (WHERE behavior_rating IS NOT NULL), 0) AS behavior,
+			       SUM(book_condition_rating * reviewer_weight) / NULLIF(SUM(reviewer_weight) FILTER (WHERE book_condition_rating IS NOT NULL), 0) AS book_condition,
+			       SUM(communication_rating * reviewer_weight) / NULLIF(SUM(reviewer_weight) FILTER (WHERE communication_rating IS NOT NULL), 0) AS communication
+			FROM user_reviews
+			WHERE reviewee_id = $1 AND status = 'visible'
+		) s
+		WHERE u.id = $1
+	`, userID)
 	return err
 }
 
+func (r *ReviewRepository) ExistsForThread(ctx context.Context, threadID, reviewerID string) (bool, error) {
+	var exists bool
+	err := conn(ctx, r.db).QueryRowContext(ctx,
+		`SELECT EXISTS(SELECT 1 FROM user_reviews WHERE handover_thread_id = $1 AND reviewer_id = $2)`,
+		threadID, reviewerID).Scan(&exists)
+	return exists, err
+}
+
 func (r *ReviewRepository) FindByUserID(ctx context.Context, userID string) ([]*domain.UserReview, error) {
-	query := `SELECT id, reviewer_id, reviewee_id, book_id, behavior_rating, 
-	                 book_condition_rating, communication_rating, comment, created_at
-	          FROM user_reviews WHERE reviewee_id = $1 ORDER BY created_at DESC`
-	rows, err := r.db.QueryContext(ctx, query, userID)
+	query := `SELECT id, reviewer_id, reviewee_id, book_id, handover_thread_id, behavior_rating,
+	                 book_condition_rating, communication_rating, COALESCE(comment, ''), COALESCE(comment_html, ''), created_at
+	          FROM user_reviews WHERE reviewee_id = $1 AND status = 'visible' ORDER BY created_at DESC`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -43,14 +87,15 @@ func (r *ReviewRepository) FindByUserID(ctx context.Context, userID string) ([]*
 	var reviews []*domain.UserReview
 	for rows.Next() {
 		rev := &domain.UserReview{}
-		var bookID sql.NullString
+		var bookID, threadID sql.NullString
 		var behaviorRating, bookConditionRating, communicationRating sql.NullInt64
-		err := rows.Scan(&rev.ID, &rev.ReviewerID, &rev.RevieweeID, &bookID,
-			&behaviorRating, &bookConditionRating, &communicationRating, &rev.Comment, &rev.CreatedAt)
+		err := rows.Scan(&rev.ID, &rev.ReviewerID, &rev.RevieweeID, &bookID, &threadID,
+			&behaviorRating, &bookConditionRating, &communicationRating, &rev.Comment, &rev.CommentHTML, &rev.CreatedAt)
 		if err != nil {
 			return nil, err
 		}
 		rev.BookID = stringPtr(bookID)
+		rev.HandoverThreadID = stringPtr(threadID)
 		rev.BehaviorRating = intPtr(behaviorRating)
 		rev.BookConditionRating = intPtr(bookConditionRating)
 		rev.CommunicationRating = intPtr(communicationRating)
@@ -58,3 +103,171 @@ func (r *ReviewRepository) FindByUserID(ctx context.Context, userID string) ([]*
 	}
 	return reviews, nil
 }
+
+// reviewColumns selects a review in the order scanReview expects
+const reviewColumns = `r.id, r.reviewer_id, r.reviewee_id, r.book_id, r.handover_thread_id, r.behavior_rating,
+	r.book_condition_rating, r.communication_rating, COALESCE(r.comment, ''), COALESCE(r.comment_html, ''), r.created_at`
+
+func scanReview(row rowScanner) (*domain.UserReview, error) {
+	rev := &domain.UserReview{}
+	var bookID, threadID sql.NullString
+	var behaviorRating, bookConditionRating, communicationRating sql.NullInt64
+	err := row.Scan(&rev.ID, &rev.ReviewerID, &rev.RevieweeID, &bookID, &threadID,
+		&behaviorRating, &bookConditionRating, &communicationRating, &rev.Comment, &rev.CommentHTML, &rev.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	rev.BookID = stringPtr(bookID)
+	rev.HandoverThreadID = stringPtr(threadID)
+	rev.BehaviorRating = intPtr(behaviorRating)
+	rev.BookConditionRating = intPtr(bookConditionRating)
+	rev.CommunicationRating = intPtr(communicationRating)
+	return rev, nil
+}
+
+func (r *ReviewRepository) FindByID(ctx context.Context, id string) (*domain.UserReview, error) {
+	query := `SELECT ` + reviewColumns + ` FROM user_reviews r WHERE r.id = $1 AND r.status = 'visible'`
+	rev, err := scanReview(conn(ctx, r.db).QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	return rev, err
+}
+
+func (r *ReviewRepository) Update(ctx context.Context, rev *domain.UserReview) error {
+	return inTx(ctx, r.db, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `
+			UPDATE user_reviews SET behavior_rating = $1, book_condition_rating = $2,
+			       communication_rating = $3, comment = $4, comment_html = $5, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $6
+		`, nullInt64(rev.BehaviorRating), nullInt64(rev.BookConditionRating),
+			nullInt64(rev.CommunicationRating), rev.Comment, rev.CommentHTML, rev.ID)
+		if err != nil {
+			return err
+		}
+
+		return refreshRatingAverages(ctx, tx, rev.RevieweeID)
+	})
+}
+
+func (r *ReviewRepository) Remove(ctx context.Context, reviewID string) error {
+	return inTx(ctx, r.db, func(tx *sql.Tx) error {
+		var revieweeID string
+		err := tx.QueryRowContext(ctx, `
+			UPDATE user_reviews SET status = 'removed', updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1 RETURNING reviewee_id
+		`, reviewID).Scan(&revieweeID)
+		if err == sql.ErrNoRows {
+			return domain.ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		return refreshRatingAverages(ctx, tx, revieweeID)
+	})
+}
+
+func (r *ReviewRepository) CreateDispute(ctx context.Context, d *domain.ReviewDispute) error {
+	query := `INSERT INTO review_disputes (id, review_id, disputer_id, statement, status, created_at)
+	          VALUES ($1, $2, $3, $4, $5, $6)`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, d.ID, d.ReviewID, d.DisputerID, d.Statement, d.Status, d.CreatedAt)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		return domain.ErrAlreadyExists
+	}
+	return err
+}
+
+const disputeColumns = `d.id, d.review_id, d.disputer_id, d.statement, d.status,
+	COALESCE(d.resolution_note, ''), d.resolved_by, d.resolved_at, d.created_at`
+
+func scanDispute(row rowScanner) (*domain.ReviewDispute, error) {
+	d := &domain.ReviewDispute{}
+	var resolvedBy sql.NullString
+	var resolvedAt sql.NullTime
+	err := row.Scan(&d.ID, &d.ReviewID, &d.DisputerID, &d.Statement, &d.Status,
+		&d.ResolutionNote, &resolvedBy, &resolvedAt, &d.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	d.ResolvedBy = stringPtr(resolvedBy)
+	d.ResolvedAt = timePtr(resolvedAt)
+	return d, nil
+}
+
+func (r *ReviewRepository) FindDisputeByID(ctx context.Context, id string) (*domain.ReviewDispute, error) {
+	query := `SELECT ` + disputeColumns + ` FROM review_disputes d WHERE d.id = $1`
+	d, err := scanDispute(conn(ctx, r.db).QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	return d, err
+}
+
+// ListDisputes returns the moderation queue, oldest first, with each
+// dispute's review attached.
+func (r *ReviewRepository) ListDisputes(ctx context.Context, status domain.DisputeStatus, limit, offset int) ([]*domain.ReviewDispute, error) {
+	query := `
+		SELECT d.id, d.review_id, d.disputer_id, d.statement, d.status,
+		       COALESCE(d.resolution_note, ''), d.resolved_by, d.resolved_at, d.created_at,
+		       r.reviewer_id, r.reviewee_id, r.book_id, r.handover_thread_id, r.behavior_rating,
+		       r.book_condition_rating, r.communication_rating, COALESCE(r.comment, ''), COALESCE(r.comment_html, ''), r.created_at
+		FROM review_disputes d
+		JOIN user_reviews r ON d.review_id = r.id
+		WHERE d.status = $1
+		ORDER BY d.created_at ASC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var disputes []*domain.ReviewDispute
+	for rows.Next() {
+		d := &domain.ReviewDispute{}
+		rev := &domain.UserReview{}
+		var resolvedBy, bookID, threadID sql.NullString
+		var resolvedAt sql.NullTime
+		var behaviorRating, bookConditionRating, communicationRating sql.NullInt64
+		err := rows.Scan(
+			&d.ID, &d.ReviewID, &d.DisputerID, &d.Statement, &d.Status,
+			&d.ResolutionNote, &resolvedBy, &resolvedAt, &d.CreatedAt,
+			&rev.ReviewerID, &rev.RevieweeID, &bookID, &threadID, &behaviorRating,
+			&bookConditionRating, &communicationRating, &rev.Comment, &rev.CommentHTML, &rev.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		d.ResolvedBy = stringPtr(resolvedBy)
+		d.ResolvedAt = timePtr(resolvedAt)
+		rev.ID = d.ReviewID
+		rev.BookID = stringPtr(bookID)
+		rev.HandoverThreadID = stringPtr(threadID)
+		rev.BehaviorRating = intPtr(behaviorRating)
+		rev.BookConditionRating = intPtr(bookConditionRating)
+		rev.CommunicationRating = intPtr(communicationRating)
+		d.Review = rev
+		disputes = append(disputes, d)
+	}
+	return disputes, nil
+}
+
+func (r *ReviewRepository) ResolveDispute(ctx context.Context, d *domain.ReviewDispute) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, `
+		UPDATE review_disputes SET status = $1, resolution_note = $2, resolved_by = $3, resolved_at = $4
+		WHERE id = $5 AND status = 'pending'
+	`, d.Status, d.ResolutionNote, nullString(d.ResolvedBy), nullTime(d.ResolvedAt), d.ID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
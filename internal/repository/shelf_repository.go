@@ -0,0 +1,268 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+	"github.com/yourusername/online-library/internal/domain"
+	"github.com/yourusername/online-library/internal/shelf"
+	"go.uber.org/zap"
+)
+
+type ShelfRepository struct {
+	db  *sql.DB
+	log *zap.Logger
+}
+
+var _ shelf.ShelfRepo = (*ShelfRepository)(nil)
+
+func NewShelfRepository(db *sql.DB, log *zap.Logger) *ShelfRepository {
+	return &ShelfRepository{db: db, log: log}
+}
+
+// shelfSelect reads shelves with their owner and counts. $1 is the viewer,
+// for the following flag.
+const shelfSelect = `
+	SELECT s.id, s.owner_id, u.username, s.name, COALESCE(s.description, ''), s.visibility, s.share_token,
+	       s.cloned_from,
+	       (SELECT COUNT(*) FROM shelf_items i WHERE i.shelf_id = s.id),
+	       (SELECT COUNT(*) FROM shelf_follows f WHERE f.shelf_id = s.id),
+	       EXISTS (SELECT 1 FROM shelf_follows f WHERE f.shelf_id = s.id AND f.user_id::text = $1),
+	       s.created_at, s.updated_at
+	FROM shelves s
+	JOIN users u ON u.id = s.owner_id`
+
+func (r *ShelfRepository) Create(ctx context.Context, s *domain.Shelf) error {
+	query := `
+		INSERT INTO shelves (id, owner_id, name, description, visibility, share_token, cloned_from, created_at, updated_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8, $9)
+	`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, s.ID, s.OwnerID, s.Name, s.Description, s.Visibility,
+		s.ShareToken, nullString(s.ClonedFrom), s.CreatedAt, s.UpdatedAt)
+	return err
+}
+
+func (r *ShelfRepository) Update(ctx context.Context, s *domain.Shelf) error {
+	query := `
+		UPDATE shelves
+		SET name = $1, description = NULLIF($2, ''), visibility = $3, share_token = $4, updated_at = $5
+		WHERE id = $6
+	`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, s.Name, s.Description, s.Visibility, s.ShareToken,
+		s.UpdatedAt, s.ID)
+	if err != nil {
+		return err
+	}
+	n, err := rowsAffected(result)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *ShelfRepository) Delete(ctx context.Context, id string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM shelves WHERE id = $1`, id)
+	return err
+}
+
+func (r *ShelfRepository) GetByID(ctx context.Context, id, viewerID string) (*domain.Shelf, error) {
+	query := shelfSelect + ` WHERE s.id = $2`
+	s, err := scanShelf(conn(ctx, r.db).QueryRowContext(ctx, query, viewerID, id))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	return s, err
+}
+
+func (r *ShelfRepository) GetByShareToken(ctx context.Context, token, viewerID string) (*domain.Shelf, error) {
+	query := shelfSelect + ` WHERE s.share_token = $2`
+	s, err := scanShelf(conn(ctx, r.db).QueryRowContext(ctx, query, viewerID, token))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	return s, err
+}
+
+func (r *ShelfRepository) ListByOwner(ctx context.Context, ownerID string) ([]*domain.Shelf, error) {
+	query := shelfSelect + ` WHERE s.owner_id = $2 ORDER BY s.updated_at DESC`
+	return r.query(ctx, query, ownerID, ownerID)
+}
+
+func (r *ShelfRepository) ListFollowed(ctx context.Context, userID string) ([]*domain.Shelf, error) {
+	query := shelfSelect + `
+		JOIN shelf_follows sf ON sf.shelf_id = s.id AND sf.user_id = $2
+		WHERE s.visibility <> 'private'
+		ORDER BY sf.created_at DESC`
+	return r.query(ctx, query, userID, userID)
+}
+
+func (r *ShelfRepository) ListPublic(ctx context.Context, limit, offset int) ([]*domain.Shelf, error) {
+	query := shelfSelect + `
+		WHERE s.visibility = 'public'
+		ORDER BY 10 DESC, s.updated_at DESC
+		LIMIT $2 OFFSET $3`
+	return r.query(ctx, query, "", limit, offset)
+}
+
+func (r *ShelfRepository) CountByOwner(ctx context.Context, ownerID string) (int, error) {
+	var n int
+	err := conn(ctx, r.db).QueryRowContext(ctx, `SELECT COUNT(*) FROM shelves WHERE owner_id = $1`, ownerID).Scan(&n)
+	return n, err
+}
+
+func (r *ShelfRepository) ListItems(ctx context.Context, shelfID string) ([]*domain.ShelfItem, error) {
+	query := `
+		SELECT i.id, i.shelf_id, i.book_id, i.position, COALESCE(i.note, ''), i.added_at,
+		       b.title, b.author, COALESCE(b.cover_url, ''), b.status,
+		       (SELECT COUNT(*) FROM book_requests br WHERE br.book_id = b.id AND br.status = 'pending')
+		FROM shelf_items i
+		JOIN books b ON b.id = i.book_id
+		WHERE i.shelf_id = $1
+		ORDER BY i.position ASC, i.added_at ASC
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, shelfID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*domain.ShelfItem
+	for rows.Next() {
+		item := &domain.ShelfItem{}
+		book := &domain.ShelfBook{}
+		err := rows.Scan(&item.ID, &item.ShelfID, &item.BookID, &item.Position, &item.Note, &item.AddedAt,
+			&book.Title, &book.Author, &book.CoverURL, &book.Status, &book.PendingRequests)
+		if err != nil {
+			return nil, err
+		}
+		book.ID = item.BookID
+		book.Available = book.Status == domain.StatusAvailable
+		item.Book = book
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+func (r *ShelfRepository) AddItem(ctx context.Context, item *domain.ShelfItem) error {
+	query := `
+		INSERT INTO shelf_items (id, shelf_id, book_id, position, note, added_at)
+		VALUES ($1, $2, $3, (SELECT COALESCE(MAX(position), 0) + 1 FROM shelf_items WHERE shelf_id = $2),
+		        NULLIF($4, ''), $5)
+		RETURNING position
+	`
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, item.ID, item.ShelfID, item.BookID, item.Note, item.AddedAt).
+		Scan(&item.Position)
+	if pqErr, ok := err.(*pq.Error); ok {
+		switch pqErr.Code {
+		case "23505":
+			return domain.ErrAlreadyExists
+		case "23503":
+			return domain.ErrBookNotFound
+		}
+	}
+	return err
+}
+
+func (r *ShelfRepository) UpdateItemNote(ctx context.Context, shelfID, bookID, note string) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx,
+		`UPDATE shelf_items SET note = NULLIF($1, '') WHERE shelf_id = $2 AND book_id = $3`, note, shelfID, bookID)
+	if err != nil {
+		return err
+	}
+	n, err := rowsAffected(result)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *ShelfRepository) RemoveItem(ctx context.Context, shelfID, bookID string) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx,
+		`DELETE FROM shelf_items WHERE shelf_id = $1 AND book_id = $2`, shelfID, bookID)
+	if err != nil {
+		return err
+	}
+	n, err := rowsAffected(result)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *ShelfRepository) SetItemPositions(ctx context.Context, shelfID string, bookIDs []string) error {
+	query := `
+		UPDATE shelf_items
+		SET position = array_position($2::text[], book_id::text)
+		WHERE shelf_id = $1 AND book_id::text = ANY($2::text[])
+	`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, shelfID, pq.Array(bookIDs))
+	return err
+}
+
+func (r *ShelfRepository) CopyItems(ctx context.Context, fromShelfID, toShelfID string) error {
+	query := `
+		INSERT INTO shelf_items (shelf_id, book_id, position, note, added_at)
+		SELECT $2, book_id, ROW_NUMBER() OVER (ORDER BY position, added_at), note, NOW()
+		FROM shelf_items
+		WHERE shelf_id = $1
+	`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, fromShelfID, toShelfID)
+	return err
+}
+
+func (r *ShelfRepository) Follow(ctx context.Context, shelfID, userID string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx,
+		`INSERT INTO shelf_follows (shelf_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`, shelfID, userID)
+	return err
+}
+
+func (r *ShelfRepository) Unfollow(ctx context.Context, shelfID, userID string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx,
+		`DELETE FROM shelf_follows WHERE shelf_id = $1 AND user_id = $2`, shelfID, userID)
+	return err
+}
+
+func (r *ShelfRepository) RemoveFollowers(ctx context.Context, shelfID string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM shelf_follows WHERE shelf_id = $1`, shelfID)
+	return err
+}
+
+func (r *ShelfRepository) query(ctx context.Context, query string, args ...interface{}) ([]*domain.Shelf, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var shelves []*domain.Shelf
+	for rows.Next() {
+		s, err := scanShelf(rows)
+		if err != nil {
+			return nil, err
+		}
+		shelves = append(shelves, s)
+	}
+	return shelves, rows.Err()
+}
+
+func scanShelf(row rowScanner) (*domain.Shelf, error) {
+	s := &domain.Shelf{}
+	var clonedFrom sql.NullString
+	err := row.Scan(&s.ID, &s.OwnerID, &s.OwnerUsername, &s.Name, &s.Description, &s.Visibility, &s.ShareToken,
+		&clonedFrom, &s.BookCount, &s.Followers, &s.Following, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	s.ClonedFrom = stringPtr(clonedFrom)
+	return s, nil
+}
@@ -3,7 +3,10 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"time"
 
+	"github.com/yourusername/online-library/internal/domain"
 	"github.com/yourusername/online-library/internal/successscore"
 	"go.uber.org/zap"
 )
@@ -19,30 +22,258 @@ func NewSuccessScoreRepository(db *sql.DB, log *zap.Logger) *SuccessScoreReposit
 	return &SuccessScoreRepository{db: db, log: log}
 }
 
-func (r *SuccessScoreRepository) UpdateScore(ctx context.Context, userID string, change int, reason, refType string, refID *string) error {
+func (r *SuccessScoreRepository) UpdateScore(ctx context.Context, userID string, change int, eventType domain.ScoreEventType, reason, refType string, refID *string) error {
+	return inTx(ctx, r.db, func(tx *sql.Tx) error {
+		// Update user success score
+		_, err := tx.ExecContext(ctx, `UPDATE users SET success_score = success_score + $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`, change, userID)
+		if err != nil {
+			return err
+		}
+
+		// Record history. created_at is written in UTC from here rather than
+		// by the database session's zone, so it compares with the UTC day
+		// boundary daily caps are summed from.
+		var refIDVal interface{} = nil
+		if refID != nil {
+			refIDVal = *refID
+		}
+		_, err = tx.ExecContext(ctx, `INSERT INTO success_score_history (user_id, change_amount, event_type, reason, reference_type, reference_id, created_at)
+		                               VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			userID, change, eventType, reason, refType, refIDVal, time.Now().UTC())
+		return err
+	})
+}
+
+func (r *SuccessScoreRepository) GetHistoryByUser(ctx context.Context, userID string, limit, offset int) ([]*domain.SuccessScoreHistory, error) {
+	query := `
+		SELECT id, user_id, change_amount, COALESCE(event_type, ''), reason,
+		       COALESCE(reference_type, ''), reference_id, created_at
+		FROM success_score_history
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.db.QueryContext(ctx, query, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []*domain.SuccessScoreHistory
+	for rows.Next() {
+		h := &domain.SuccessScoreHistory{}
+		var refID sql.NullString
+		if err := rows.Scan(&h.ID, &h.UserID, &h.ChangeAmount, &h.EventType, &h.Reason, &h.ReferenceType, &refID, &h.CreatedAt); err != nil {
+			return nil, err
+		}
+		h.ReferenceID = stringPtr(refID)
+		history = append(history, h)
+	}
+	return history, nil
+}
+
+func (r *SuccessScoreRepository) FindScoreDrift(ctx context.Context, baseScore int) ([]*successscore.ScoreDrift, error) {
+	query := `
+		SELECT u.id, u.username, COALESCE(u.success_score, 0),
+		       $1 + COALESCE(SUM(h.change_amount), 0) AS computed
+		FROM users u
+		LEFT JOIN success_score_history h ON h.user_id = u.id
+		GROUP BY u.id, u.username, u.success_score
+		HAVING COALESCE(u.success_score, 0) <> $1 + COALESCE(SUM(h.change_amount), 0)
+		ORDER BY u.username
+	`
+	rows, err := r.db.QueryContext(ctx, query, baseScore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var drifted []*successscore.ScoreDrift
+	for rows.Next() {
+		d := &successscore.ScoreDrift{}
+		if err := rows.Scan(&d.UserID, &d.Username, &d.StoredScore, &d.ComputedScore); err != nil {
+			return nil, err
+		}
+		d.Drift = d.StoredScore - d.ComputedScore
+		drifted = append(drifted, d)
+	}
+	return drifted, nil
+}
+
+func (r *SuccessScoreRepository) CountUsers(ctx context.Context) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users`).Scan(&count)
+	return count, err
+}
+
+func (r *SuccessScoreRepository) LockUser(ctx context.Context, userID string) error {
+	var id string
+	err := conn(ctx, r.db).QueryRowContext(ctx, `SELECT id FROM users WHERE id = $1 FOR UPDATE`, userID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return domain.ErrNotFound
+	}
+	return err
+}
+
+func (r *SuccessScoreRepository) RecordDrift(ctx context.Context, userID string, baseScore int, reason string) error {
+	query := `
+		INSERT INTO success_score_history (user_id, change_amount, event_type, reason, reference_type, created_at)
+		SELECT u.id, COALESCE(u.success_score, 0) - $2 - COALESCE(SUM(h.change_amount), 0), $3, $4, '', $5
+		FROM users u
+		LEFT JOIN success_score_history h ON h.user_id = u.id
+		WHERE u.id = $1
+		GROUP BY u.id, u.success_score
+		HAVING COALESCE(u.success_score, 0) <> $2 + COALESCE(SUM(h.change_amount), 0)
+	`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, userID, baseScore, domain.ScoreEventAdminAdjustment, reason, time.Now().UTC())
+	return err
+}
+
+func (r *SuccessScoreRepository) GetRule(ctx context.Context, eventType domain.ScoreEventType) (*domain.ScoreRule, error) {
+	query := `
+		SELECT id, event_type, amount, daily_cap, COALESCE(is_active, true),
+		       COALESCE(description, ''), updated_by, updated_at
+		FROM success_score_rules WHERE event_type = $1
+	`
+	rule, err := scanScoreRule(r.db.QueryRowContext(ctx, query, eventType))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	return rule, err
+}
+
+func (r *SuccessScoreRepository) ListRules(ctx context.Context) ([]*domain.ScoreRule, error) {
+	query := `
+		SELECT id, event_type, amount, daily_cap, COALESCE(is_active, true),
+		       COALESCE(description, ''), updated_by, updated_at
+		FROM success_score_rules
+		ORDER BY event_type
+	`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []*domain.ScoreRule
+	for rows.Next() {
+		rule, err := scanScoreRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// SaveRule upserts a rule and writes the before/after values to audit_logs
+// in the same transaction.
+func (r *SuccessScoreRepository) SaveRule(ctx context.Context, rule *domain.ScoreRule, actorID string) error {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	// Update user success score
-	_, err = tx.ExecContext(ctx, `UPDATE users SET success_score = success_score + $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`, change, userID)
-	if err != nil {
+	previous, err := scanScoreRule(tx.QueryRowContext(ctx, `
+		SELECT id, event_type, amount, daily_cap, COALESCE(is_active, true),
+		       COALESCE(description, ''), updated_by, updated_at
+		FROM success_score_rules WHERE event_type = $1 FOR UPDATE
+	`, rule.EventType))
+	if err != nil && err != sql.ErrNoRows {
 		return err
 	}
 
-	// Record history
-	var refIDVal interface{} = nil
-	if refID != nil {
-		refIDVal = *refID
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO success_score_rules (event_type, amount, daily_cap, is_active, description, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7)
+		ON CONFLICT (event_type) DO UPDATE SET
+			amount = EXCLUDED.amount,
+			daily_cap = EXCLUDED.daily_cap,
+			is_active = EXCLUDED.is_active,
+			description = COALESCE(EXCLUDED.description, success_score_rules.description),
+			updated_by = EXCLUDED.updated_by,
+			updated_at = EXCLUDED.updated_at
+		RETURNING id, COALESCE(description, '')
+	`, rule.EventType, rule.Amount, nullInt64(rule.DailyCap), rule.IsActive, rule.Description, actorID, rule.UpdatedAt,
+	).Scan(&rule.ID, &rule.Description)
+	if err != nil {
+		return err
 	}
-	_, err = tx.ExecContext(ctx, `INSERT INTO success_score_history (user_id, change_amount, reason, reference_type, reference_id, created_at)
-	                               VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP)`,
-		userID, change, reason, refType, refIDVal)
+
+	details, _ := json.Marshal(map[string]interface{}{
+		"event_type": rule.EventType,
+		"before":     previous,
+		"after":      rule,
+	})
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO audit_logs (user_id, action, resource_type, resource_id, details, created_at)
+		VALUES ($1, 'score_rule.updated', 'success_score_rule', $2, $3, NOW())
+	`, actorID, rule.ID, details)
 	if err != nil {
 		return err
 	}
 
 	return tx.Commit()
 }
+
+func (r *SuccessScoreRepository) SumEventSince(ctx context.Context, userID string, eventType domain.ScoreEventType, since time.Time) (int, error) {
+	var total int
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(change_amount), 0)
+		FROM success_score_history
+		WHERE user_id = $1 AND event_type = $2 AND created_at >= $3
+	`, userID, eventType, since).Scan(&total)
+	return total, err
+}
+
+func (r *SuccessScoreRepository) SumReference(ctx context.Context, userID, refType, refID string) (int, error) {
+	var total int
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(change_amount), 0)
+		FROM success_score_history
+		WHERE user_id = $1 AND reference_type = $2 AND reference_id = $3
+	`, userID, refType, refID).Scan(&total)
+	return total, err
+}
+
+func (r *SuccessScoreRepository) AggregateEvents(ctx context.Context, since, until time.Time) ([]*successscore.EventAggregate, error) {
+	query := `
+		SELECT h.user_id, COALESCE(u.username, ''), h.event_type, DATE(h.created_at),
+		       COUNT(*), COALESCE(SUM(h.change_amount), 0)
+		FROM success_score_history h
+		LEFT JOIN users u ON h.user_id = u.id
+		WHERE h.created_at >= $1 AND h.created_at < $2
+		  AND h.event_type IS NOT NULL AND h.event_type <> 'admin_adjustment'
+		GROUP BY h.user_id, u.username, h.event_type, DATE(h.created_at)
+	`
+	rows, err := r.db.QueryContext(ctx, query, since, until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var aggregates []*successscore.EventAggregate
+	for rows.Next() {
+		a := &successscore.EventAggregate{}
+		if err := rows.Scan(&a.UserID, &a.Username, &a.EventType, &a.Day, &a.Count, &a.Total); err != nil {
+			return nil, err
+		}
+		aggregates = append(aggregates, a)
+	}
+	return aggregates, nil
+}
+
+func scanScoreRule(row rowScanner) (*domain.ScoreRule, error) {
+	rule := &domain.ScoreRule{}
+	var dailyCap sql.NullInt64
+	var updatedBy sql.NullString
+	err := row.Scan(&rule.ID, &rule.EventType, &rule.Amount, &dailyCap, &rule.IsActive,
+		&rule.Description, &updatedBy, &rule.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	rule.DailyCap = intPtr(dailyCap)
+	rule.UpdatedBy = stringPtr(updatedBy)
+	return rule, nil
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/yourusername/online-library/internal/domain"
+	"go.uber.org/zap"
+)
+
+// querier is satisfied by both *sql.DB and *sql.Tx
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+type txKey struct{}
+
+// UnitOfWork runs a service operation that spans several repositories in a
+// single transaction. Repositories that look up their connection with conn
+// join the transaction through the context passed to the callback.
+type UnitOfWork struct {
+	db  *sql.DB
+	log *zap.Logger
+}
+
+func NewUnitOfWork(db *sql.DB, log *zap.Logger) *UnitOfWork {
+	return &UnitOfWork{db: db, log: log}
+}
+
+// Do commits if fn returns nil and rolls back otherwise. A Do nested in
+// another joins the outer transaction.
+func (u *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		u.log.Error("failed to commit transaction", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// conn returns the transaction started by UnitOfWork.Do for ctx, or db
+// outside of one
+func conn(ctx context.Context, db *sql.DB) querier {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return db
+}
+
+// inTx runs fn in the transaction started by UnitOfWork.Do for ctx, or
+// outside of one in a transaction of its own that commits if fn succeeds
+func inTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(tx)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// lockBook takes a row lock on the book until the surrounding transaction
+// ends, serializing state changes to the same book
+func lockBook(ctx context.Context, q querier, bookID string) error {
+	var id string
+	err := q.QueryRowContext(ctx, `SELECT id FROM books WHERE id = $1 FOR UPDATE`, bookID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return domain.ErrBookNotFound
+	}
+	return err
+}
@@ -36,28 +36,34 @@ func (r *UserRepository) FindByID(ctx context.Context, id string) (*domain.User,
 	u := &domain.User{}
 	query := `
 		SELECT id, username, email, password_hash, full_name, role, 
-		       COALESCE(avatar_url, ''), COALESCE(bio, ''),
+		       COALESCE(avatar_url, ''), COALESCE(bio, ''), COALESCE(bio_html, ''),
 		       location_lat, location_lng, COALESCE(location_address, ''),
 		       COALESCE(success_score, 100), COALESCE(books_shared, 0),
 		       COALESCE(books_received, 0), COALESCE(reviews_received, 0),
 		       COALESCE(ideas_posted, 0), COALESCE(total_upvotes, 0),
 		       COALESCE(total_downvotes, 0), COALESCE(is_donor, false),
-		       created_at, updated_at
+		       avg_behavior_rating, avg_book_condition_rating, avg_communication_rating,
+		       preferred_locale, created_at, updated_at
 		FROM users WHERE id = $1
 	`
 	var locationLat, locationLng sql.NullFloat64
+	var avgBehavior, avgBookCondition, avgCommunication sql.NullFloat64
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&u.ID, &u.Username, &u.Email, &u.PasswordHash, &u.FullName, &u.Role,
-		&u.AvatarURL, &u.Bio, &locationLat, &locationLng, &u.LocationAddress,
+		&u.AvatarURL, &u.Bio, &u.BioHTML, &locationLat, &locationLng, &u.LocationAddress,
 		&u.SuccessScore, &u.BooksShared, &u.BooksReceived, &u.ReviewsReceived,
 		&u.IdeasPosted, &u.TotalUpvotes, &u.TotalDownvotes, &u.IsDonor,
-		&u.CreatedAt, &u.UpdatedAt,
+		&avgBehavior, &avgBookCondition, &avgCommunication,
+		&u.PreferredLocale, &u.CreatedAt, &u.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, domain.ErrUserNotFound
 	}
 	u.LocationLat = float64Ptr(locationLat)
 	u.LocationLng = float64Ptr(locationLng)
+	u.AvgBehaviorRating = float64Ptr(avgBehavior)
+	u.AvgBookConditionRating = float64Ptr(avgBookCondition)
+	u.AvgCommunicationRating = float64Ptr(avgCommunication)
 	return u, err
 }
 
@@ -65,22 +71,22 @@ func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*domain
 	u := &domain.User{}
 	query := `
 		SELECT id, username, email, password_hash, full_name, role, 
-		       COALESCE(avatar_url, ''), COALESCE(bio, ''),
+		       COALESCE(avatar_url, ''), COALESCE(bio, ''), COALESCE(bio_html, ''),
 		       location_lat, location_lng, COALESCE(location_address, ''),
 		       COALESCE(success_score, 100), COALESCE(books_shared, 0),
 		       COALESCE(books_received, 0), COALESCE(reviews_received, 0),
 		       COALESCE(ideas_posted, 0), COALESCE(total_upvotes, 0),
 		       COALESCE(total_downvotes, 0), COALESCE(is_donor, false),
-		       created_at, updated_at
+		       preferred_locale, created_at, updated_at
 		FROM users WHERE email = $1
 	`
 	var locationLat, locationLng sql.NullFloat64
 	err := r.db.QueryRowContext(ctx, query, email).Scan(
 		&u.ID, &u.Username, &u.Email, &u.PasswordHash, &u.FullName, &u.Role,
-		&u.AvatarURL, &u.Bio, &locationLat, &locationLng, &u.LocationAddress,
+		&u.AvatarURL, &u.Bio, &u.BioHTML, &locationLat, &locationLng, &u.LocationAddress,
 		&u.SuccessScore, &u.BooksShared, &u.BooksReceived, &u.ReviewsReceived,
 		&u.IdeasPosted, &u.TotalUpvotes, &u.TotalDownvotes, &u.IsDonor,
-		&u.CreatedAt, &u.UpdatedAt,
+		&u.PreferredLocale, &u.CreatedAt, &u.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, domain.ErrUserNotFound
@@ -94,22 +100,22 @@ func (r *UserRepository) FindByUsername(ctx context.Context, username string) (*
 	u := &domain.User{}
 	query := `
 		SELECT id, username, email, password_hash, full_name, role, 
-		       COALESCE(avatar_url, ''), COALESCE(bio, ''),
+		       COALESCE(avatar_url, ''), COALESCE(bio, ''), COALESCE(bio_html, ''),
 		       location_lat, location_lng, COALESCE(location_address, ''),
 		       COALESCE(success_score, 100), COALESCE(books_shared, 0),
 		       COALESCE(books_received, 0), COALESCE(reviews_received, 0),
 		       COALESCE(ideas_posted, 0), COALESCE(total_upvotes, 0),
 		       COALESCE(total_downvotes, 0), COALESCE(is_donor, false),
-		       created_at, updated_at
+		       preferred_locale, created_at, updated_at
 		FROM users WHERE username = $1
 	`
 	var locationLat, locationLng sql.NullFloat64
 	err := r.db.QueryRowContext(ctx, query, username).Scan(
 		&u.ID, &u.Username, &u.Email, &u.PasswordHash, &u.FullName, &u.Role,
-		&u.AvatarURL, &u.Bio, &locationLat, &locationLng, &u.LocationAddress,
+		&u.AvatarURL, &u.Bio, &u.BioHTML, &locationLat, &locationLng, &u.LocationAddress,
 		&u.SuccessScore, &u.BooksShared, &u.BooksReceived, &u.ReviewsReceived,
 		&u.IdeasPosted, &u.TotalUpvotes, &u.TotalDownvotes, &u.IsDonor,
-		&u.CreatedAt, &u.UpdatedAt,
+		&u.PreferredLocale, &u.CreatedAt, &u.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, domain.ErrUserNotFound
@@ -122,13 +128,14 @@ func (r *UserRepository) FindByUsername(ctx context.Context, username string) (*
 func (r *UserRepository) Update(ctx context.Context, id string, u *domain.User) error {
 	query := `
 		UPDATE users SET full_name = $1, bio = $2, avatar_url = $3, 
-		       location_lat = $4, location_lng = $5, location_address = $6, updated_at = $7
+		       location_lat = $4, location_lng = $5, location_address = $6, updated_at = $7,
+		       preferred_locale = COALESCE(NULLIF($9, ''), preferred_locale), bio_html = $10
 		WHERE id = $8
 	`
 	_, err := r.db.ExecContext(ctx, query,
 		u.FullName, u.Bio, u.AvatarURL,
 		nullFloat64(u.LocationLat), nullFloat64(u.LocationLng), u.LocationAddress,
-		u.UpdatedAt, id)
+		u.UpdatedAt, id, u.PreferredLocale, u.BioHTML)
 	return err
 }
 
@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/yourusername/online-library/internal/admin"
 	"github.com/yourusername/online-library/internal/domain"
+	"github.com/yourusername/online-library/internal/rest/middleware"
 	"github.com/yourusername/online-library/internal/rest/response"
 	"go.uber.org/zap"
 )
@@ -40,7 +41,7 @@ func (h *Handler) ApproveBookRequest(c *gin.Context) {
 		return
 	}
 
-	if err := h.adminSvc.ApproveBookRequest(c.Request.Context(), requestID, req.DueDate); err != nil {
+	if err := h.adminSvc.ApproveBookRequest(c.Request.Context(), requestID, middleware.GetUserID(c), req.DueDate); err != nil {
 		response.Error(c, err)
 		return
 	}
@@ -172,7 +173,8 @@ func (h *Handler) GetAllBooks(c *gin.Context) {
 
 // UpdateBookStatus updates a book's status
 type UpdateBookStatusReq struct {
-	Status string `json:"status" binding:"required"`
+	Status string `json:"status" binding:"required,oneof=available reserved on_hold"`
+	Note   string `json:"note" binding:"max=500"`
 }
 
 func (h *Handler) UpdateBookStatus(c *gin.Context) {
@@ -184,7 +186,7 @@ func (h *Handler) UpdateBookStatus(c *gin.Context) {
 	}
 
 	status := domain.BookStatus(req.Status)
-	if err := h.adminSvc.UpdateBookStatus(c.Request.Context(), bookID, status); err != nil {
+	if err := h.adminSvc.UpdateBookStatus(c.Request.Context(), bookID, middleware.GetUserID(c), status, req.Note); err != nil {
 		response.Error(c, err)
 		return
 	}
@@ -213,6 +213,7 @@ func RegisterRoutes(r *gin.RouterGroup, h *Handler) {
 		books.DELETE("/:id/request", h.CancelRequest)
 		books.GET("/:id/requested", h.CheckBookRequested)
 		books.POST("/:id/return", h.ReturnBook)
+		books.GET("/:id/timeline", h.GetTimeline)
 	}
 
 	// User's book requests and history
@@ -233,6 +234,20 @@ func (h *Handler) ReturnBook(c *gin.Context) {
 	response.Success(c, gin.H{"message": "book returned successfully"})
 }
 
+// GetTimeline returns a book's custody history
+func (h *Handler) GetTimeline(c *gin.Context) {
+	id := c.Param("id")
+	isAdmin := middleware.GetUserRole(c) == string(domain.RoleAdmin)
+
+	events, err := h.bookSvc.GetTimeline(c.Request.Context(), id, middleware.GetUserID(c), isAdmin)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, events)
+}
+
 func (h *Handler) GetReadingHistory(c *gin.Context) {
 	userID := middleware.GetUserID(c)
 
@@ -18,10 +18,13 @@ func NewHandler(bookmarkSvc bookmark.Service, log *zap.Logger) *Handler {
 	return &Handler{bookmarkSvc: bookmarkSvc, log: log}
 }
 
+// CreateBookmarkRequest adds a book to a list. Wishlist entries are
+// watches; auto_request only applies to them.
 type CreateBookmarkRequest struct {
 	BookID        string `json:"book_id" binding:"required"`
-	BookmarkType  string `json:"bookmark_type" binding:"required"`
-	PriorityLevel int    `json:"priority_level"`
+	BookmarkType  string `json:"bookmark_type" binding:"required,oneof=wishlist favorite reading"`
+	PriorityLevel int    `json:"priority_level" binding:"min=0,max=5"`
+	AutoRequest   bool   `json:"auto_request"`
 }
 
 func (h *Handler) Create(c *gin.Context) {
@@ -37,6 +40,7 @@ func (h *Handler) Create(c *gin.Context) {
 		BookID:        req.BookID,
 		BookmarkType:  domain.BookmarkType(req.BookmarkType),
 		PriorityLevel: req.PriorityLevel,
+		AutoRequest:   req.AutoRequest,
 	}
 
 	created, err := h.bookmarkSvc.Create(c.Request.Context(), bookmark)
@@ -76,8 +80,30 @@ func (h *Handler) GetByUser(c *gin.Context) {
 	response.Success(c, bookmarks)
 }
 
+type UpdateWatchRequest struct {
+	PriorityLevel int  `json:"priority_level" binding:"min=0,max=5"`
+	AutoRequest   bool `json:"auto_request"`
+}
+
+// UpdateWatch changes the caller's wishlist entry for a book
+func (h *Handler) UpdateWatch(c *gin.Context) {
+	var req UpdateWatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	bookmark, err := h.bookmarkSvc.UpdateWatch(c.Request.Context(), middleware.GetUserID(c), c.Param("bookId"), req.PriorityLevel, req.AutoRequest)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, bookmark)
+}
+
 func RegisterRoutes(r *gin.RouterGroup, h *Handler) {
 	r.POST("/bookmarks", h.Create)
 	r.DELETE("/bookmarks/:bookId", h.Delete)
 	r.GET("/bookmarks", h.GetByUser)
+	r.PUT("/bookmarks/:bookId/watch", h.UpdateWatch)
 }
@@ -1,6 +1,13 @@
 package donationhandler
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"github.com/yourusername/online-library/internal/domain"
 	"github.com/yourusername/online-library/internal/donation"
@@ -18,13 +25,24 @@ func NewHandler(donationSvc donation.Service, log *zap.Logger) *Handler {
 	return &Handler{donationSvc: donationSvc, log: log}
 }
 
+// PledgeRequest describes the book a donor will bring in
+type PledgeRequest struct {
+	Title       string `json:"title" binding:"required,max=500"`
+	Author      string `json:"author" binding:"required,max=255"`
+	ISBN        string `json:"isbn" binding:"max=20"`
+	Category    string `json:"category" binding:"max=100"`
+	Description string `json:"description"`
+	Condition   string `json:"condition" binding:"omitempty,oneof=new good fair poor"`
+}
+
 type CreateDonationRequest struct {
-	DonationType string   `json:"donation_type" binding:"required"`
-	BookID       string   `json:"book_id"`
-	Amount       *float64 `json:"amount"`
-	Currency     string   `json:"currency"`
-	Message      string   `json:"message"`
-	IsPublic     bool     `json:"is_public"`
+	DonationType string         `json:"donation_type" binding:"required,oneof=book money"`
+	Pledge       *PledgeRequest `json:"pledge" binding:"required_if=DonationType book"`
+	Amount       *float64       `json:"amount"`
+	Currency     string         `json:"currency"`
+	Message      string         `json:"message"`
+	IsPublic     bool           `json:"is_public"`
+	CampaignID   *string        `json:"campaign_id" binding:"omitempty,uuid"`
 }
 
 func (h *Handler) Create(c *gin.Context) {
@@ -35,19 +53,24 @@ func (h *Handler) Create(c *gin.Context) {
 	}
 
 	userID := middleware.GetUserID(c)
-	var bookID *string
-	if req.BookID != "" {
-		bookID = &req.BookID
-	}
-
 	donation := &domain.Donation{
 		DonorID:      userID,
 		DonationType: domain.DonationType(req.DonationType),
-		BookID:       bookID,
 		Amount:       req.Amount,
 		Currency:     req.Currency,
 		Message:      req.Message,
 		IsPublic:     req.IsPublic,
+		CampaignID:   req.CampaignID,
+	}
+	if req.Pledge != nil {
+		donation.Pledge = &domain.BookPledge{
+			Title:       req.Pledge.Title,
+			Author:      req.Pledge.Author,
+			ISBN:        req.Pledge.ISBN,
+			Category:    req.Pledge.Category,
+			Description: req.Pledge.Description,
+			Condition:   req.Pledge.Condition,
+		}
 	}
 
 	created, err := h.donationSvc.Create(c.Request.Context(), donation)
@@ -68,7 +91,431 @@ func (h *Handler) List(c *gin.Context) {
 	response.Success(c, donations)
 }
 
+// ListMine returns the caller's donations and pledges in any status
+func (h *Handler) ListMine(c *gin.Context) {
+	donations, err := h.donationSvc.ListByDonor(c.Request.Context(), middleware.GetUserID(c))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, donations)
+}
+
+// CancelPledge withdraws a book pledge that hasn't been received yet
+func (h *Handler) CancelPledge(c *gin.Context) {
+	if err := h.donationSvc.CancelPledge(c.Request.Context(), c.Param("id"), middleware.GetUserID(c)); err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "pledge withdrawn"})
+}
+
+// ListIntake returns book donations in one intake status (?status=pledged
+// by default), oldest first
+func (h *Handler) ListIntake(c *gin.Context) {
+	status := domain.DonationStatus(c.DefaultQuery("status", string(domain.DonationPledged)))
+	donations, err := h.donationSvc.ListByStatus(c.Request.Context(), status, 100, 0)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, donations)
+}
+
+func (h *Handler) MarkReceived(c *gin.Context) {
+	donation, err := h.donationSvc.MarkReceived(c.Request.Context(), c.Param("id"), middleware.GetUserID(c))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, donation)
+}
+
+// CatalogueRequest shelves a received book. Empty fields fall back to the
+// donor's pledge.
+type CatalogueRequest struct {
+	PhysicalCode   string   `json:"physical_code" binding:"required,max=50"`
+	MaxReadingDays int      `json:"max_reading_days" binding:"omitempty,min=1,max=365"`
+	Title          string   `json:"title" binding:"max=500"`
+	Author         string   `json:"author" binding:"max=255"`
+	ISBN           string   `json:"isbn" binding:"max=20"`
+	CoverURL       string   `json:"cover_url"`
+	Category       string   `json:"category" binding:"max=100"`
+	Description    string   `json:"description"`
+	Tags           []string `json:"tags"`
+	Topics         []string `json:"topics"`
+}
+
+func (h *Handler) Catalogue(c *gin.Context) {
+	var req CatalogueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	book, err := h.donationSvc.Catalogue(c.Request.Context(), c.Param("id"), middleware.GetUserID(c), donation.CatalogueEntry{
+		PhysicalCode:   req.PhysicalCode,
+		MaxReadingDays: req.MaxReadingDays,
+		Title:          req.Title,
+		Author:         req.Author,
+		ISBN:           req.ISBN,
+		CoverURL:       req.CoverURL,
+		Category:       req.Category,
+		Description:    req.Description,
+		Tags:           req.Tags,
+		Topics:         req.Topics,
+	})
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Created(c, book)
+}
+
+type DeclineRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+func (h *Handler) Decline(c *gin.Context) {
+	var req DeclineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	if err := h.donationSvc.Decline(c.Request.Context(), c.Param("id"), middleware.GetUserID(c), req.Reason); err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "donation declined"})
+}
+
+// Webhook receives payment provider callbacks. It is unauthenticated; the
+// provider's signature is checked instead.
+func (h *Handler) Webhook(c *gin.Context) {
+	payload, err := io.ReadAll(io.LimitReader(c.Request.Body, 1<<20))
+	if err != nil {
+		response.BadRequest(c, "could not read body")
+		return
+	}
+
+	signature := c.GetHeader(h.donationSvc.PaymentSignatureHeader())
+	if err := h.donationSvc.HandleWebhook(c.Request.Context(), c.Param("provider"), payload, signature); err != nil {
+		if errors.Is(err, donation.ErrInvalidSignature) {
+			response.Unauthorized(c, "invalid signature")
+			return
+		}
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, gin.H{"received": true})
+}
+
+type RefundRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+func (h *Handler) Refund(c *gin.Context) {
+	var req RefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	donation, err := h.donationSvc.Refund(c.Request.Context(), c.Param("id"), middleware.GetUserID(c), req.Reason)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, donation)
+}
+
+// ListCampaigns returns running and upcoming campaigns with their progress;
+// ?ended=true includes past ones
+func (h *Handler) ListCampaigns(c *gin.Context) {
+	campaigns, err := h.donationSvc.ListCampaigns(c.Request.Context(), c.Query("ended") == "true")
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, campaigns)
+}
+
+func (h *Handler) GetCampaign(c *gin.Context) {
+	campaign, err := h.donationSvc.GetCampaign(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, campaign)
+}
+
+// CampaignRequest sets a money goal (goal_amount, currency) or a book goal
+// (goal_books) depending on goal_type
+type CampaignRequest struct {
+	Title       string    `json:"title" binding:"required,max=255"`
+	Description string    `json:"description" binding:"max=5000"`
+	GoalType    string    `json:"goal_type" binding:"required,oneof=book money"`
+	GoalAmount  *float64  `json:"goal_amount" binding:"omitempty,gt=0"`
+	GoalBooks   *int      `json:"goal_books" binding:"omitempty,min=1"`
+	Currency    string    `json:"currency" binding:"omitempty,len=3"`
+	Category    string    `json:"category" binding:"max=100"`
+	StartsAt    time.Time `json:"starts_at" binding:"required"`
+	EndsAt      time.Time `json:"ends_at" binding:"required,gtfield=StartsAt"`
+}
+
+func (r *CampaignRequest) campaign() *domain.Campaign {
+	return &domain.Campaign{
+		Title:       r.Title,
+		Description: r.Description,
+		GoalType:    domain.DonationType(r.GoalType),
+		GoalAmount:  r.GoalAmount,
+		GoalBooks:   r.GoalBooks,
+		Currency:    r.Currency,
+		Category:    r.Category,
+		StartsAt:    r.StartsAt,
+		EndsAt:      r.EndsAt,
+	}
+}
+
+func (h *Handler) CreateCampaign(c *gin.Context) {
+	var req CampaignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	campaign := req.campaign()
+	campaign.CreatedBy = middleware.GetUserID(c)
+	created, err := h.donationSvc.CreateCampaign(c.Request.Context(), campaign)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Created(c, created)
+}
+
+func (h *Handler) UpdateCampaign(c *gin.Context) {
+	var req CampaignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	campaign := req.campaign()
+	campaign.ID = c.Param("id")
+	updated, err := h.donationSvc.UpdateCampaign(c.Request.Context(), campaign)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, updated)
+}
+
+// ListReceipts returns the caller's receipts, oldest first
+func (h *Handler) ListReceipts(c *gin.Context) {
+	receipts, err := h.donationSvc.ListReceipts(c.Request.Context(), middleware.GetUserID(c))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, receipts)
+}
+
+// DownloadReceipt sends a donation's receipt as PDF to its donor or an
+// admin
+func (h *Handler) DownloadReceipt(c *gin.Context) {
+	isAdmin := middleware.GetUserRole(c) == string(domain.RoleAdmin)
+	receipt, pdf, err := h.donationSvc.ReceiptPDF(c.Request.Context(), c.Param("id"), middleware.GetUserID(c), isAdmin)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	sendPDF(c, "receipt-"+receipt.Code+".pdf", pdf)
+}
+
+// DownloadStatement sends the caller's statement for a calendar year
+func (h *Handler) DownloadStatement(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		response.BadRequest(c, "invalid year")
+		return
+	}
+	userID := middleware.GetUserID(c)
+	pdf, err := h.donationSvc.StatementPDF(c.Request.Context(), userID, year)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	sendPDF(c, fmt.Sprintf("donation-statement-%d.pdf", year), pdf)
+}
+
+func (h *Handler) RegenerateReceipt(c *gin.Context) {
+	receipt, err := h.donationSvc.RegenerateReceipt(c.Request.Context(), c.Param("id"), middleware.GetUserID(c))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, receipt)
+}
+
+// DownloadDonorStatement sends any donor's statement to an admin
+func (h *Handler) DownloadDonorStatement(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		response.BadRequest(c, "invalid year")
+		return
+	}
+	pdf, err := h.donationSvc.StatementPDF(c.Request.Context(), c.Param("userId"), year)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	sendPDF(c, fmt.Sprintf("donation-statement-%d.pdf", year), pdf)
+}
+
+func (h *Handler) RegenerateStatement(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		response.BadRequest(c, "invalid year")
+		return
+	}
+	statement, err := h.donationSvc.RegenerateStatement(c.Request.Context(), c.Param("userId"), year, middleware.GetUserID(c))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, statement)
+}
+
+// DonorWall shows public donations and top donors for ?period=month (the
+// default), year or all. Money is ranked in ?currency, BDT by default.
+func (h *Handler) DonorWall(c *gin.Context) {
+	period := domain.WallPeriod(c.DefaultQuery("period", string(domain.WallMonth)))
+	wall, err := h.donationSvc.DonorWall(c.Request.Context(), period, c.Query("currency"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, wall)
+}
+
+// Ledger returns the transparency ledger for ?year, the current one by
+// default
+func (h *Handler) Ledger(c *gin.Context) {
+	year := time.Now().Year()
+	if y := c.Query("year"); y != "" {
+		var err error
+		if year, err = strconv.Atoi(y); err != nil {
+			response.BadRequest(c, "invalid year")
+			return
+		}
+	}
+	ledger, err := h.donationSvc.Ledger(c.Request.Context(), year)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, ledger)
+}
+
+type OutflowRequest struct {
+	Amount      float64 `json:"amount" binding:"required,gt=0"`
+	Currency    string  `json:"currency" binding:"omitempty,len=3"`
+	Category    string  `json:"category" binding:"required,oneof=books shipping operations events other"`
+	Description string  `json:"description" binding:"required,max=1000"`
+	// SpentOn is a date, 2006-01-02
+	SpentOn    string  `json:"spent_on" binding:"required,datetime=2006-01-02"`
+	CampaignID *string `json:"campaign_id" binding:"omitempty,uuid"`
+}
+
+func (h *Handler) RecordOutflow(c *gin.Context) {
+	var req OutflowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	spentOn, err := time.Parse("2006-01-02", req.SpentOn)
+	if err != nil {
+		response.BadRequest(c, "invalid spent_on")
+		return
+	}
+
+	outflow, err := h.donationSvc.RecordOutflow(c.Request.Context(), &domain.LedgerOutflow{
+		Amount:      req.Amount,
+		Currency:    req.Currency,
+		Category:    domain.OutflowCategory(req.Category),
+		Description: req.Description,
+		SpentOn:     spentOn,
+		CampaignID:  req.CampaignID,
+		RecordedBy:  middleware.GetUserID(c),
+	})
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Created(c, outflow)
+}
+
+type VoidOutflowRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+func (h *Handler) VoidOutflow(c *gin.Context) {
+	var req VoidOutflowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	outflow, err := h.donationSvc.VoidOutflow(c.Request.Context(), c.Param("id"), middleware.GetUserID(c), req.Reason)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, outflow)
+}
+
+func sendPDF(c *gin.Context, filename string, pdf []byte) {
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+func RegisterPublicRoutes(r *gin.RouterGroup, h *Handler) {
+	r.POST("/payments/webhook/:provider", h.Webhook)
+	r.GET("/campaigns", h.ListCampaigns)
+	r.GET("/campaigns/:id", h.GetCampaign)
+	r.GET("/donations/wall", h.DonorWall)
+	r.GET("/ledger", h.Ledger)
+}
+
 func RegisterRoutes(r *gin.RouterGroup, h *Handler) {
 	r.POST("/donations", h.Create)
 	r.GET("/donations", h.List)
+	r.GET("/donations/mine", h.ListMine)
+	r.POST("/donations/:id/cancel", h.CancelPledge)
+	r.GET("/donations/receipts", h.ListReceipts)
+	r.GET("/donations/:id/receipt", h.DownloadReceipt)
+	r.GET("/donations/statements/:year", h.DownloadStatement)
+}
+
+func RegisterAdminRoutes(r *gin.RouterGroup, h *Handler) {
+	intake := r.Group("/admin/donations")
+	{
+		intake.GET("", h.ListIntake)
+		intake.POST("/:id/receive", h.MarkReceived)
+		intake.POST("/:id/catalogue", h.Catalogue)
+		intake.POST("/:id/decline", h.Decline)
+		intake.POST("/:id/refund", h.Refund)
+		intake.POST("/:id/receipt/regenerate", h.RegenerateReceipt)
+	}
+
+	r.GET("/admin/donors/:userId/statements/:year", h.DownloadDonorStatement)
+	r.POST("/admin/donors/:userId/statements/:year/regenerate", h.RegenerateStatement)
+
+	r.POST("/admin/campaigns", h.CreateCampaign)
+	r.PUT("/admin/campaigns/:id", h.UpdateCampaign)
+
+	r.POST("/admin/ledger/outflows", h.RecordOutflow)
+	r.POST("/admin/ledger/outflows/:id/void", h.VoidOutflow)
 }
@@ -2,13 +2,19 @@ package handover
 
 import (
 	"fmt"
+	"io"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/yourusername/online-library/internal/domain"
 	"github.com/yourusername/online-library/internal/handover"
 	"github.com/yourusername/online-library/internal/rest/response"
 	"go.uber.org/zap"
 )
 
+// streamHeartbeat is how often an idle stream sends a keep-alive comment
+const streamHeartbeat = 25 * time.Second
+
 type Handler struct {
 	handoverSvc handover.Service
 	log         *zap.Logger
@@ -114,8 +120,9 @@ func (h *Handler) PostHandoverMessage(c *gin.Context) {
 // GET /api/v1/handover/threads/:id/messages
 func (h *Handler) GetHandoverMessages(c *gin.Context) {
 	threadID := c.Param("id")
+	userID := c.GetString("user_id")
 
-	messages, err := h.handoverSvc.GetHandoverMessages(c.Request.Context(), threadID)
+	messages, err := h.handoverSvc.GetHandoverMessages(c.Request.Context(), threadID, userID)
 	if err != nil {
 		h.log.Error("failed to get handover messages", zap.Error(err))
 		response.Error(c, fmt.Errorf("failed to get messages"))
@@ -125,6 +132,61 @@ func (h *Handler) GetHandoverMessages(c *gin.Context) {
 	response.Success(c, messages)
 }
 
+type CancelThreadRequest struct {
+	Reason string `json:"reason" binding:"required,oneof=withdrawn unresponsive other"`
+	Note   string `json:"note" binding:"max=500"`
+}
+
+// CancelThread lets a participant call off an active handover
+// POST /api/v1/handover/threads/:id/cancel
+func (h *Handler) CancelThread(c *gin.Context) {
+	userID := c.GetString("user_id")
+	threadID := c.Param("id")
+
+	var req CancelThreadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	thread, err := h.handoverSvc.CancelThread(c.Request.Context(), threadID, userID, domain.HandoverCancelReason(req.Reason), req.Note)
+	if err != nil {
+		h.log.Error("failed to cancel handover thread", zap.Error(err))
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, thread)
+}
+
+type AdminCancelThreadRequest struct {
+	CancelThreadRequest
+	// AtFaultID optionally names the participant held responsible
+	AtFaultID string `json:"at_fault_id"`
+}
+
+// AdminCancelThread cancels a handover on behalf of its participants
+// POST /api/v1/admin/handover/threads/:id/cancel
+func (h *Handler) AdminCancelThread(c *gin.Context) {
+	adminID := c.GetString("user_id")
+	threadID := c.Param("id")
+
+	var req AdminCancelThreadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	thread, err := h.handoverSvc.AdminCancelThread(c.Request.Context(), threadID, adminID, domain.HandoverCancelReason(req.Reason), req.Note, req.AtFaultID)
+	if err != nil {
+		h.log.Error("failed to cancel handover thread", zap.Error(err))
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, thread)
+}
+
 // GetReadingHistoryExtended gets extended reading history for current holder
 // GET /api/v1/books/:id/reading-status
 func (h *Handler) GetReadingHistoryExtended(c *gin.Context) {
@@ -141,6 +203,45 @@ func (h *Handler) GetReadingHistoryExtended(c *gin.Context) {
 	response.Success(c, history)
 }
 
+// StreamHandoverThread pushes a thread's messages and status changes as
+// Server-Sent Events. Resume with ?last_message_id= or Last-Event-ID.
+// GET /api/v1/handover/threads/:id/stream
+func (h *Handler) StreamHandoverThread(c *gin.Context) {
+	userID := c.GetString("user_id")
+	threadID := c.Param("id")
+
+	lastMessageID := c.Query("last_message_id")
+	if lastMessageID == "" {
+		lastMessageID = c.GetHeader("Last-Event-ID")
+	}
+
+	events, err := h.handoverSvc.StreamThread(c.Request.Context(), threadID, userID, lastMessageID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	response.StartSSE(c)
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				return false
+			}
+			id := ""
+			if ev.Message != nil {
+				id = ev.Message.ID
+			}
+			return response.SSEvent(w, id, string(ev.Type), ev) == nil
+		case <-heartbeat.C:
+			return response.SSEHeartbeat(w) == nil
+		}
+	})
+}
+
 // RegisterRoutes registers handover routes
 func RegisterRoutes(router *gin.RouterGroup, h *Handler) {
 	books := router.Group("/books")
@@ -156,5 +257,16 @@ func RegisterRoutes(router *gin.RouterGroup, h *Handler) {
 		handover.GET("/threads", h.GetUserHandoverThreads)
 		handover.POST("/threads/:id/messages", h.PostHandoverMessage)
 		handover.GET("/threads/:id/messages", h.GetHandoverMessages)
+		handover.POST("/threads/:id/cancel", h.CancelThread)
 	}
 }
+
+func RegisterAdminRoutes(router *gin.RouterGroup, h *Handler) {
+	router.POST("/admin/handover/threads/:id/cancel", h.AdminCancelThread)
+}
+
+// RegisterStreamRoutes registers long-lived streaming endpoints, which are
+// authenticated with StreamAuthMiddleware
+func RegisterStreamRoutes(router *gin.RouterGroup, h *Handler) {
+	router.GET("/handover/threads/:id/stream", h.StreamHandoverThread)
+}
@@ -1,6 +1,9 @@
 package ideahandler
 
 import (
+	"strconv"
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"github.com/yourusername/online-library/internal/domain"
 	"github.com/yourusername/online-library/internal/idea"
@@ -58,6 +61,69 @@ func (h *Handler) GetByBook(c *gin.Context) {
 	response.Success(c, ideas)
 }
 
+// Feed lists ideas across all books: ?sort=top (last week's by net votes),
+// new or best (Wilson score)
+func (h *Handler) Feed(c *gin.Context) {
+	sort := domain.IdeaFeedSort(c.DefaultQuery("sort", string(domain.IdeaFeedBest)))
+	if !sort.Valid() {
+		response.BadRequest(c, "sort must be top, new or best")
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	ideas, err := h.ideaSvc.Feed(c.Request.Context(), sort, limit, offset)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, ideas)
+}
+
+type UpdateIdeaRequest struct {
+	Title   string `json:"title" binding:"required,max=500"`
+	Content string `json:"content" binding:"required"`
+}
+
+func (h *Handler) Update(c *gin.Context) {
+	var req UpdateIdeaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	idea, err := h.ideaSvc.Update(c.Request.Context(), c.Param("id"), middleware.GetUserID(c), req.Title, req.Content)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, idea)
+}
+
+func (h *Handler) ListRevisions(c *gin.Context) {
+	revisions, err := h.ideaSvc.ListRevisions(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, revisions)
+}
+
+func (h *Handler) Delete(c *gin.Context) {
+	isAdmin := middleware.GetUserRole(c) == string(domain.RoleAdmin)
+	if err := h.ideaSvc.Delete(c.Request.Context(), c.Param("id"), middleware.GetUserID(c), isAdmin); err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "idea deleted"})
+}
+
 func (h *Handler) Vote(c *gin.Context) {
 	ideaID := c.Param("id")
 	userID := middleware.GetUserID(c)
@@ -74,8 +140,165 @@ func (h *Handler) Vote(c *gin.Context) {
 	response.Success(c, gin.H{"message": "vote recorded"})
 }
 
+type SetVoteRequest struct {
+	VoteType string `json:"vote_type" binding:"required,oneof=upvote downvote"`
+}
+
+func (h *Handler) SetVote(c *gin.Context) {
+	var req SetVoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	if err := h.ideaSvc.SetVote(c.Request.Context(), c.Param("id"), middleware.GetUserID(c), domain.VoteType(req.VoteType)); err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "vote recorded"})
+}
+
+func (h *Handler) RetractVote(c *gin.Context) {
+	if err := h.ideaSvc.RetractVote(c.Request.Context(), c.Param("id"), middleware.GetUserID(c)); err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "vote retracted"})
+}
+
+func (h *Handler) ListComments(c *gin.Context) {
+	comments, err := h.ideaSvc.ListComments(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, comments)
+}
+
+type CommentRequest struct {
+	Content  string `json:"content" binding:"required,max=2000"`
+	ParentID string `json:"parent_id"`
+}
+
+func (h *Handler) AddComment(c *gin.Context) {
+	var req CommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	comment := &domain.IdeaComment{
+		IdeaID:  c.Param("id"),
+		UserID:  middleware.GetUserID(c),
+		Content: req.Content,
+	}
+	if req.ParentID != "" {
+		comment.ParentID = &req.ParentID
+	}
+
+	created, err := h.ideaSvc.AddComment(c.Request.Context(), comment)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Created(c, created)
+}
+
+type EditCommentRequest struct {
+	Content string `json:"content" binding:"required,max=2000"`
+}
+
+func (h *Handler) EditComment(c *gin.Context) {
+	var req EditCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	comment, err := h.ideaSvc.EditComment(c.Request.Context(), c.Param("commentId"), middleware.GetUserID(c), req.Content)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, comment)
+}
+
+func (h *Handler) DeleteComment(c *gin.Context) {
+	isAdmin := middleware.GetUserRole(c) == string(domain.RoleAdmin)
+	if err := h.ideaSvc.DeleteComment(c.Request.Context(), c.Param("commentId"), middleware.GetUserID(c), isAdmin); err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "comment deleted"})
+}
+
+// DetectVoteRings scans upvotes from the last ?days=N days (default 30)
+// and flags groups of users voting for each other.
+func (h *Handler) DetectVoteRings(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil || days <= 0 {
+		response.BadRequest(c, "days must be a positive integer")
+		return
+	}
+
+	rings, err := h.ideaSvc.DetectVoteRings(c.Request.Context(), time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, rings)
+}
+
+func (h *Handler) ListVoteRings(c *gin.Context) {
+	status := domain.VoteRingStatus(c.DefaultQuery("status", string(domain.VoteRingPending)))
+	rings, err := h.ideaSvc.ListVoteRings(c.Request.Context(), status)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, rings)
+}
+
+type ReviewVoteRingRequest struct {
+	Status string `json:"status" binding:"required"`
+}
+
+func (h *Handler) ReviewVoteRing(c *gin.Context) {
+	var req ReviewVoteRingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	adminID := middleware.GetUserID(c)
+	if err := h.ideaSvc.ReviewVoteRing(c.Request.Context(), c.Param("id"), adminID, domain.VoteRingStatus(req.Status)); err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "vote ring reviewed"})
+}
+
 func RegisterRoutes(r *gin.RouterGroup, h *Handler) {
 	r.POST("/ideas", h.Create)
+	r.GET("/ideas", h.Feed)
 	r.GET("/ideas/book/:bookId", h.GetByBook)
+	r.PUT("/ideas/:id", h.Update)
+	r.DELETE("/ideas/:id", h.Delete)
+	r.GET("/ideas/:id/revisions", h.ListRevisions)
 	r.POST("/ideas/:id/vote", h.Vote)
+	r.PUT("/ideas/:id/vote", h.SetVote)
+	r.DELETE("/ideas/:id/vote", h.RetractVote)
+	r.GET("/ideas/:id/comments", h.ListComments)
+	r.POST("/ideas/:id/comments", h.AddComment)
+	r.PUT("/ideas/comments/:commentId", h.EditComment)
+	r.DELETE("/ideas/comments/:commentId", h.DeleteComment)
+}
+
+func RegisterAdminRoutes(r *gin.RouterGroup, h *Handler) {
+	rings := r.Group("/admin/vote-rings")
+	{
+		rings.GET("", h.ListVoteRings)
+		rings.POST("/detect", h.DetectVoteRings)
+		rings.PATCH("/:id", h.ReviewVoteRing)
+	}
 }
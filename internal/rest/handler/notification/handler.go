@@ -1,13 +1,21 @@
 package notificationhandler
 
 import (
+	"io"
+	"strconv"
+	"time"
+
 	"github.com/gin-gonic/gin"
+	"github.com/yourusername/online-library/internal/domain"
 	"github.com/yourusername/online-library/internal/notification"
 	"github.com/yourusername/online-library/internal/rest/middleware"
 	"github.com/yourusername/online-library/internal/rest/response"
 	"go.uber.org/zap"
 )
 
+// streamHeartbeat is how often an idle stream sends a keep-alive comment
+const streamHeartbeat = 25 * time.Second
+
 type Handler struct {
 	notificationSvc notification.Service
 	log             *zap.Logger
@@ -17,10 +25,12 @@ func NewHandler(notificationSvc notification.Service, log *zap.Logger) *Handler
 	return &Handler{notificationSvc: notificationSvc, log: log}
 }
 
+// GetUserNotifications lists the inbox, or the archive with ?archived=true
 func (h *Handler) GetUserNotifications(c *gin.Context) {
 	userID := middleware.GetUserID(c)
+	archived := c.Query("archived") == "true"
 
-	notifications, err := h.notificationSvc.GetUserNotifications(c.Request.Context(), userID, 50)
+	notifications, err := h.notificationSvc.GetUserNotifications(c.Request.Context(), userID, archived, 50)
 	if err != nil {
 		response.Error(c, err)
 		return
@@ -30,9 +40,10 @@ func (h *Handler) GetUserNotifications(c *gin.Context) {
 }
 
 func (h *Handler) MarkAsRead(c *gin.Context) {
+	userID := middleware.GetUserID(c)
 	notificationID := c.Param("id")
 
-	if err := h.notificationSvc.MarkAsRead(c.Request.Context(), notificationID); err != nil {
+	if err := h.notificationSvc.MarkAsRead(c.Request.Context(), userID, notificationID); err != nil {
 		response.Error(c, err)
 		return
 	}
@@ -40,6 +51,53 @@ func (h *Handler) MarkAsRead(c *gin.Context) {
 	response.Success(c, gin.H{"message": "notification marked as read"})
 }
 
+func (h *Handler) Archive(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	notificationID := c.Param("id")
+
+	if err := h.notificationSvc.Archive(c.Request.Context(), userID, notificationID); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "notification archived"})
+}
+
+func (h *Handler) Delete(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	notificationID := c.Param("id")
+
+	if err := h.notificationSvc.Delete(c.Request.Context(), userID, notificationID); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "notification deleted"})
+}
+
+type BulkRequest struct {
+	Action string   `json:"action" binding:"required,oneof=read unread archive unarchive delete"`
+	IDs    []string `json:"ids" binding:"required,min=1,max=200"`
+}
+
+func (h *Handler) Bulk(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	var req BulkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	updated, err := h.notificationSvc.Bulk(c.Request.Context(), userID, notification.BulkAction(req.Action), req.IDs)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"updated": updated})
+}
+
 func (h *Handler) MarkAllAsRead(c *gin.Context) {
 	userID := middleware.GetUserID(c)
 
@@ -51,8 +109,196 @@ func (h *Handler) MarkAllAsRead(c *gin.Context) {
 	response.Success(c, gin.H{"message": "all notifications marked as read"})
 }
 
+func (h *Handler) GetUnreadCount(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	count, err := h.notificationSvc.CountUnread(c.Request.Context(), userID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"count": count})
+}
+
+// Stream pushes new notifications as Server-Sent Events. Each event's id
+// is the notification ID, so a reconnecting client that sends
+// Last-Event-ID receives whatever it missed.
+func (h *Handler) Stream(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	notifications, err := h.notificationSvc.Stream(c.Request.Context(), userID, c.GetHeader("Last-Event-ID"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	response.StartSSE(c)
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case n, ok := <-notifications:
+			if !ok {
+				return false
+			}
+			return response.SSEvent(w, n.ID, "notification", n) == nil
+		case <-heartbeat.C:
+			return response.SSEHeartbeat(w) == nil
+		}
+	})
+}
+
+func (h *Handler) GetPreferences(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	prefs, err := h.notificationSvc.GetPreferences(c.Request.Context(), userID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, prefs)
+}
+
+type UpdatePreferencesRequest struct {
+	Preferences []*domain.NotificationPreference `json:"preferences" binding:"required,dive"`
+}
+
+func (h *Handler) UpdatePreferences(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	var req UpdatePreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	if err := h.notificationSvc.UpdatePreferences(c.Request.Context(), userID, req.Preferences); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	prefs, err := h.notificationSvc.GetPreferences(c.Request.Context(), userID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, prefs)
+}
+
+type UpdateSettingsRequest struct {
+	SMSNumber       string `json:"sms_number"`
+	QuietHoursStart string `json:"quiet_hours_start"`
+	QuietHoursEnd   string `json:"quiet_hours_end"`
+	Timezone        string `json:"timezone"`
+	DigestFrequency string `json:"digest_frequency"`
+}
+
+func (h *Handler) UpdateSettings(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	var req UpdateSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	settings := &domain.NotificationSettings{
+		UserID:          userID,
+		SMSNumber:       req.SMSNumber,
+		QuietHoursStart: req.QuietHoursStart,
+		QuietHoursEnd:   req.QuietHoursEnd,
+		Timezone:        req.Timezone,
+		DigestFrequency: domain.DigestFrequency(req.DigestFrequency),
+	}
+	if err := h.notificationSvc.UpdateSettings(c.Request.Context(), settings); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, settings)
+}
+
+type PushTokenRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Platform string `json:"platform"`
+}
+
+func (h *Handler) RegisterPushToken(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	var req PushTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	if err := h.notificationSvc.RegisterPushToken(c.Request.Context(), userID, req.Token, req.Platform); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Created(c, gin.H{"message": "push token registered"})
+}
+
+func (h *Handler) UnregisterPushToken(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	var req PushTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	if err := h.notificationSvc.UnregisterPushToken(c.Request.Context(), userID, req.Token); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "push token removed"})
+}
+
+// ListDeliveries shows the email/SMS/push outbox, optionally filtered by
+// status, for diagnosing delivery failures
+func (h *Handler) ListDeliveries(c *gin.Context) {
+	status := domain.OutboxStatus(c.Query("status"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+
+	deliveries, err := h.notificationSvc.ListDeliveries(c.Request.Context(), status, limit)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, deliveries)
+}
+
 func RegisterRoutes(r *gin.RouterGroup, h *Handler) {
 	r.GET("/notifications", h.GetUserNotifications)
+	r.GET("/notifications/unread-count", h.GetUnreadCount)
 	r.PUT("/notifications/:id/read", h.MarkAsRead)
 	r.PUT("/notifications/read-all", h.MarkAllAsRead)
+	r.PUT("/notifications/:id/archive", h.Archive)
+	r.DELETE("/notifications/:id", h.Delete)
+	r.POST("/notifications/bulk", h.Bulk)
+	r.GET("/notifications/preferences", h.GetPreferences)
+	r.PUT("/notifications/preferences", h.UpdatePreferences)
+	r.PUT("/notifications/settings", h.UpdateSettings)
+	r.POST("/notifications/push-tokens", h.RegisterPushToken)
+	r.DELETE("/notifications/push-tokens", h.UnregisterPushToken)
+}
+
+func RegisterAdminRoutes(r *gin.RouterGroup, h *Handler) {
+	r.GET("/admin/notifications/outbox", h.ListDeliveries)
+}
+
+// RegisterStreamRoutes registers long-lived streaming endpoints, which are
+// authenticated with StreamAuthMiddleware
+func RegisterStreamRoutes(r *gin.RouterGroup, h *Handler) {
+	r.GET("/notifications/stream", h.Stream)
 }
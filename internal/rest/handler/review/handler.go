@@ -18,12 +18,13 @@ func NewHandler(reviewSvc review.Service, log *zap.Logger) *Handler {
 	return &Handler{reviewSvc: reviewSvc, log: log}
 }
 
+// CreateReviewRequest reviews the other participant of a completed
+// handover; the reviewee and book are taken from the thread.
 type CreateReviewRequest struct {
-	RevieweeID          string `json:"reviewee_id" binding:"required"`
-	BookID              string `json:"book_id"`
-	BehaviorRating      *int   `json:"behavior_rating"`
-	BookConditionRating *int   `json:"book_condition_rating"`
-	CommunicationRating *int   `json:"communication_rating"`
+	HandoverThreadID    string `json:"handover_thread_id" binding:"required"`
+	BehaviorRating      *int   `json:"behavior_rating" binding:"omitempty,min=1,max=5"`
+	BookConditionRating *int   `json:"book_condition_rating" binding:"omitempty,min=1,max=5"`
+	CommunicationRating *int   `json:"communication_rating" binding:"omitempty,min=1,max=5"`
 	Comment             string `json:"comment"`
 }
 
@@ -35,15 +36,9 @@ func (h *Handler) Create(c *gin.Context) {
 	}
 
 	userID := middleware.GetUserID(c)
-	var bookID *string
-	if req.BookID != "" {
-		bookID = &req.BookID
-	}
-
 	review := &domain.UserReview{
 		ReviewerID:          userID,
-		RevieweeID:          req.RevieweeID,
-		BookID:              bookID,
+		HandoverThreadID:    &req.HandoverThreadID,
 		BehaviorRating:      req.BehaviorRating,
 		BookConditionRating: req.BookConditionRating,
 		CommunicationRating: req.CommunicationRating,
@@ -69,7 +64,82 @@ func (h *Handler) GetByUser(c *gin.Context) {
 	response.Success(c, reviews)
 }
 
+type DisputeReviewRequest struct {
+	Statement string `json:"statement" binding:"required"`
+}
+
+// Dispute lets the reviewee challenge a review
+func (h *Handler) Dispute(c *gin.Context) {
+	var req DisputeReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	dispute, err := h.reviewSvc.Dispute(c.Request.Context(), c.Param("id"), userID, req.Statement)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Created(c, dispute)
+}
+
+// ListDisputes returns the moderation queue (?status=pending by default)
+func (h *Handler) ListDisputes(c *gin.Context) {
+	status := domain.DisputeStatus(c.DefaultQuery("status", string(domain.DisputePending)))
+	disputes, err := h.reviewSvc.ListDisputes(c.Request.Context(), status, 100, 0)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, disputes)
+}
+
+// ResolveDisputeRequest upholds, edits or removes the disputed review.
+// Ratings and comment are only applied when outcome is "edited".
+type ResolveDisputeRequest struct {
+	Outcome             string  `json:"outcome" binding:"required,oneof=upheld edited removed"`
+	Note                string  `json:"note"`
+	BehaviorRating      *int    `json:"behavior_rating" binding:"omitempty,min=1,max=5"`
+	BookConditionRating *int    `json:"book_condition_rating" binding:"omitempty,min=1,max=5"`
+	CommunicationRating *int    `json:"communication_rating" binding:"omitempty,min=1,max=5"`
+	Comment             *string `json:"comment"`
+}
+
+func (h *Handler) ResolveDispute(c *gin.Context) {
+	var req ResolveDisputeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	adminID := middleware.GetUserID(c)
+	dispute, err := h.reviewSvc.ResolveDispute(c.Request.Context(), c.Param("id"), adminID, &review.DisputeResolution{
+		Outcome:             domain.DisputeStatus(req.Outcome),
+		Note:                req.Note,
+		BehaviorRating:      req.BehaviorRating,
+		BookConditionRating: req.BookConditionRating,
+		CommunicationRating: req.CommunicationRating,
+		Comment:             req.Comment,
+	})
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, dispute)
+}
+
 func RegisterRoutes(r *gin.RouterGroup, h *Handler) {
 	r.POST("/reviews", h.Create)
+	r.POST("/reviews/:id/dispute", h.Dispute)
 	r.GET("/users/:id/reviews", h.GetByUser)
 }
+
+func RegisterAdminRoutes(r *gin.RouterGroup, h *Handler) {
+	disputes := r.Group("/admin/review-disputes")
+	{
+		disputes.GET("", h.ListDisputes)
+		disputes.POST("/:id/resolve", h.ResolveDispute)
+	}
+}
@@ -0,0 +1,295 @@
+package shelfhandler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/online-library/internal/domain"
+	"github.com/yourusername/online-library/internal/rest/middleware"
+	"github.com/yourusername/online-library/internal/rest/response"
+	"github.com/yourusername/online-library/internal/shelf"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	shelfSvc shelf.Service
+	log      *zap.Logger
+}
+
+func NewHandler(shelfSvc shelf.Service, log *zap.Logger) *Handler {
+	return &Handler{shelfSvc: shelfSvc, log: log}
+}
+
+type ShelfRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description" binding:"max=2000"`
+	Visibility  string `json:"visibility" binding:"omitempty,oneof=private link public"`
+}
+
+func (h *Handler) Create(c *gin.Context) {
+	var req ShelfRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	created, err := h.shelfSvc.Create(c.Request.Context(), &domain.Shelf{
+		OwnerID:     middleware.GetUserID(c),
+		Name:        req.Name,
+		Description: req.Description,
+		Visibility:  domain.ShelfVisibility(req.Visibility),
+	})
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Created(c, created)
+}
+
+func (h *Handler) Update(c *gin.Context) {
+	var req ShelfRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if req.Visibility == "" {
+		response.BadRequest(c, "visibility is required")
+		return
+	}
+
+	updated, err := h.shelfSvc.Update(c.Request.Context(), &domain.Shelf{
+		ID:          c.Param("id"),
+		OwnerID:     middleware.GetUserID(c),
+		Name:        req.Name,
+		Description: req.Description,
+		Visibility:  domain.ShelfVisibility(req.Visibility),
+	})
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, updated)
+}
+
+func (h *Handler) Delete(c *gin.Context) {
+	if err := h.shelfSvc.Delete(c.Request.Context(), c.Param("id"), middleware.GetUserID(c)); err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "shelf deleted"})
+}
+
+// Get returns a shelf with its books and their current availability
+func (h *Handler) Get(c *gin.Context) {
+	s, err := h.shelfSvc.Get(c.Request.Context(), c.Param("id"), middleware.GetUserID(c))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, s)
+}
+
+// GetPublic serves a public shelf to anyone
+func (h *Handler) GetPublic(c *gin.Context) {
+	s, err := h.shelfSvc.Get(c.Request.Context(), c.Param("id"), "")
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, s)
+}
+
+// GetShared serves a link or public shelf by its share token
+func (h *Handler) GetShared(c *gin.Context) {
+	s, err := h.shelfSvc.GetShared(c.Request.Context(), c.Param("token"), "")
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, s)
+}
+
+func (h *Handler) ListMine(c *gin.Context) {
+	shelves, err := h.shelfSvc.ListMine(c.Request.Context(), middleware.GetUserID(c))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, shelves)
+}
+
+func (h *Handler) ListFollowed(c *gin.Context) {
+	shelves, err := h.shelfSvc.ListFollowed(c.Request.Context(), middleware.GetUserID(c))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, shelves)
+}
+
+// ListPublic returns public shelves, most followed first
+func (h *Handler) ListPublic(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	shelves, err := h.shelfSvc.ListPublic(c.Request.Context(), limit, offset)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, shelves)
+}
+
+func (h *Handler) ResetShareLink(c *gin.Context) {
+	s, err := h.shelfSvc.ResetShareLink(c.Request.Context(), c.Param("id"), middleware.GetUserID(c))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, s)
+}
+
+type AddBookRequest struct {
+	BookID string `json:"book_id" binding:"required,uuid"`
+	Note   string `json:"note" binding:"max=1000"`
+}
+
+func (h *Handler) AddBook(c *gin.Context) {
+	var req AddBookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	item, err := h.shelfSvc.AddBook(c.Request.Context(), c.Param("id"), middleware.GetUserID(c), req.BookID, req.Note)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Created(c, item)
+}
+
+type NoteRequest struct {
+	Note string `json:"note" binding:"max=1000"`
+}
+
+func (h *Handler) UpdateNote(c *gin.Context) {
+	var req NoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	if err := h.shelfSvc.UpdateNote(c.Request.Context(), c.Param("id"), middleware.GetUserID(c), c.Param("bookId"), req.Note); err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "note updated"})
+}
+
+func (h *Handler) RemoveBook(c *gin.Context) {
+	if err := h.shelfSvc.RemoveBook(c.Request.Context(), c.Param("id"), middleware.GetUserID(c), c.Param("bookId")); err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "book removed from shelf"})
+}
+
+type ReorderRequest struct {
+	BookIDs []string `json:"book_ids" binding:"required"`
+}
+
+func (h *Handler) Reorder(c *gin.Context) {
+	var req ReorderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	if err := h.shelfSvc.Reorder(c.Request.Context(), c.Param("id"), middleware.GetUserID(c), req.BookIDs); err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "shelf reordered"})
+}
+
+type FollowRequest struct {
+	// ShareToken is needed to follow a link shelf
+	ShareToken string `json:"share_token"`
+}
+
+func (h *Handler) Follow(c *gin.Context) {
+	var req FollowRequest
+	// The body is optional for public shelves
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, err.Error())
+			return
+		}
+	}
+
+	if err := h.shelfSvc.Follow(c.Request.Context(), c.Param("id"), middleware.GetUserID(c), req.ShareToken); err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "following shelf"})
+}
+
+func (h *Handler) Unfollow(c *gin.Context) {
+	if err := h.shelfSvc.Unfollow(c.Request.Context(), c.Param("id"), middleware.GetUserID(c)); err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "unfollowed shelf"})
+}
+
+type CloneRequest struct {
+	// Name defaults to the original shelf's
+	Name string `json:"name" binding:"max=100"`
+}
+
+func (h *Handler) Clone(c *gin.Context) {
+	var req CloneRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, err.Error())
+			return
+		}
+	}
+
+	clone, err := h.shelfSvc.Clone(c.Request.Context(), c.Param("id"), middleware.GetUserID(c), req.Name)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Created(c, clone)
+}
+
+func RegisterPublicRoutes(r *gin.RouterGroup, h *Handler) {
+	r.GET("/shelves/public", h.ListPublic)
+	r.GET("/shelves/public/:id", h.GetPublic)
+	r.GET("/shelves/shared/:token", h.GetShared)
+}
+
+func RegisterRoutes(r *gin.RouterGroup, h *Handler) {
+	r.GET("/shelves", h.ListMine)
+	r.POST("/shelves", h.Create)
+	r.GET("/shelves/following", h.ListFollowed)
+	r.GET("/shelves/:id", h.Get)
+	r.PUT("/shelves/:id", h.Update)
+	r.DELETE("/shelves/:id", h.Delete)
+	r.POST("/shelves/:id/share-link", h.ResetShareLink)
+	r.POST("/shelves/:id/books", h.AddBook)
+	r.PUT("/shelves/:id/books/:bookId", h.UpdateNote)
+	r.DELETE("/shelves/:id/books/:bookId", h.RemoveBook)
+	r.PUT("/shelves/:id/order", h.Reorder)
+	r.POST("/shelves/:id/follow", h.Follow)
+	r.DELETE("/shelves/:id/follow", h.Unfollow)
+	r.POST("/shelves/:id/clone", h.Clone)
+}
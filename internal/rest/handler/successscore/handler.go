@@ -0,0 +1,151 @@
+package successscorehandler
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/online-library/internal/domain"
+	"github.com/yourusername/online-library/internal/rest/middleware"
+	"github.com/yourusername/online-library/internal/rest/response"
+	"github.com/yourusername/online-library/internal/successscore"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	successScoreSvc successscore.Service
+	log             *zap.Logger
+}
+
+func NewHandler(successScoreSvc successscore.Service, log *zap.Logger) *Handler {
+	return &Handler{successScoreSvc: successScoreSvc, log: log}
+}
+
+// GetHistory returns a user's success score changes, newest first
+func (h *Handler) GetHistory(c *gin.Context) {
+	userID := c.Param("id")
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	history, err := h.successScoreSvc.GetHistory(c.Request.Context(), userID, limit, offset)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, history)
+}
+
+// Reconcile compares stored scores against their history.
+// Pass ?fix=true to record an adjustment entry for each drifted user.
+func (h *Handler) Reconcile(c *gin.Context) {
+	fix := c.Query("fix") == "true"
+	report, err := h.successScoreSvc.Reconcile(c.Request.Context(), fix)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, report)
+}
+
+// ListRules returns the configured score rules
+func (h *Handler) ListRules(c *gin.Context) {
+	rules, err := h.successScoreSvc.ListRules(c.Request.Context())
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, rules)
+}
+
+// ScoreRuleReq describes the configurable part of a score rule
+type ScoreRuleReq struct {
+	EventType string `json:"event_type"`
+	Amount    *int   `json:"amount" binding:"required"`
+	DailyCap  *int   `json:"daily_cap"`
+	IsActive  *bool  `json:"is_active"`
+}
+
+func (r ScoreRuleReq) toRule() *domain.ScoreRule {
+	isActive := true
+	if r.IsActive != nil {
+		isActive = *r.IsActive
+	}
+	return &domain.ScoreRule{
+		EventType: domain.ScoreEventType(r.EventType),
+		Amount:    *r.Amount,
+		DailyCap:  r.DailyCap,
+		IsActive:  isActive,
+	}
+}
+
+// UpdateRule changes the points, cap or active flag of an event type
+func (h *Handler) UpdateRule(c *gin.Context) {
+	var req ScoreRuleReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	req.EventType = c.Param("eventType")
+
+	rule, err := h.successScoreSvc.UpdateRule(c.Request.Context(), middleware.GetUserID(c), req.toRule())
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, rule)
+}
+
+// DryRunReq is a proposed rule set and the window of past events to replay
+type DryRunReq struct {
+	Rules []ScoreRuleReq `json:"rules" binding:"required,dive"`
+	Since *time.Time     `json:"since"`
+	Until *time.Time     `json:"until"`
+}
+
+// DryRun shows how past events would have scored under proposed rules.
+// Defaults to the last month of events.
+func (h *Handler) DryRun(c *gin.Context) {
+	var req DryRunReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	dryRun := &successscore.DryRunRequest{}
+	for _, r := range req.Rules {
+		dryRun.Rules = append(dryRun.Rules, r.toRule())
+	}
+	if req.Since != nil {
+		dryRun.Since = *req.Since
+	}
+	if req.Until != nil {
+		dryRun.Until = *req.Until
+	}
+
+	result, err := h.successScoreSvc.DryRun(c.Request.Context(), dryRun)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, result)
+}
+
+func RegisterRoutes(r *gin.RouterGroup, h *Handler) {
+	r.GET("/users/:id/score-history", h.GetHistory)
+}
+
+func RegisterAdminRoutes(r *gin.RouterGroup, h *Handler) {
+	score := r.Group("/admin/success-score")
+	{
+		score.POST("/reconcile", h.Reconcile)
+		score.GET("/rules", h.ListRules)
+		score.PUT("/rules/:eventType", h.UpdateRule)
+		score.POST("/rules/dry-run", h.DryRun)
+	}
+}
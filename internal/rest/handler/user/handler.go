@@ -35,6 +35,7 @@ type UpdateProfileRequest struct {
 	LocationAddress string   `json:"location_address"`
 	LocationLat     *float64 `json:"location_lat"`
 	LocationLng     *float64 `json:"location_lng"`
+	PreferredLocale string   `json:"preferred_locale" binding:"omitempty,oneof=en bn"`
 }
 
 func (h *Handler) UpdateProfile(c *gin.Context) {
@@ -52,6 +53,7 @@ func (h *Handler) UpdateProfile(c *gin.Context) {
 		LocationAddress: req.LocationAddress,
 		LocationLat:     req.LocationLat,
 		LocationLng:     req.LocationLng,
+		PreferredLocale: req.PreferredLocale,
 	}
 
 	updated, err := h.userSvc.UpdateProfile(c.Request.Context(), userID, user)
@@ -44,6 +44,21 @@ func AuthMiddleware(authSvc auth.Service, log *zap.Logger) gin.HandlerFunc {
 	}
 }
 
+// StreamAuthMiddleware validates JWT tokens like AuthMiddleware but also
+// accepts the token as an access_token query parameter, since browser
+// EventSource connections cannot set headers.
+func StreamAuthMiddleware(authSvc auth.Service, log *zap.Logger) gin.HandlerFunc {
+	authenticate := AuthMiddleware(authSvc, log)
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			if token := c.Query("access_token"); token != "" {
+				c.Request.Header.Set("Authorization", "Bearer "+token)
+			}
+		}
+		authenticate(c)
+	}
+}
+
 // GetUserID extracts user ID from context
 func GetUserID(c *gin.Context) string {
 	userID, _ := c.Get("user_id")
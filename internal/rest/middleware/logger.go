@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"net/url"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -12,7 +13,7 @@ func RequestLogger(log *zap.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
 		path := c.Request.URL.Path
-		query := c.Request.URL.RawQuery
+		query := redactQuery(c.Request.URL.RawQuery)
 
 		c.Next()
 
@@ -31,3 +32,13 @@ func RequestLogger(log *zap.Logger) gin.HandlerFunc {
 		)
 	}
 }
+
+// redactQuery hides access tokens passed in the query string
+func redactQuery(raw string) string {
+	values, err := url.ParseQuery(raw)
+	if err != nil || !values.Has("access_token") {
+		return raw
+	}
+	values.Set("access_token", "REDACTED")
+	return values.Encode()
+}
@@ -1,6 +1,7 @@
 package response
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -29,26 +30,30 @@ func Error(c *gin.Context, err error) {
 	message := "internal server error"
 
 	// Map domain errors to HTTP status codes
+	var transitionErr *domain.BookTransitionError
 	switch err {
-	case domain.ErrNotFound, domain.ErrUserNotFound:
+	case domain.ErrNotFound, domain.ErrUserNotFound, domain.ErrBookNotFound:
 		statusCode = http.StatusNotFound
 		message = err.Error()
 	case domain.ErrInvalidCredentials, domain.ErrInvalidToken, domain.ErrTokenExpired:
 		statusCode = http.StatusUnauthorized
 		message = err.Error()
-	case domain.ErrEmailExists, domain.ErrUsernameExists, domain.ErrAlreadyExists:
+	case domain.ErrEmailExists, domain.ErrUsernameExists, domain.ErrAlreadyExists, domain.ErrAlreadyReviewed, domain.ErrHandoverNotActive, domain.ErrRequestNotPending, domain.ErrDonationStatus, domain.ErrCampaignClosed, domain.ErrOutflowVoided, domain.ErrShelfLimit:
 		statusCode = http.StatusConflict
 		message = err.Error()
-	case domain.ErrInvalidInput:
+	case domain.ErrInvalidInput, domain.ErrInvalidBookStatus, domain.ErrContentTooLong:
 		statusCode = http.StatusBadRequest
 		message = err.Error()
-	case domain.ErrForbidden:
+	case domain.ErrForbidden, domain.ErrSelfVote, domain.ErrReviewNotAllowed, domain.ErrCommentNotAllowed:
 		statusCode = http.StatusForbidden
 		message = err.Error()
-	case domain.ErrBookNotAvailable, domain.ErrBookAlreadyBorrowed:
+	case domain.ErrBookNotAvailable, domain.ErrBookAlreadyBorrowed, domain.ErrReviewWindowClosed, domain.ErrHandoverNotOverdue, domain.ErrCommentWindowClosed:
 		statusCode = http.StatusBadRequest
 		message = err.Error()
 	default:
+		if errors.As(err, &transitionErr) {
+			statusCode = http.StatusConflict
+		}
 		if err != nil {
 			message = err.Error()
 		}
@@ -0,0 +1,157 @@
+package review
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/online-library/internal/content"
+	"github.com/yourusername/online-library/internal/domain"
+	"go.uber.org/zap"
+)
+
+// Dispute lets the reviewee challenge a review with a statement. The
+// review stays visible until an admin resolves the dispute.
+func (s *service) Dispute(ctx context.Context, reviewID, userID, statement string) (*domain.ReviewDispute, error) {
+	statement = strings.TrimSpace(statement)
+	if statement == "" {
+		return nil, domain.ErrInvalidInput
+	}
+
+	review, err := s.reviewRepo.FindByID(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if review.RevieweeID != userID {
+		return nil, domain.ErrForbidden
+	}
+
+	dispute := &domain.ReviewDispute{
+		ID:         uuid.New().String(),
+		ReviewID:   reviewID,
+		DisputerID: userID,
+		Statement:  statement,
+		Status:     domain.DisputePending,
+		CreatedAt:  time.Now(),
+	}
+	if err := s.reviewRepo.CreateDispute(ctx, dispute); err != nil {
+		s.log.Error("failed to create review dispute", zap.String("review_id", reviewID), zap.Error(err))
+		return nil, err
+	}
+
+	s.log.Info("review disputed", zap.String("review_id", reviewID), zap.String("dispute_id", dispute.ID))
+	return dispute, nil
+}
+
+func (s *service) ListDisputes(ctx context.Context, status domain.DisputeStatus, limit, offset int) ([]*domain.ReviewDispute, error) {
+	disputes, err := s.reviewRepo.ListDisputes(ctx, status, limit, offset)
+	if err != nil {
+		s.log.Error("failed to list review disputes", zap.Error(err))
+		return nil, err
+	}
+	return disputes, nil
+}
+
+// ResolveDispute applies an admin's decision. Removing a review hides it
+// and reverses its score effect; editing it re-scores the new ratings.
+func (s *service) ResolveDispute(ctx context.Context, disputeID, adminID string, res *DisputeResolution) (*domain.ReviewDispute, error) {
+	switch res.Outcome {
+	case domain.DisputeUpheld, domain.DisputeEdited, domain.DisputeRemoved:
+	default:
+		return nil, domain.ErrInvalidInput
+	}
+	// Render an edited comment before anything is changed so that one
+	// that is too long leaves the dispute open
+	var comment *content.Rendered
+	if res.Outcome == domain.DisputeEdited && res.Comment != nil {
+		var err error
+		if comment, err = s.contentSvc.Render(ctx, *res.Comment, content.MaxReviewComment); err != nil {
+			return nil, err
+		}
+	}
+
+	dispute, err := s.reviewRepo.FindDisputeByID(ctx, disputeID)
+	if err != nil {
+		return nil, err
+	}
+	review, err := s.reviewRepo.FindByID(ctx, dispute.ReviewID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	dispute.Status = res.Outcome
+	dispute.ResolutionNote = res.Note
+	dispute.ResolvedBy = &adminID
+	dispute.ResolvedAt = &now
+
+	// Closing the dispute and changing the review commit together, so a
+	// failed change leaves the dispute open to be resolved again
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.reviewRepo.ResolveDispute(ctx, dispute); err != nil {
+			return err
+		}
+
+		switch res.Outcome {
+		case domain.DisputeEdited:
+			applyEdit(review, res, comment)
+			if err := s.reviewRepo.Update(ctx, review); err != nil {
+				s.log.Error("failed to edit disputed review", zap.String("review_id", review.ID), zap.Error(err))
+				return err
+			}
+			if _, err := s.successScoreSvc.RevertReference(ctx, review.RevieweeID, "review", review.ID, "Review edited by moderator"); err != nil {
+				s.log.Error("failed to revert review score", zap.String("review_id", review.ID), zap.Error(err))
+				return err
+			}
+			if err := s.scoreReview(ctx, review); err != nil {
+				s.log.Error("failed to rescore edited review", zap.String("review_id", review.ID), zap.Error(err))
+				return err
+			}
+
+		case domain.DisputeRemoved:
+			if err := s.reviewRepo.Remove(ctx, review.ID); err != nil {
+				s.log.Error("failed to remove disputed review", zap.String("review_id", review.ID), zap.Error(err))
+				return err
+			}
+			if _, err := s.successScoreSvc.RevertReference(ctx, review.RevieweeID, "review", review.ID, "Review removed by moderator"); err != nil {
+				s.log.Error("failed to revert review score", zap.String("review_id", review.ID), zap.Error(err))
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.notificationSvc.NotifyReviewDisputeResolved(ctx, review.RevieweeID, review.ID, res.Outcome, false); err != nil {
+		s.log.Warn("failed to notify reviewee", zap.Error(err))
+	}
+	if err := s.notificationSvc.NotifyReviewDisputeResolved(ctx, review.ReviewerID, review.ID, res.Outcome, true); err != nil {
+		s.log.Warn("failed to notify reviewer", zap.Error(err))
+	}
+
+	s.log.Info("review dispute resolved",
+		zap.String("dispute_id", disputeID),
+		zap.String("outcome", string(res.Outcome)),
+		zap.String("admin_id", adminID))
+	dispute.Review = review
+	return dispute, nil
+}
+
+func applyEdit(review *domain.UserReview, res *DisputeResolution, comment *content.Rendered) {
+	if res.BehaviorRating != nil {
+		review.BehaviorRating = res.BehaviorRating
+	}
+	if res.BookConditionRating != nil {
+		review.BookConditionRating = res.BookConditionRating
+	}
+	if res.CommunicationRating != nil {
+		review.CommunicationRating = res.CommunicationRating
+	}
+	if comment != nil {
+		review.Comment = comment.Source
+		review.CommentHTML = comment.HTML
+	}
+}
@@ -3,24 +3,71 @@ package review
 import (
 	"context"
 
+	"github.com/yourusername/online-library/internal/content"
 	"github.com/yourusername/online-library/internal/domain"
 )
 
 type Service interface {
 	Create(ctx context.Context, review *domain.UserReview) (*domain.UserReview, error)
 	GetByUser(ctx context.Context, userID string) ([]*domain.UserReview, error)
+
+	// Disputes and moderation
+	Dispute(ctx context.Context, reviewID, userID, statement string) (*domain.ReviewDispute, error)
+	ListDisputes(ctx context.Context, status domain.DisputeStatus, limit, offset int) ([]*domain.ReviewDispute, error)
+	ResolveDispute(ctx context.Context, disputeID, adminID string, res *DisputeResolution) (*domain.ReviewDispute, error)
+}
+
+// DisputeResolution is an admin's decision on a dispute. The rating and
+// comment fields are only used when Outcome is DisputeEdited; nil keeps
+// the current value.
+type DisputeResolution struct {
+	Outcome             domain.DisputeStatus
+	Note                string
+	BehaviorRating      *int
+	BookConditionRating *int
+	CommunicationRating *int
+	Comment             *string
 }
 
 type ReviewRepo interface {
+	// Create stores the review and refreshes the reviewee's rating averages
 	Create(ctx context.Context, review *domain.UserReview) error
 	FindByUserID(ctx context.Context, userID string) ([]*domain.UserReview, error)
+	ExistsForThread(ctx context.Context, threadID, reviewerID string) (bool, error)
+	FindByID(ctx context.Context, id string) (*domain.UserReview, error)
+	// Update and Remove also refresh the reviewee's rating averages
+	Update(ctx context.Context, review *domain.UserReview) error
+	Remove(ctx context.Context, reviewID string) error
+
+	CreateDispute(ctx context.Context, dispute *domain.ReviewDispute) error
+	FindDisputeByID(ctx context.Context, id string) (*domain.ReviewDispute, error)
+	ListDisputes(ctx context.Context, status domain.DisputeStatus, limit, offset int) ([]*domain.ReviewDispute, error)
+	// ResolveDispute closes a pending dispute; ErrNotFound if it is not pending
+	ResolveDispute(ctx context.Context, dispute *domain.ReviewDispute) error
+}
+
+// UnitOfWork runs fn in one database transaction. Repository calls made
+// with the context passed to fn take part in it.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type HandoverRepo interface {
+	GetHandoverThreadByID(ctx context.Context, threadID string) (*domain.HandoverThread, error)
 }
 
 type SuccessScoreSvc interface {
 	ProcessPositiveReview(ctx context.Context, userID, reviewID string) error
 	ProcessNegativeReview(ctx context.Context, userID, reviewID string) error
+	RevertReference(ctx context.Context, userID, refType, refID, reason string) (int, error)
 }
 
 type NotificationSvc interface {
-	NotifyReviewReceived(ctx context.Context, userID, reviewerName string) error
+	NotifyReviewReceived(ctx context.Context, userID, reviewID, reviewerName string) error
+	NotifyReviewDisputeResolved(ctx context.Context, userID, reviewID string, outcome domain.DisputeStatus, isReviewer bool) error
+	NotifyMention(ctx context.Context, userID, authorID string, source domain.MentionSource) error
+}
+
+type ContentSvc interface {
+	Render(ctx context.Context, source string, maxLen int) (*content.Rendered, error)
 }
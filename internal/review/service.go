@@ -5,27 +5,48 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/yourusername/online-library/internal/content"
 	"github.com/yourusername/online-library/internal/domain"
 	"go.uber.org/zap"
 )
 
+// ReviewWindow is how long after a handover completes its participants
+// may review each other.
+const ReviewWindow = 14 * 24 * time.Hour
+
 type service struct {
 	reviewRepo      ReviewRepo
+	handoverRepo    HandoverRepo
 	successScoreSvc SuccessScoreSvc
 	notificationSvc NotificationSvc
+	contentSvc      ContentSvc
+	uow             UnitOfWork
 	log             *zap.Logger
 }
 
-func NewService(reviewRepo ReviewRepo, successScoreSvc SuccessScoreSvc, notificationSvc NotificationSvc, log *zap.Logger) Service {
+func NewService(reviewRepo ReviewRepo, handoverRepo HandoverRepo, successScoreSvc SuccessScoreSvc, notificationSvc NotificationSvc, contentSvc ContentSvc, uow UnitOfWork, log *zap.Logger) Service {
 	return &service{
 		reviewRepo:      reviewRepo,
+		handoverRepo:    handoverRepo,
 		successScoreSvc: successScoreSvc,
 		notificationSvc: notificationSvc,
+		contentSvc:      contentSvc,
+		uow:             uow,
 		log:             log,
 	}
 }
 
 func (s *service) Create(ctx context.Context, review *domain.UserReview) (*domain.UserReview, error) {
+	if err := s.checkEligible(ctx, review); err != nil {
+		return nil, err
+	}
+	rendered, err := s.contentSvc.Render(ctx, review.Comment, content.MaxReviewComment)
+	if err != nil {
+		return nil, err
+	}
+	review.Comment = rendered.Source
+	review.CommentHTML = rendered.HTML
+
 	review.ID = uuid.New().String()
 	review.CreatedAt = time.Now()
 
@@ -34,7 +55,31 @@ func (s *service) Create(ctx context.Context, review *domain.UserReview) (*domai
 		return nil, err
 	}
 
-	// Calculate average rating and update success score
+	if err := s.scoreReview(ctx, review); err != nil {
+		s.log.Warn("failed to update success score for review", zap.String("review_id", review.ID), zap.Error(err))
+	}
+	s.notifyMentions(ctx, review, rendered.Mentions)
+
+	s.log.Info("review created successfully", zap.String("review_id", review.ID))
+	return review, nil
+}
+
+// notifyMentions tells the users mentioned in a review's comment about it
+func (s *service) notifyMentions(ctx context.Context, review *domain.UserReview, mentions []content.Mention) {
+	source := domain.MentionSource{Type: domain.MentionInReview, ID: review.ID}
+	for _, m := range mentions {
+		if m.UserID == review.ReviewerID {
+			continue
+		}
+		if err := s.notificationSvc.NotifyMention(ctx, m.UserID, review.ReviewerID, source); err != nil {
+			s.log.Warn("failed to notify mentioned user", zap.String("user_id", m.UserID), zap.Error(err))
+		}
+	}
+}
+
+// scoreReview applies the success score effect of a review's ratings to
+// the reviewee.
+func (s *service) scoreReview(ctx context.Context, review *domain.UserReview) error {
 	avgRating := 0
 	count := 0
 	if review.BehaviorRating != nil {
@@ -50,21 +95,62 @@ func (s *service) Create(ctx context.Context, review *domain.UserReview) (*domai
 		count++
 	}
 
-	if count > 0 {
-		avgRating = avgRating / count
-		if avgRating >= 4 {
-			if err := s.successScoreSvc.ProcessPositiveReview(ctx, review.RevieweeID, review.ID); err != nil {
-				s.log.Warn("failed to update success score for positive review", zap.Error(err))
-			}
-		} else if avgRating < 3 {
-			if err := s.successScoreSvc.ProcessNegativeReview(ctx, review.RevieweeID, review.ID); err != nil {
-				s.log.Warn("failed to update success score for negative review", zap.Error(err))
-			}
-		}
+	if count == 0 {
+		return nil
+	}
+	avgRating = avgRating / count
+	if avgRating >= 4 {
+		return s.successScoreSvc.ProcessPositiveReview(ctx, review.RevieweeID, review.ID)
 	}
+	if avgRating < 3 {
+		return s.successScoreSvc.ProcessNegativeReview(ctx, review.RevieweeID, review.ID)
+	}
+	return nil
+}
 
-	s.log.Info("review created successfully", zap.String("review_id", review.ID))
-	return review, nil
+// checkEligible verifies the reviewer took part in the completed handover,
+// the review window is still open and this side has not reviewed yet. The
+// reviewee and book are taken from the thread.
+func (s *service) checkEligible(ctx context.Context, review *domain.UserReview) error {
+	if review.HandoverThreadID == nil || *review.HandoverThreadID == "" {
+		return domain.ErrReviewNotAllowed
+	}
+
+	thread, err := s.handoverRepo.GetHandoverThreadByID(ctx, *review.HandoverThreadID)
+	if err != nil {
+		s.log.Error("failed to get handover thread", zap.Error(err))
+		return err
+	}
+	if thread == nil {
+		return domain.ErrNotFound
+	}
+	if thread.Status != string(domain.HandoverCompleted) || thread.CompletedAt == nil {
+		return domain.ErrReviewNotAllowed
+	}
+
+	switch review.ReviewerID {
+	case thread.CurrentHolderID:
+		review.RevieweeID = thread.NextHolderID
+	case thread.NextHolderID:
+		review.RevieweeID = thread.CurrentHolderID
+	default:
+		return domain.ErrReviewNotAllowed
+	}
+
+	if time.Since(*thread.CompletedAt) > ReviewWindow {
+		return domain.ErrReviewWindowClosed
+	}
+
+	exists, err := s.reviewRepo.ExistsForThread(ctx, thread.ID, review.ReviewerID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return domain.ErrAlreadyReviewed
+	}
+
+	review.BookID = &thread.BookID
+	return nil
 }
 
 func (s *service) GetByUser(ctx context.Context, userID string) ([]*domain.UserReview, error) {
@@ -0,0 +1,82 @@
+package shelf
+
+import (
+	"context"
+
+	"github.com/yourusername/online-library/internal/domain"
+)
+
+type Service interface {
+	Create(ctx context.Context, shelf *domain.Shelf) (*domain.Shelf, error)
+	// Update changes the name, description and visibility of a shelf owned
+	// by shelf.OwnerID
+	Update(ctx context.Context, shelf *domain.Shelf) (*domain.Shelf, error)
+	Delete(ctx context.Context, shelfID, userID string) error
+	// Get returns a shelf with its books. Others than the owner see public
+	// shelves, and link shelves they follow; anything else is not found.
+	// viewerID is empty for anonymous viewers.
+	Get(ctx context.Context, shelfID, viewerID string) (*domain.Shelf, error)
+	// GetShared returns a link or public shelf by its share token
+	GetShared(ctx context.Context, token, viewerID string) (*domain.Shelf, error)
+	ListMine(ctx context.Context, userID string) ([]*domain.Shelf, error)
+	ListFollowed(ctx context.Context, userID string) ([]*domain.Shelf, error)
+	// ListPublic returns public shelves, most followed first
+	ListPublic(ctx context.Context, limit, offset int) ([]*domain.Shelf, error)
+	// ResetShareLink replaces the share token, breaking the old link. Unless
+	// the shelf is public its followers are dropped too, since they may have
+	// followed through the old link.
+	ResetShareLink(ctx context.Context, shelfID, userID string) (*domain.Shelf, error)
+
+	// Books on a shelf; only the owner changes them
+	AddBook(ctx context.Context, shelfID, userID, bookID, note string) (*domain.ShelfItem, error)
+	UpdateNote(ctx context.Context, shelfID, userID, bookID, note string) error
+	RemoveBook(ctx context.Context, shelfID, userID, bookID string) error
+	// Reorder puts the shelf's books in the order of bookIDs, which must
+	// list every book on the shelf once
+	Reorder(ctx context.Context, shelfID, userID string, bookIDs []string) error
+
+	// Follow and Unfollow work on shelves the user can see but doesn't own
+	Follow(ctx context.Context, shelfID, userID, shareToken string) error
+	Unfollow(ctx context.Context, shelfID, userID string) error
+	// Clone copies a public shelf's books and notes into a new private
+	// shelf of the user's
+	Clone(ctx context.Context, shelfID, userID, name string) (*domain.Shelf, error)
+}
+
+type ShelfRepo interface {
+	Create(ctx context.Context, shelf *domain.Shelf) error
+	// Update saves the name, description, visibility and share token
+	Update(ctx context.Context, shelf *domain.Shelf) error
+	Delete(ctx context.Context, id string) error
+	// GetByID and GetByShareToken return the shelf without its items.
+	// Following is filled in for viewerID.
+	GetByID(ctx context.Context, id, viewerID string) (*domain.Shelf, error)
+	GetByShareToken(ctx context.Context, token, viewerID string) (*domain.Shelf, error)
+	ListByOwner(ctx context.Context, ownerID string) ([]*domain.Shelf, error)
+	// ListFollowed leaves out shelves that have since been made private
+	ListFollowed(ctx context.Context, userID string) ([]*domain.Shelf, error)
+	ListPublic(ctx context.Context, limit, offset int) ([]*domain.Shelf, error)
+	CountByOwner(ctx context.Context, ownerID string) (int, error)
+
+	// ListItems returns a shelf's books in order with their current status
+	ListItems(ctx context.Context, shelfID string) ([]*domain.ShelfItem, error)
+	// AddItem puts the book last on the shelf and sets item.Position. It
+	// returns domain.ErrAlreadyExists if the book is already on it and
+	// domain.ErrBookNotFound if the book doesn't exist.
+	AddItem(ctx context.Context, item *domain.ShelfItem) error
+	UpdateItemNote(ctx context.Context, shelfID, bookID, note string) error
+	RemoveItem(ctx context.Context, shelfID, bookID string) error
+	SetItemPositions(ctx context.Context, shelfID string, bookIDs []string) error
+	// CopyItems copies one shelf's books, order and notes to another
+	CopyItems(ctx context.Context, fromShelfID, toShelfID string) error
+
+	Follow(ctx context.Context, shelfID, userID string) error
+	Unfollow(ctx context.Context, shelfID, userID string) error
+	RemoveFollowers(ctx context.Context, shelfID string) error
+}
+
+// UnitOfWork runs fn in one database transaction. Repository calls made
+// with the context passed to fn take part in it.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
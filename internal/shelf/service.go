@@ -0,0 +1,370 @@
+package shelf
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/online-library/internal/domain"
+	"go.uber.org/zap"
+)
+
+const (
+	maxShelvesPerUser = 50
+	maxBooksPerShelf  = 500
+	maxShelfName      = 100
+)
+
+type service struct {
+	shelfRepo ShelfRepo
+	uow       UnitOfWork
+	log       *zap.Logger
+}
+
+func NewService(shelfRepo ShelfRepo, uow UnitOfWork, log *zap.Logger) Service {
+	return &service{
+		shelfRepo: shelfRepo,
+		uow:       uow,
+		log:       log,
+	}
+}
+
+func (s *service) Create(ctx context.Context, shelf *domain.Shelf) (*domain.Shelf, error) {
+	if shelf.Visibility == "" {
+		shelf.Visibility = domain.ShelfPrivate
+	}
+	if err := validateShelf(shelf); err != nil {
+		return nil, err
+	}
+	if err := s.checkShelfLimit(ctx, shelf.OwnerID); err != nil {
+		return nil, err
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		return nil, err
+	}
+	shelf.ID = uuid.New().String()
+	shelf.ShareToken = token
+	shelf.ClonedFrom = nil
+	shelf.CreatedAt = time.Now()
+	shelf.UpdatedAt = shelf.CreatedAt
+
+	if err := s.shelfRepo.Create(ctx, shelf); err != nil {
+		s.log.Error("failed to create shelf", zap.Error(err))
+		return nil, err
+	}
+	s.log.Info("shelf created", zap.String("shelf_id", shelf.ID), zap.String("owner_id", shelf.OwnerID))
+	return s.shelfRepo.GetByID(ctx, shelf.ID, shelf.OwnerID)
+}
+
+func (s *service) Update(ctx context.Context, shelf *domain.Shelf) (*domain.Shelf, error) {
+	if err := validateShelf(shelf); err != nil {
+		return nil, err
+	}
+	existing, err := s.owned(ctx, shelf.ID, shelf.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing.Name = shelf.Name
+	existing.Description = shelf.Description
+	existing.Visibility = shelf.Visibility
+	existing.UpdatedAt = time.Now()
+	if err := s.shelfRepo.Update(ctx, existing); err != nil {
+		s.log.Error("failed to update shelf", zap.String("shelf_id", shelf.ID), zap.Error(err))
+		return nil, err
+	}
+	return existing, nil
+}
+
+func (s *service) Delete(ctx context.Context, shelfID, userID string) error {
+	if _, err := s.owned(ctx, shelfID, userID); err != nil {
+		return err
+	}
+	if err := s.shelfRepo.Delete(ctx, shelfID); err != nil {
+		s.log.Error("failed to delete shelf", zap.String("shelf_id", shelfID), zap.Error(err))
+		return err
+	}
+	s.log.Info("shelf deleted", zap.String("shelf_id", shelfID), zap.String("owner_id", userID))
+	return nil
+}
+
+func (s *service) Get(ctx context.Context, shelfID, viewerID string) (*domain.Shelf, error) {
+	shelf, err := s.shelfRepo.GetByID(ctx, shelfID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if !canView(shelf, viewerID) {
+		return nil, domain.ErrNotFound
+	}
+	return s.withItems(ctx, shelf, viewerID)
+}
+
+func (s *service) GetShared(ctx context.Context, token, viewerID string) (*domain.Shelf, error) {
+	shelf, err := s.shelfRepo.GetByShareToken(ctx, token, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if shelf.Visibility == domain.ShelfPrivate && shelf.OwnerID != viewerID {
+		return nil, domain.ErrNotFound
+	}
+	return s.withItems(ctx, shelf, viewerID)
+}
+
+func (s *service) ListMine(ctx context.Context, userID string) ([]*domain.Shelf, error) {
+	return s.shelfRepo.ListByOwner(ctx, userID)
+}
+
+func (s *service) ListFollowed(ctx context.Context, userID string) ([]*domain.Shelf, error) {
+	shelves, err := s.shelfRepo.ListFollowed(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, shelf := range shelves {
+		shelf.ShareToken = ""
+	}
+	return shelves, nil
+}
+
+func (s *service) ListPublic(ctx context.Context, limit, offset int) ([]*domain.Shelf, error) {
+	shelves, err := s.shelfRepo.ListPublic(ctx, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	for _, shelf := range shelves {
+		shelf.ShareToken = ""
+	}
+	return shelves, nil
+}
+
+func (s *service) ResetShareLink(ctx context.Context, shelfID, userID string) (*domain.Shelf, error) {
+	shelf, err := s.owned(ctx, shelfID, userID)
+	if err != nil {
+		return nil, err
+	}
+	token, err := newShareToken()
+	if err != nil {
+		return nil, err
+	}
+	shelf.ShareToken = token
+	shelf.UpdatedAt = time.Now()
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.shelfRepo.Update(ctx, shelf); err != nil {
+			return err
+		}
+		if shelf.Visibility == domain.ShelfPublic {
+			return nil
+		}
+		shelf.Followers = 0
+		return s.shelfRepo.RemoveFollowers(ctx, shelfID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.log.Info("shelf share link reset", zap.String("shelf_id", shelfID))
+	return shelf, nil
+}
+
+func (s *service) AddBook(ctx context.Context, shelfID, userID, bookID, note string) (*domain.ShelfItem, error) {
+	shelf, err := s.owned(ctx, shelfID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if shelf.BookCount >= maxBooksPerShelf {
+		return nil, domain.ErrShelfLimit
+	}
+
+	item := &domain.ShelfItem{
+		ID:      uuid.New().String(),
+		ShelfID: shelfID,
+		BookID:  bookID,
+		Note:    strings.TrimSpace(note),
+		AddedAt: time.Now(),
+	}
+	if err := s.shelfRepo.AddItem(ctx, item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (s *service) UpdateNote(ctx context.Context, shelfID, userID, bookID, note string) error {
+	if _, err := s.owned(ctx, shelfID, userID); err != nil {
+		return err
+	}
+	return s.shelfRepo.UpdateItemNote(ctx, shelfID, bookID, strings.TrimSpace(note))
+}
+
+func (s *service) RemoveBook(ctx context.Context, shelfID, userID, bookID string) error {
+	if _, err := s.owned(ctx, shelfID, userID); err != nil {
+		return err
+	}
+	return s.shelfRepo.RemoveItem(ctx, shelfID, bookID)
+}
+
+func (s *service) Reorder(ctx context.Context, shelfID, userID string, bookIDs []string) error {
+	if _, err := s.owned(ctx, shelfID, userID); err != nil {
+		return err
+	}
+	items, err := s.shelfRepo.ListItems(ctx, shelfID)
+	if err != nil {
+		return err
+	}
+
+	if len(bookIDs) != len(items) {
+		return domain.ErrInvalidInput
+	}
+	onShelf := make(map[string]bool, len(items))
+	for _, item := range items {
+		onShelf[item.BookID] = true
+	}
+	for _, id := range bookIDs {
+		if !onShelf[id] {
+			return domain.ErrInvalidInput
+		}
+		delete(onShelf, id)
+	}
+
+	return s.shelfRepo.SetItemPositions(ctx, shelfID, bookIDs)
+}
+
+func (s *service) Follow(ctx context.Context, shelfID, userID, shareToken string) error {
+	shelf, err := s.shelfRepo.GetByID(ctx, shelfID, userID)
+	if err != nil {
+		return err
+	}
+	if shelf.OwnerID == userID {
+		return domain.ErrInvalidInput
+	}
+	// Following a link shelf needs the link
+	if !canView(shelf, userID) && (shelf.Visibility != domain.ShelfLink || shareToken != shelf.ShareToken) {
+		return domain.ErrNotFound
+	}
+	return s.shelfRepo.Follow(ctx, shelfID, userID)
+}
+
+func (s *service) Unfollow(ctx context.Context, shelfID, userID string) error {
+	return s.shelfRepo.Unfollow(ctx, shelfID, userID)
+}
+
+func (s *service) Clone(ctx context.Context, shelfID, userID, name string) (*domain.Shelf, error) {
+	source, err := s.shelfRepo.GetByID(ctx, shelfID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if source.Visibility != domain.ShelfPublic && source.OwnerID != userID {
+		return nil, domain.ErrNotFound
+	}
+	if err := s.checkShelfLimit(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		return nil, err
+	}
+	clone := &domain.Shelf{
+		ID:          uuid.New().String(),
+		OwnerID:     userID,
+		Name:        strings.TrimSpace(name),
+		Description: source.Description,
+		Visibility:  domain.ShelfPrivate,
+		ShareToken:  token,
+		ClonedFrom:  &source.ID,
+		CreatedAt:   time.Now(),
+	}
+	clone.UpdatedAt = clone.CreatedAt
+	if clone.Name == "" {
+		clone.Name = source.Name
+	}
+	if err := validateShelf(clone); err != nil {
+		return nil, err
+	}
+
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.shelfRepo.Create(ctx, clone); err != nil {
+			return err
+		}
+		return s.shelfRepo.CopyItems(ctx, source.ID, clone.ID)
+	})
+	if err != nil {
+		s.log.Error("failed to clone shelf", zap.String("shelf_id", shelfID), zap.Error(err))
+		return nil, err
+	}
+
+	s.log.Info("shelf cloned", zap.String("shelf_id", clone.ID), zap.String("source_id", source.ID), zap.String("owner_id", userID))
+	return s.Get(ctx, clone.ID, userID)
+}
+
+// owned loads a shelf for changes by its owner. Others get not found
+// rather than forbidden so private shelves don't give themselves away.
+func (s *service) owned(ctx context.Context, shelfID, userID string) (*domain.Shelf, error) {
+	shelf, err := s.shelfRepo.GetByID(ctx, shelfID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if shelf.OwnerID != userID {
+		return nil, domain.ErrNotFound
+	}
+	return shelf, nil
+}
+
+func (s *service) withItems(ctx context.Context, shelf *domain.Shelf, viewerID string) (*domain.Shelf, error) {
+	items, err := s.shelfRepo.ListItems(ctx, shelf.ID)
+	if err != nil {
+		s.log.Error("failed to list shelf items", zap.String("shelf_id", shelf.ID), zap.Error(err))
+		return nil, err
+	}
+	if items == nil {
+		items = []*domain.ShelfItem{}
+	}
+	shelf.Items = items
+	if shelf.OwnerID != viewerID {
+		shelf.ShareToken = ""
+	}
+	return shelf, nil
+}
+
+func (s *service) checkShelfLimit(ctx context.Context, ownerID string) error {
+	n, err := s.shelfRepo.CountByOwner(ctx, ownerID)
+	if err != nil {
+		return err
+	}
+	if n >= maxShelvesPerUser {
+		return domain.ErrShelfLimit
+	}
+	return nil
+}
+
+// canView reports whether viewerID may open the shelf by its ID
+func canView(shelf *domain.Shelf, viewerID string) bool {
+	switch {
+	case shelf.OwnerID == viewerID:
+		return true
+	case shelf.Visibility == domain.ShelfPublic:
+		return true
+	case shelf.Visibility == domain.ShelfLink:
+		return shelf.Following
+	}
+	return false
+}
+
+func validateShelf(shelf *domain.Shelf) error {
+	shelf.Name = strings.TrimSpace(shelf.Name)
+	if shelf.Name == "" || len([]rune(shelf.Name)) > maxShelfName || !shelf.Visibility.Valid() {
+		return domain.ErrInvalidInput
+	}
+	return nil
+}
+
+// newShareToken returns a random URL-safe token for a shelf's share link
+func newShareToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
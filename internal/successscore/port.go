@@ -1,11 +1,17 @@
 package successscore
 
-import "context"
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/online-library/internal/domain"
+)
 
 type Service interface {
 	ProcessIdeaPosted(ctx context.Context, userID, ideaID string) error
-	ProcessIdeaUpvote(ctx context.Context, userID, ideaID string) error
-	ProcessIdeaDownvote(ctx context.Context, userID, ideaID string) error
+	ProcessIdeaUpvote(ctx context.Context, userID, ideaID string) (int, error)
+	ProcessIdeaDownvote(ctx context.Context, userID, ideaID string) (int, error)
+	RevertIdeaVote(ctx context.Context, userID, ideaID string, voteType domain.VoteType, points int) error
 	ProcessPositiveReview(ctx context.Context, userID, reviewID string) error
 	ProcessNegativeReview(ctx context.Context, userID, reviewID string) error
 	ProcessBookDonation(ctx context.Context, userID, donationID string) error
@@ -13,9 +19,101 @@ type Service interface {
 	ProcessReturnOnTime(ctx context.Context, userID, bookID string) error
 	ProcessReturnLate(ctx context.Context, userID, bookID string) error
 	ProcessLostBook(ctx context.Context, userID, bookID string) error
+	ProcessHandoverCancelled(ctx context.Context, userID, threadID string) error
 	AdjustScore(ctx context.Context, userID string, amount int, reason, refType string, refID *string) error
+	RevertReference(ctx context.Context, userID, refType, refID, reason string) (int, error)
+
+	// History and reconciliation
+	GetHistory(ctx context.Context, userID string, limit, offset int) ([]*domain.SuccessScoreHistory, error)
+	Reconcile(ctx context.Context, fix bool) (*ReconcileReport, error)
+
+	// Rule management
+	ListRules(ctx context.Context) ([]*domain.ScoreRule, error)
+	UpdateRule(ctx context.Context, actorID string, rule *domain.ScoreRule) (*domain.ScoreRule, error)
+	DryRun(ctx context.Context, req *DryRunRequest) (*DryRunResult, error)
 }
 
 type ScoreRepo interface {
-	UpdateScore(ctx context.Context, userID string, change int, reason, refType string, refID *string) error
+	UpdateScore(ctx context.Context, userID string, change int, eventType domain.ScoreEventType, reason, refType string, refID *string) error
+	GetHistoryByUser(ctx context.Context, userID string, limit, offset int) ([]*domain.SuccessScoreHistory, error)
+	FindScoreDrift(ctx context.Context, baseScore int) ([]*ScoreDrift, error)
+	CountUsers(ctx context.Context) (int, error)
+	// LockUser locks the user's row until the surrounding transaction ends,
+	// serialising score changes for that user.
+	LockUser(ctx context.Context, userID string) error
+	// RecordDrift writes an admin adjustment for the difference between the
+	// user's stored score and baseScore plus their history, if any.
+	RecordDrift(ctx context.Context, userID string, baseScore int, reason string) error
+	SumReference(ctx context.Context, userID, refType, refID string) (int, error)
+
+	GetRule(ctx context.Context, eventType domain.ScoreEventType) (*domain.ScoreRule, error)
+	ListRules(ctx context.Context) ([]*domain.ScoreRule, error)
+	SaveRule(ctx context.Context, rule *domain.ScoreRule, actorID string) error
+	SumEventSince(ctx context.Context, userID string, eventType domain.ScoreEventType, since time.Time) (int, error)
+	AggregateEvents(ctx context.Context, since, until time.Time) ([]*EventAggregate, error)
+}
+
+// UnitOfWork runs fn in one database transaction. Repository calls made
+// with the context passed to fn take part in it.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// ScoreDrift describes a user whose stored score no longer matches
+// the base score plus the sum of their history entries.
+type ScoreDrift struct {
+	UserID        string `json:"user_id"`
+	Username      string `json:"username"`
+	StoredScore   int    `json:"stored_score"`
+	ComputedScore int    `json:"computed_score"`
+	Drift         int    `json:"drift"`
+}
+
+type ReconcileReport struct {
+	UsersChecked int           `json:"users_checked"`
+	Drifted      []*ScoreDrift `json:"drifted"`
+	Fixed        bool          `json:"fixed"`
+}
+
+// EventAggregate is the number of score events and the points they produced
+// for one user, event type and calendar day.
+type EventAggregate struct {
+	UserID    string
+	Username  string
+	EventType domain.ScoreEventType
+	Day       time.Time
+	Count     int
+	Total     int
+}
+
+// DryRunRequest is a proposed rule set to replay against past events.
+// Rules missing from the proposal keep their current values.
+type DryRunRequest struct {
+	Rules []*domain.ScoreRule
+	Since time.Time
+	Until time.Time
+}
+
+type DryRunResult struct {
+	Since         time.Time           `json:"since"`
+	Until         time.Time           `json:"until"`
+	ActualTotal   int                 `json:"actual_total"`
+	ProposedTotal int                 `json:"proposed_total"`
+	Events        []*DryRunEventTotal `json:"events"`
+	Users         []*DryRunUserImpact `json:"users"`
+}
+
+type DryRunEventTotal struct {
+	EventType     domain.ScoreEventType `json:"event_type"`
+	Count         int                   `json:"count"`
+	ActualTotal   int                   `json:"actual_total"`
+	ProposedTotal int                   `json:"proposed_total"`
+}
+
+type DryRunUserImpact struct {
+	UserID        string `json:"user_id"`
+	Username      string `json:"username"`
+	ActualTotal   int    `json:"actual_total"`
+	ProposedTotal int    `json:"proposed_total"`
+	Delta         int    `json:"delta"`
 }
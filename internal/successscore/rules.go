@@ -0,0 +1,199 @@
+package successscore
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/yourusername/online-library/internal/domain"
+	"go.uber.org/zap"
+)
+
+var defaultAmounts = map[domain.ScoreEventType]int{
+	domain.ScoreEventReturnOnTime:   ScoreReturnOnTime,
+	domain.ScoreEventReturnLate:     ScoreReturnLate,
+	domain.ScoreEventPositiveReview: ScorePositiveReview,
+	domain.ScoreEventNegativeReview: ScoreNegativeReview,
+	domain.ScoreEventIdeaPosted:     ScoreIdeaPosted,
+	domain.ScoreEventIdeaUpvote:     ScoreIdeaUpvote,
+	domain.ScoreEventIdeaDownvote:   ScoreIdeaDownvote,
+	domain.ScoreEventLostBook:       ScoreLostBook,
+	domain.ScoreEventBookDonated:    ScoreBookDonated,
+	domain.ScoreEventMoneyDonated:   ScoreMoneyDonated,
+	domain.ScoreEventHandoverCancel: ScoreHandoverCancel,
+}
+
+// dryRunUserLimit bounds how many affected users a dry-run reports.
+const dryRunUserLimit = 50
+
+func (s *service) ListRules(ctx context.Context) ([]*domain.ScoreRule, error) {
+	rules, err := s.scoreRepo.ListRules(ctx)
+	if err != nil {
+		s.log.Error("failed to list score rules", zap.Error(err))
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (s *service) UpdateRule(ctx context.Context, actorID string, rule *domain.ScoreRule) (*domain.ScoreRule, error) {
+	if _, ok := defaultAmounts[rule.EventType]; !ok {
+		return nil, domain.ErrInvalidInput
+	}
+	if rule.DailyCap != nil && *rule.DailyCap <= 0 {
+		return nil, domain.ErrInvalidInput
+	}
+
+	rule.UpdatedBy = &actorID
+	rule.UpdatedAt = time.Now()
+	if err := s.scoreRepo.SaveRule(ctx, rule, actorID); err != nil {
+		s.log.Error("failed to save score rule", zap.String("event_type", string(rule.EventType)), zap.Error(err))
+		return nil, err
+	}
+
+	s.log.Info("score rule updated",
+		zap.String("event_type", string(rule.EventType)),
+		zap.Int("amount", rule.Amount),
+		zap.Bool("is_active", rule.IsActive),
+		zap.String("updated_by", actorID))
+	return rule, nil
+}
+
+// DryRun replays the events recorded in the requested window under the
+// proposed rules and reports how the totals would have differed.
+func (s *service) DryRun(ctx context.Context, req *DryRunRequest) (*DryRunResult, error) {
+	if req.Until.IsZero() {
+		req.Until = time.Now()
+	}
+	if req.Since.IsZero() {
+		req.Since = req.Until.AddDate(0, -1, 0)
+	}
+	if !req.Since.Before(req.Until) {
+		return nil, domain.ErrInvalidInput
+	}
+
+	current, err := s.scoreRepo.ListRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rules := make(map[domain.ScoreEventType]*domain.ScoreRule, len(current))
+	for _, r := range current {
+		rules[r.EventType] = r
+	}
+	for _, r := range req.Rules {
+		if _, ok := defaultAmounts[r.EventType]; !ok {
+			return nil, domain.ErrInvalidInput
+		}
+		rules[r.EventType] = r
+	}
+
+	aggregates, err := s.scoreRepo.AggregateEvents(ctx, req.Since, req.Until)
+	if err != nil {
+		s.log.Error("failed to aggregate score events", zap.Error(err))
+		return nil, err
+	}
+
+	result := &DryRunResult{Since: req.Since, Until: req.Until}
+	events := map[domain.ScoreEventType]*DryRunEventTotal{}
+	users := map[string]*DryRunUserImpact{}
+
+	for _, agg := range aggregates {
+		proposed := agg.Total
+		if rule, ok := rules[agg.EventType]; ok {
+			proposed = simulate(rule, agg.Count)
+		}
+
+		ev, ok := events[agg.EventType]
+		if !ok {
+			ev = &DryRunEventTotal{EventType: agg.EventType}
+			events[agg.EventType] = ev
+		}
+		ev.Count += agg.Count
+		ev.ActualTotal += agg.Total
+		ev.ProposedTotal += proposed
+
+		u, ok := users[agg.UserID]
+		if !ok {
+			u = &DryRunUserImpact{UserID: agg.UserID, Username: agg.Username}
+			users[agg.UserID] = u
+		}
+		u.ActualTotal += agg.Total
+		u.ProposedTotal += proposed
+
+		result.ActualTotal += agg.Total
+		result.ProposedTotal += proposed
+	}
+
+	result.Events = make([]*DryRunEventTotal, 0, len(events))
+	for _, ev := range events {
+		result.Events = append(result.Events, ev)
+	}
+	sort.Slice(result.Events, func(i, j int) bool {
+		return result.Events[i].EventType < result.Events[j].EventType
+	})
+
+	result.Users = []*DryRunUserImpact{}
+	for _, u := range users {
+		u.Delta = u.ProposedTotal - u.ActualTotal
+		if u.Delta != 0 {
+			result.Users = append(result.Users, u)
+		}
+	}
+	sort.Slice(result.Users, func(i, j int) bool {
+		return abs(result.Users[i].Delta) > abs(result.Users[j].Delta)
+	})
+	if len(result.Users) > dryRunUserLimit {
+		result.Users = result.Users[:dryRunUserLimit]
+	}
+
+	return result, nil
+}
+
+// simulate returns the points a user would have earned from count events
+// of one type on a single day under the given rule.
+func simulate(rule *domain.ScoreRule, count int) int {
+	if !rule.IsActive {
+		return 0
+	}
+	earned := 0
+	for i := 0; i < count; i++ {
+		amount := rule.Amount
+		if rule.DailyCap != nil {
+			amount = capAmount(amount, earned, *rule.DailyCap)
+		}
+		if amount == 0 {
+			break
+		}
+		earned += amount
+	}
+	return earned
+}
+
+// capAmount trims amount so that the absolute points earned from an event
+// in the current day never exceed limit.
+func capAmount(amount, earned, limit int) int {
+	remaining := limit - abs(earned)
+	if remaining <= 0 {
+		return 0
+	}
+	if abs(amount) > remaining {
+		if amount < 0 {
+			return -remaining
+		}
+		return remaining
+	}
+	return amount
+}
+
+// startOfDay is midnight UTC today, the boundary daily caps reset at
+// regardless of the server's time zone.
+func startOfDay() time.Time {
+	now := time.Now().UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -2,10 +2,18 @@ package successscore
 
 import (
 	"context"
+	"errors"
 
+	"github.com/yourusername/online-library/internal/domain"
 	"go.uber.org/zap"
 )
 
+// BaseScore is the score every user starts with; a user's current score
+// is always BaseScore plus the sum of their history entries.
+const BaseScore = 100
+
+// Default point values, used to seed success_score_rules and as a
+// fallback when a rule row is missing.
 const (
 	ScoreReturnOnTime   = 10
 	ScoreReturnLate     = -15
@@ -17,60 +25,270 @@ const (
 	ScoreLostBook       = -50
 	ScoreBookDonated    = 20
 	ScoreMoneyDonated   = 10
+	ScoreHandoverCancel = -10
 )
 
 type service struct {
 	scoreRepo ScoreRepo
+	uow       UnitOfWork
 	log       *zap.Logger
 }
 
-func NewService(scoreRepo ScoreRepo, log *zap.Logger) Service {
+func NewService(scoreRepo ScoreRepo, uow UnitOfWork, log *zap.Logger) Service {
 	return &service{
 		scoreRepo: scoreRepo,
+		uow:       uow,
 		log:       log,
 	}
 }
 
 func (s *service) ProcessIdeaPosted(ctx context.Context, userID, ideaID string) error {
-	return s.scoreRepo.UpdateScore(ctx, userID, ScoreIdeaPosted, "Posted reading idea", "idea", &ideaID)
+	return s.apply(ctx, userID, domain.ScoreEventIdeaPosted, "Posted reading idea", "idea", &ideaID)
 }
 
-func (s *service) ProcessIdeaUpvote(ctx context.Context, userID, ideaID string) error {
-	return s.scoreRepo.UpdateScore(ctx, userID, ScoreIdeaUpvote, "Idea received upvote", "idea", &ideaID)
+// ProcessIdeaUpvote credits the idea author and returns the points
+// actually awarded after the daily cap.
+func (s *service) ProcessIdeaUpvote(ctx context.Context, userID, ideaID string) (int, error) {
+	return s.award(ctx, userID, domain.ScoreEventIdeaUpvote, "Idea received upvote", "idea", &ideaID)
 }
 
-func (s *service) ProcessIdeaDownvote(ctx context.Context, userID, ideaID string) error {
-	return s.scoreRepo.UpdateScore(ctx, userID, ScoreIdeaDownvote, "Idea received downvote", "idea", &ideaID)
+// ProcessIdeaDownvote penalises the idea author and returns the points
+// actually deducted after the daily cap.
+func (s *service) ProcessIdeaDownvote(ctx context.Context, userID, ideaID string) (int, error) {
+	return s.award(ctx, userID, domain.ScoreEventIdeaDownvote, "Idea received downvote", "idea", &ideaID)
+}
+
+// RevertIdeaVote undoes the points a vote earned when it is changed or
+// retracted. The entry has an event type of its own: counted under the
+// vote's type it would use up the author's daily cap, not restore it, once
+// the vote was from an earlier day.
+func (s *service) RevertIdeaVote(ctx context.Context, userID, ideaID string, voteType domain.VoteType, points int) error {
+	if points == 0 {
+		return nil
+	}
+	reason := "Upvote on idea withdrawn"
+	if voteType == domain.VoteTypeDown {
+		reason = "Downvote on idea withdrawn"
+	}
+	return s.scoreRepo.UpdateScore(ctx, userID, -points, domain.ScoreEventVoteReverted, reason, "idea", &ideaID)
 }
 
 func (s *service) ProcessPositiveReview(ctx context.Context, userID, reviewID string) error {
-	return s.scoreRepo.UpdateScore(ctx, userID, ScorePositiveReview, "Received positive review", "review", &reviewID)
+	return s.apply(ctx, userID, domain.ScoreEventPositiveReview, "Received positive review", "review", &reviewID)
 }
 
 func (s *service) ProcessNegativeReview(ctx context.Context, userID, reviewID string) error {
-	return s.scoreRepo.UpdateScore(ctx, userID, ScoreNegativeReview, "Received negative review", "review", &reviewID)
+	return s.apply(ctx, userID, domain.ScoreEventNegativeReview, "Received negative review", "review", &reviewID)
 }
 
 func (s *service) ProcessBookDonation(ctx context.Context, userID, donationID string) error {
-	return s.scoreRepo.UpdateScore(ctx, userID, ScoreBookDonated, "Donated book", "donation", &donationID)
+	return s.apply(ctx, userID, domain.ScoreEventBookDonated, "Donated book", "donation", &donationID)
 }
 
 func (s *service) ProcessMoneyDonation(ctx context.Context, userID, donationID string) error {
-	return s.scoreRepo.UpdateScore(ctx, userID, ScoreMoneyDonated, "Made financial contribution", "donation", &donationID)
+	return s.apply(ctx, userID, domain.ScoreEventMoneyDonated, "Made financial contribution", "donation", &donationID)
 }
 
 func (s *service) ProcessReturnOnTime(ctx context.Context, userID, bookID string) error {
-	return s.scoreRepo.UpdateScore(ctx, userID, ScoreReturnOnTime, "Returned book on time", "book", &bookID)
+	return s.apply(ctx, userID, domain.ScoreEventReturnOnTime, "Returned book on time", "book", &bookID)
 }
 
 func (s *service) ProcessReturnLate(ctx context.Context, userID, bookID string) error {
-	return s.scoreRepo.UpdateScore(ctx, userID, ScoreReturnLate, "Returned book late", "book", &bookID)
+	return s.apply(ctx, userID, domain.ScoreEventReturnLate, "Returned book late", "book", &bookID)
 }
 
 func (s *service) ProcessLostBook(ctx context.Context, userID, bookID string) error {
-	return s.scoreRepo.UpdateScore(ctx, userID, ScoreLostBook, "Lost book", "book", &bookID)
+	return s.apply(ctx, userID, domain.ScoreEventLostBook, "Lost book", "book", &bookID)
+}
+
+// ProcessHandoverCancelled penalises the participant responsible for a
+// handover falling through
+func (s *service) ProcessHandoverCancelled(ctx context.Context, userID, threadID string) error {
+	return s.apply(ctx, userID, domain.ScoreEventHandoverCancel, "Handover cancelled", "handover", &threadID)
 }
 
 func (s *service) AdjustScore(ctx context.Context, userID string, amount int, reason, refType string, refID *string) error {
-	return s.scoreRepo.UpdateScore(ctx, userID, amount, reason, refType, refID)
+	return s.scoreRepo.UpdateScore(ctx, userID, amount, domain.ScoreEventAdminAdjustment, reason, refType, refID)
+}
+
+// RevertReference cancels the net effect every earlier entry for a
+// reference had on a user's score by writing a single compensating entry.
+// It returns the amount written.
+func (s *service) RevertReference(ctx context.Context, userID, refType, refID, reason string) (int, error) {
+	net, err := s.scoreRepo.SumReference(ctx, userID, refType, refID)
+	if err != nil {
+		return 0, err
+	}
+	if net == 0 {
+		return 0, nil
+	}
+	if err := s.scoreRepo.UpdateScore(ctx, userID, -net, domain.ScoreEventAdminAdjustment, reason, refType, &refID); err != nil {
+		return 0, err
+	}
+	return -net, nil
+}
+
+// apply awards the points configured for an event, honouring the rule's
+// active flag and daily cap.
+func (s *service) apply(ctx context.Context, userID string, event domain.ScoreEventType, reason, refType string, refID *string) error {
+	_, err := s.award(ctx, userID, event, reason, refType, refID)
+	return err
+}
+
+// award is apply that also reports the points recorded.
+func (s *service) award(ctx context.Context, userID string, event domain.ScoreEventType, reason, refType string, refID *string) (int, error) {
+	rule, err := s.rule(ctx, event)
+	if err != nil {
+		return 0, err
+	}
+
+	if !rule.IsActive {
+		s.log.Debug("score rule inactive, skipping", zap.String("event_type", string(event)))
+		return 0, nil
+	}
+
+	if rule.Amount == 0 {
+		return 0, nil
+	}
+
+	if rule.DailyCap == nil {
+		if err := s.scoreRepo.UpdateScore(ctx, userID, rule.Amount, event, reason, refType, refID); err != nil {
+			return 0, err
+		}
+		return rule.Amount, nil
+	}
+
+	// The user's row stays locked from reading today's total until the
+	// award is written, so concurrent awards can't both pass the cap.
+	var amount int
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.scoreRepo.LockUser(ctx, userID); err != nil {
+			return err
+		}
+		earned, err := s.scoreRepo.SumEventSince(ctx, userID, event, startOfDay())
+		if err != nil {
+			return err
+		}
+		amount = capAmount(rule.Amount, earned, *rule.DailyCap)
+		if amount == 0 {
+			return nil
+		}
+		return s.scoreRepo.UpdateScore(ctx, userID, amount, event, reason, refType, refID)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if amount == 0 {
+		s.log.Info("daily score cap reached",
+			zap.String("user_id", userID),
+			zap.String("event_type", string(event)))
+	}
+	return amount, nil
+}
+
+// rule loads the configured rule for an event, falling back to the
+// compiled-in default when none has been stored.
+func (s *service) rule(ctx context.Context, event domain.ScoreEventType) (*domain.ScoreRule, error) {
+	rule, err := s.scoreRepo.GetRule(ctx, event)
+	if errors.Is(err, domain.ErrNotFound) {
+		amount, ok := defaultAmounts[event]
+		if !ok {
+			return nil, domain.ErrInvalidInput
+		}
+		return &domain.ScoreRule{EventType: event, Amount: amount, IsActive: true}, nil
+	}
+	if err != nil {
+		s.log.Error("failed to load score rule", zap.String("event_type", string(event)), zap.Error(err))
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (s *service) GetHistory(ctx context.Context, userID string, limit, offset int) ([]*domain.SuccessScoreHistory, error) {
+	history, err := s.scoreRepo.GetHistoryByUser(ctx, userID, limit, offset)
+	if err != nil {
+		s.log.Error("failed to get success score history", zap.String("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+	if history == nil {
+		history = []*domain.SuccessScoreHistory{}
+	}
+
+	for _, h := range history {
+		h.Link = referenceLink(h.ReferenceType, h.ReferenceID)
+	}
+	return history, nil
+}
+
+func (s *service) Reconcile(ctx context.Context, fix bool) (*ReconcileReport, error) {
+	checked, err := s.scoreRepo.CountUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	drifted, err := s.scoreRepo.FindScoreDrift(ctx, BaseScore)
+	if err != nil {
+		s.log.Error("failed to compute success score drift", zap.Error(err))
+		return nil, err
+	}
+
+	report := &ReconcileReport{UsersChecked: checked, Drifted: drifted}
+	if drifted == nil {
+		report.Drifted = []*ScoreDrift{}
+	}
+
+	for _, d := range drifted {
+		s.log.Warn("success score drift detected",
+			zap.String("user_id", d.UserID),
+			zap.Int("stored", d.StoredScore),
+			zap.Int("computed", d.ComputedScore))
+	}
+
+	// The stored score is what users have seen, so rather than moving it
+	// the fix records the missing change in history. Each user is locked
+	// and their drift recomputed, so concurrent awards or a second run
+	// can't record it twice.
+	if fix {
+		for _, d := range drifted {
+			err := s.uow.Do(ctx, func(ctx context.Context) error {
+				if err := s.scoreRepo.LockUser(ctx, d.UserID); err != nil {
+					return err
+				}
+				return s.scoreRepo.RecordDrift(ctx, d.UserID, BaseScore, "Score reconciled with history")
+			})
+			if err != nil {
+				s.log.Error("failed to fix success score", zap.String("user_id", d.UserID), zap.Error(err))
+				return report, err
+			}
+		}
+		report.Fixed = true
+	}
+
+	s.log.Info("success score reconciliation finished",
+		zap.Int("users_checked", checked),
+		zap.Int("drifted", len(drifted)),
+		zap.Bool("fixed", report.Fixed))
+	return report, nil
+}
+
+// referenceLink maps a history entry's reference to the client route
+// where the referenced entity can be viewed.
+func referenceLink(refType string, refID *string) string {
+	if refID == nil || *refID == "" {
+		return ""
+	}
+	switch refType {
+	case "book":
+		return "/books/" + *refID
+	case "idea":
+		return "/ideas/" + *refID
+	case "review":
+		return "/reviews/" + *refID
+	case "donation":
+		return "/donations/" + *refID
+	case "handover":
+		return "/handover/threads/" + *refID
+	}
+	return ""
 }
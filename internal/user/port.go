@@ -3,6 +3,7 @@ package user
 import (
 	"context"
 
+	"github.com/yourusername/online-library/internal/content"
 	"github.com/yourusername/online-library/internal/domain"
 )
 
@@ -19,3 +20,11 @@ type UserRepo interface {
 	AddInterests(ctx context.Context, userID string, interests []string) error
 	GetTopUsers(ctx context.Context, limit int) ([]*domain.User, error)
 }
+
+type NotificationSvc interface {
+	NotifyMention(ctx context.Context, userID, authorID string, source domain.MentionSource) error
+}
+
+type ContentSvc interface {
+	Render(ctx context.Context, source string, maxLen int) (*content.Rendered, error)
+}
@@ -4,19 +4,24 @@ import (
 	"context"
 	"time"
 
+	"github.com/yourusername/online-library/internal/content"
 	"github.com/yourusername/online-library/internal/domain"
 	"go.uber.org/zap"
 )
 
 type service struct {
-	userRepo UserRepo
-	log      *zap.Logger
+	userRepo        UserRepo
+	contentSvc      ContentSvc
+	notificationSvc NotificationSvc
+	log             *zap.Logger
 }
 
-func NewService(userRepo UserRepo, log *zap.Logger) Service {
+func NewService(userRepo UserRepo, contentSvc ContentSvc, notificationSvc NotificationSvc, log *zap.Logger) Service {
 	return &service{
-		userRepo: userRepo,
-		log:      log,
+		userRepo:        userRepo,
+		contentSvc:      contentSvc,
+		notificationSvc: notificationSvc,
+		log:             log,
 	}
 }
 
@@ -30,11 +35,32 @@ func (s *service) GetProfile(ctx context.Context, userID string) (*domain.User,
 }
 
 func (s *service) UpdateProfile(ctx context.Context, userID string, user *domain.User) (*domain.User, error) {
+	bio, err := s.contentSvc.Render(ctx, user.Bio, content.MaxBio)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	user.Bio = bio.Source
+	user.BioHTML = bio.HTML
 	user.UpdatedAt = time.Now()
 	if err := s.userRepo.Update(ctx, userID, user); err != nil {
 		s.log.Error("failed to update user profile", zap.String("user_id", userID), zap.Error(err))
 		return nil, err
 	}
+
+	// Only users the bio didn't mention before hear about it
+	source := domain.MentionSource{Type: domain.MentionInBio, ID: userID}
+	for _, m := range content.NewMentions(bio.Mentions, previous.Bio) {
+		if m.UserID == userID {
+			continue
+		}
+		if err := s.notificationSvc.NotifyMention(ctx, m.UserID, userID, source); err != nil {
+			s.log.Warn("failed to notify mentioned user", zap.String("user_id", m.UserID), zap.Error(err))
+		}
+	}
 	s.log.Info("user profile updated", zap.String("user_id", userID))
 	return user, nil
 }
@@ -0,0 +1,56 @@
+-- +goose Up
+-- Configurable success score rules, replacing the hard-coded point values
+
+CREATE TABLE IF NOT EXISTS success_score_rules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    event_type VARCHAR(50) UNIQUE NOT NULL,
+    amount INTEGER NOT NULL,
+    daily_cap INTEGER CHECK (daily_cap IS NULL OR daily_cap > 0),
+    is_active BOOLEAN DEFAULT TRUE,
+    description TEXT,
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO success_score_rules (event_type, amount, description) VALUES
+    ('return_on_time', 10, 'Returned book on time'),
+    ('return_late', -15, 'Returned book late'),
+    ('positive_review', 5, 'Received positive review'),
+    ('negative_review', -10, 'Received negative review'),
+    ('idea_posted', 3, 'Posted reading idea'),
+    ('idea_upvote', 1, 'Idea received upvote'),
+    ('idea_downvote', -1, 'Idea received downvote'),
+    ('lost_book', -50, 'Lost book'),
+    ('book_donated', 20, 'Donated book'),
+    ('money_donated', 10, 'Made financial contribution')
+ON CONFLICT (event_type) DO NOTHING;
+
+CREATE TRIGGER update_success_score_rules_updated_at BEFORE UPDATE ON success_score_rules
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Record which rule produced each history entry so caps and dry-runs can group by it
+ALTER TABLE success_score_history
+ADD COLUMN IF NOT EXISTS event_type VARCHAR(50);
+
+UPDATE success_score_history SET event_type = CASE reason
+    WHEN 'Returned book on time' THEN 'return_on_time'
+    WHEN 'Returned book late' THEN 'return_late'
+    WHEN 'Received positive review' THEN 'positive_review'
+    WHEN 'Received negative review' THEN 'negative_review'
+    WHEN 'Posted reading idea' THEN 'idea_posted'
+    WHEN 'Idea received upvote' THEN 'idea_upvote'
+    WHEN 'Idea received downvote' THEN 'idea_downvote'
+    WHEN 'Lost book' THEN 'lost_book'
+    WHEN 'Donated book' THEN 'book_donated'
+    WHEN 'Made financial contribution' THEN 'money_donated'
+    ELSE 'admin_adjustment'
+END
+WHERE event_type IS NULL;
+
+CREATE INDEX idx_success_score_history_event ON success_score_history(user_id, event_type, created_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_success_score_history_event;
+ALTER TABLE success_score_history DROP COLUMN IF EXISTS event_type;
+DROP TABLE IF EXISTS success_score_rules CASCADE;
@@ -0,0 +1,31 @@
+-- +goose Up
+-- Points awarded to the idea author for each vote, so a changed or
+-- retracted vote can reverse exactly what it earned
+ALTER TABLE idea_votes ADD COLUMN score_awarded INTEGER NOT NULL DEFAULT 0;
+
+-- Limit how much score a user can farm from ideas in a single day
+UPDATE success_score_rules SET daily_cap = 9 WHERE event_type = 'idea_posted' AND daily_cap IS NULL;
+UPDATE success_score_rules SET daily_cap = 10 WHERE event_type = 'idea_upvote' AND daily_cap IS NULL;
+UPDATE success_score_rules SET daily_cap = 10 WHERE event_type = 'idea_downvote' AND daily_cap IS NULL;
+
+-- Groups of accounts that repeatedly upvote each other's ideas
+CREATE TABLE IF NOT EXISTS vote_rings (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    ring_key TEXT NOT NULL,
+    member_ids UUID[] NOT NULL,
+    vote_count INTEGER NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'confirmed', 'dismissed')),
+    detected_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Only one open flag per member set; re-detection refreshes it
+CREATE UNIQUE INDEX idx_vote_rings_pending_key ON vote_rings(ring_key) WHERE status = 'pending';
+CREATE INDEX idx_vote_rings_status ON vote_rings(status, detected_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS vote_rings CASCADE;
+UPDATE success_score_rules SET daily_cap = NULL WHERE event_type IN ('idea_posted', 'idea_upvote', 'idea_downvote');
+ALTER TABLE idea_votes DROP COLUMN IF EXISTS score_awarded;
@@ -0,0 +1,42 @@
+-- +goose Up
+-- Reviews are tied to the completed handover they describe
+ALTER TABLE user_reviews
+ADD COLUMN IF NOT EXISTS handover_thread_id UUID REFERENCES handover_threads(id) ON DELETE SET NULL,
+ADD COLUMN IF NOT EXISTS reviewer_weight NUMERIC(4,2) NOT NULL DEFAULT 1.0;
+
+-- One review per side per handover
+CREATE UNIQUE INDEX idx_user_reviews_thread_reviewer
+    ON user_reviews(handover_thread_id, reviewer_id)
+    WHERE handover_thread_id IS NOT NULL;
+
+-- Weighted rating averages shown on the profile
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS avg_behavior_rating NUMERIC(3,2),
+ADD COLUMN IF NOT EXISTS avg_book_condition_rating NUMERIC(3,2),
+ADD COLUMN IF NOT EXISTS avg_communication_rating NUMERIC(3,2);
+
+UPDATE users u SET
+    reviews_received = s.total,
+    avg_behavior_rating = s.behavior,
+    avg_book_condition_rating = s.book_condition,
+    avg_communication_rating = s.communication
+FROM (
+    SELECT reviewee_id,
+           COUNT(*) AS total,
+           SUM(behavior_rating * reviewer_weight) / NULLIF(SUM(reviewer_weight) FILTER (WHERE behavior_rating IS NOT NULL), 0) AS behavior,
+           SUM(book_condition_rating * reviewer_weight) / NULLIF(SUM(reviewer_weight) FILTER (WHERE book_condition_rating IS NOT NULL), 0) AS book_condition,
+           SUM(communication_rating * reviewer_weight) / NULLIF(SUM(reviewer_weight) FILTER (WHERE communication_rating IS NOT NULL), 0) AS communication
+    FROM user_reviews
+    GROUP BY reviewee_id
+) s
+WHERE u.id = s.reviewee_id;
+
+-- +goose Down
+ALTER TABLE users
+DROP COLUMN IF EXISTS avg_behavior_rating,
+DROP COLUMN IF EXISTS avg_book_condition_rating,
+DROP COLUMN IF EXISTS avg_communication_rating;
+DROP INDEX IF EXISTS idx_user_reviews_thread_reviewer;
+ALTER TABLE user_reviews
+DROP COLUMN IF EXISTS handover_thread_id,
+DROP COLUMN IF EXISTS reviewer_weight;
@@ -0,0 +1,27 @@
+-- +goose Up
+-- Removed reviews are hidden rather than deleted so disputes keep their context
+ALTER TABLE user_reviews
+ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'visible' CHECK (status IN ('visible', 'removed')),
+ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS review_disputes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    review_id UUID NOT NULL REFERENCES user_reviews(id) ON DELETE CASCADE,
+    disputer_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    statement TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'upheld', 'edited', 'removed')),
+    resolution_note TEXT,
+    resolved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    resolved_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- A review can only have one open dispute at a time
+CREATE UNIQUE INDEX idx_review_disputes_pending ON review_disputes(review_id) WHERE status = 'pending';
+CREATE INDEX idx_review_disputes_status ON review_disputes(status, created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS review_disputes CASCADE;
+ALTER TABLE user_reviews
+DROP COLUMN IF EXISTS status,
+DROP COLUMN IF EXISTS updated_at;
@@ -0,0 +1,45 @@
+-- +goose Up
+-- Publish handover chat activity on handover_thread_<id> so streaming
+-- clients connected to any replica are told about it
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION notify_handover_message()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify(
+        'handover_thread_' || NEW.thread_id::text,
+        json_build_object('type', 'message', 'id', NEW.id)::text
+    );
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION notify_handover_status()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify(
+        'handover_thread_' || NEW.id::text,
+        json_build_object('type', 'status', 'status', NEW.status)::text
+    );
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+-- +goose StatementEnd
+
+CREATE TRIGGER handover_message_notify AFTER INSERT ON handover_messages
+    FOR EACH ROW EXECUTE FUNCTION notify_handover_message();
+
+CREATE TRIGGER handover_status_notify AFTER UPDATE OF status ON handover_threads
+    FOR EACH ROW WHEN (OLD.status IS DISTINCT FROM NEW.status)
+    EXECUTE FUNCTION notify_handover_status();
+
+CREATE INDEX IF NOT EXISTS idx_handover_messages_thread_created ON handover_messages(thread_id, created_at, id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_handover_messages_thread_created;
+DROP TRIGGER IF EXISTS handover_status_notify ON handover_threads;
+DROP TRIGGER IF EXISTS handover_message_notify ON handover_messages;
+DROP FUNCTION IF EXISTS notify_handover_status();
+DROP FUNCTION IF EXISTS notify_handover_message();
@@ -0,0 +1,33 @@
+-- +goose Up
+ALTER TABLE notification_settings
+ADD COLUMN IF NOT EXISTS digest_frequency VARCHAR(10) NOT NULL DEFAULT 'off' CHECK (digest_frequency IN ('off', 'daily', 'weekly'));
+
+-- One row per sent digest; the unique period key keeps the job idempotent
+CREATE TABLE IF NOT EXISTS notification_digests (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    frequency VARCHAR(10) NOT NULL CHECK (frequency IN ('daily', 'weekly')),
+    period_key VARCHAR(20) NOT NULL,
+    item_count INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, period_key)
+);
+
+-- Low-priority notifications waiting for a digest; digest_id is set once sent
+CREATE TABLE IF NOT EXISTS notification_digest_items (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    digest_id UUID REFERENCES notification_digests(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    message TEXT NOT NULL,
+    link TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_notification_digest_items_pending ON notification_digest_items(user_id, created_at) WHERE digest_id IS NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS notification_digest_items CASCADE;
+DROP TABLE IF EXISTS notification_digests CASCADE;
+ALTER TABLE notification_settings DROP COLUMN IF EXISTS digest_frequency;
@@ -0,0 +1,15 @@
+-- +goose Up
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS preferred_locale VARCHAR(5) NOT NULL DEFAULT 'en' CHECK (preferred_locale IN ('en', 'bn'));
+
+-- System messages keep their catalog key and parameters so each viewer sees
+-- them in their own language; message holds the English text as a fallback
+ALTER TABLE handover_messages
+ADD COLUMN IF NOT EXISTS message_key VARCHAR(100),
+ADD COLUMN IF NOT EXISTS message_params JSONB;
+
+-- +goose Down
+ALTER TABLE handover_messages
+DROP COLUMN IF EXISTS message_key,
+DROP COLUMN IF EXISTS message_params;
+ALTER TABLE users DROP COLUMN IF EXISTS preferred_locale;
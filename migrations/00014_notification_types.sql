@@ -0,0 +1,46 @@
+-- +goose Up
+-- Rows written before payloads existed keep version 1 and an empty payload
+ALTER TABLE notifications
+ADD COLUMN IF NOT EXISTS version SMALLINT NOT NULL DEFAULT 1,
+ADD COLUMN IF NOT EXISTS payload JSONB NOT NULL DEFAULT '{}',
+ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP;
+
+-- Fold the legacy free-form types into the closed set before enforcing it
+UPDATE notifications SET type = 'return_due' WHERE type = 'book_due';
+UPDATE notifications SET type = 'review_received' WHERE type = 'review';
+UPDATE notifications SET type = 'idea_vote' WHERE type = 'idea';
+-- Anything else, such as the old donation type, becomes a 'system'
+-- notification so no one's history is lost
+UPDATE notifications SET type = 'system'
+WHERE type NOT IN (
+    'idea_vote', 'review_received', 'review_dispute_resolved', 'book_available', 'request_approved',
+    'return_due', 'book_in_transit', 'book_delivered', 'handover_thread', 'handover_message'
+);
+
+-- Preferences follow the same renames unless the user already has one for
+-- the new type. Preferences for types that no longer exist are kept as
+-- they are; nothing reads them.
+UPDATE notification_preferences p SET type = m.new_type
+FROM (VALUES ('book_due', 'return_due'), ('review', 'review_received'), ('idea', 'idea_vote')) AS m(old_type, new_type)
+WHERE p.type = m.old_type
+  AND NOT EXISTS (
+    SELECT 1 FROM notification_preferences q
+    WHERE q.user_id = p.user_id AND q.channel = p.channel AND q.type = m.new_type
+  );
+
+ALTER TABLE notifications
+ADD CONSTRAINT notifications_type_check CHECK (type IN (
+    'idea_vote', 'review_received', 'review_dispute_resolved', 'book_available', 'request_approved',
+    'return_due', 'book_in_transit', 'book_delivered', 'handover_thread', 'handover_message', 'system'
+));
+
+-- Retention prunes read notifications by age
+CREATE INDEX IF NOT EXISTS idx_notifications_read_created ON notifications(created_at) WHERE is_read = TRUE;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_notifications_read_created;
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_type_check;
+ALTER TABLE notifications
+DROP COLUMN IF EXISTS archived_at,
+DROP COLUMN IF EXISTS payload,
+DROP COLUMN IF EXISTS version;
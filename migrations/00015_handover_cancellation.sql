@@ -0,0 +1,38 @@
+-- +goose Up
+-- Who called a handover off, why, and who was held responsible
+ALTER TABLE handover_threads
+ADD COLUMN IF NOT EXISTS cancelled_by UUID REFERENCES users(id) ON DELETE SET NULL,
+ADD COLUMN IF NOT EXISTS cancel_reason VARCHAR(20) CHECK (cancel_reason IN ('withdrawn', 'unresponsive', 'other')),
+ADD COLUMN IF NOT EXISTS cancel_note TEXT,
+ADD COLUMN IF NOT EXISTS at_fault_id UUID REFERENCES users(id) ON DELETE SET NULL,
+ADD COLUMN IF NOT EXISTS cancelled_at TIMESTAMP;
+
+INSERT INTO success_score_rules (event_type, amount, description) VALUES
+    ('handover_cancelled', -10, 'Handover cancelled')
+ON CONFLICT (event_type) DO NOTHING;
+
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_type_check;
+ALTER TABLE notifications
+ADD CONSTRAINT notifications_type_check CHECK (type IN (
+    'idea_vote', 'review_received', 'review_dispute_resolved', 'book_available', 'request_approved',
+    'return_due', 'book_in_transit', 'book_delivered', 'handover_thread', 'handover_message', 'system',
+    'handover_cancelled'
+));
+
+-- +goose Down
+DELETE FROM notifications WHERE type = 'handover_cancelled';
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_type_check;
+ALTER TABLE notifications
+ADD CONSTRAINT notifications_type_check CHECK (type IN (
+    'idea_vote', 'review_received', 'review_dispute_resolved', 'book_available', 'request_approved',
+    'return_due', 'book_in_transit', 'book_delivered', 'handover_thread', 'handover_message', 'system'
+));
+
+DELETE FROM success_score_rules WHERE event_type = 'handover_cancelled';
+
+ALTER TABLE handover_threads
+DROP COLUMN IF EXISTS cancelled_at,
+DROP COLUMN IF EXISTS at_fault_id,
+DROP COLUMN IF EXISTS cancel_note,
+DROP COLUMN IF EXISTS cancel_reason,
+DROP COLUMN IF EXISTS cancelled_by;
@@ -0,0 +1,26 @@
+-- +goose Up
+-- Every book status change, written by the lifecycle state machine
+CREATE TABLE IF NOT EXISTS book_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    book_id UUID NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    action VARCHAR(30) NOT NULL CHECK (action IN (
+        'created', 'approved', 'delivered', 'completed', 'returned', 'handover_cancelled', 'admin_override'
+    )),
+    from_status VARCHAR(20),
+    to_status VARCHAR(20) NOT NULL,
+    actor VARCHAR(20) NOT NULL CHECK (actor IN ('admin', 'holder', 'reader', 'system')),
+    actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    holder_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    note TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_book_events_book_created ON book_events(book_id, created_at);
+
+-- Existing books start their log at creation
+INSERT INTO book_events (book_id, action, to_status, actor, actor_id, created_at)
+SELECT id, 'created', 'available', CASE WHEN created_by IS NULL THEN 'system' ELSE 'holder' END, created_by, created_at
+FROM books;
+
+-- +goose Down
+DROP TABLE IF EXISTS book_events;
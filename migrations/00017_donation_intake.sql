@@ -0,0 +1,48 @@
+-- +goose Up
+-- Book donations are pledged, received and then catalogued as a book
+ALTER TABLE donations
+ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'confirmed'
+    CHECK (status IN ('pledged', 'received', 'catalogued', 'cancelled', 'confirmed')),
+ADD COLUMN IF NOT EXISTS pledge JSONB,
+ADD COLUMN IF NOT EXISTS received_by UUID REFERENCES users(id) ON DELETE SET NULL,
+ADD COLUMN IF NOT EXISTS received_at TIMESTAMP,
+ADD COLUMN IF NOT EXISTS catalogued_at TIMESTAMP,
+ADD COLUMN IF NOT EXISTS cancel_reason TEXT,
+ADD COLUMN IF NOT EXISTS scored_at TIMESTAMP;
+
+-- Book donations linked to a book count as catalogued; the rest are still
+-- waiting for the book to arrive
+UPDATE donations
+SET status = CASE WHEN book_id IS NULL THEN 'pledged' ELSE 'catalogued' END,
+    received_at = CASE WHEN book_id IS NULL THEN NULL ELSE created_at END,
+    catalogued_at = CASE WHEN book_id IS NULL THEN NULL ELSE created_at END
+WHERE donation_type = 'book';
+
+-- Points were awarded on creation before this change; don't award them twice
+UPDATE donations d
+SET scored_at = d.created_at
+WHERE EXISTS (
+    SELECT 1 FROM success_score_history h
+    WHERE h.reference_type = 'donation' AND h.reference_id = d.id
+);
+
+UPDATE books b
+SET donated_by = d.donor_id, is_donated = TRUE, donation_date = d.created_at
+FROM donations d
+WHERE d.book_id = b.id AND d.donation_type = 'book' AND b.donated_by IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_donations_status ON donations(donation_type, status, created_at);
+CREATE INDEX IF NOT EXISTS idx_donations_donor ON donations(donor_id, created_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_donations_donor;
+DROP INDEX IF EXISTS idx_donations_status;
+
+ALTER TABLE donations
+DROP COLUMN IF EXISTS scored_at,
+DROP COLUMN IF EXISTS cancel_reason,
+DROP COLUMN IF EXISTS catalogued_at,
+DROP COLUMN IF EXISTS received_at,
+DROP COLUMN IF EXISTS received_by,
+DROP COLUMN IF EXISTS pledge,
+DROP COLUMN IF EXISTS status;
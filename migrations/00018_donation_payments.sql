@@ -0,0 +1,39 @@
+-- +goose Up
+-- Money donations are paid through a payment provider: pending until its
+-- webhook confirms or fails the payment, and refundable afterwards
+ALTER TABLE donations
+ADD COLUMN IF NOT EXISTS payment_provider VARCHAR(30),
+ADD COLUMN IF NOT EXISTS payment_session_id VARCHAR(255),
+ADD COLUMN IF NOT EXISTS payment_ref VARCHAR(255),
+ADD COLUMN IF NOT EXISTS confirmed_at TIMESTAMP,
+ADD COLUMN IF NOT EXISTS refunded_at TIMESTAMP,
+ADD COLUMN IF NOT EXISTS refund_id VARCHAR(255);
+
+ALTER TABLE donations DROP CONSTRAINT IF EXISTS donations_status_check;
+ALTER TABLE donations ADD CONSTRAINT donations_status_check
+    CHECK (status IN ('pledged', 'received', 'catalogued', 'cancelled', 'pending', 'confirmed', 'failed', 'refunded'));
+
+-- Self-reported money donations made before payments count as confirmed
+UPDATE donations SET confirmed_at = created_at
+WHERE donation_type = 'money' AND status = 'confirmed' AND confirmed_at IS NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_donations_payment_session
+    ON donations(payment_provider, payment_session_id)
+    WHERE payment_session_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_donations_payment_session;
+
+UPDATE donations SET status = 'cancelled' WHERE status IN ('pending', 'failed', 'refunded');
+
+ALTER TABLE donations DROP CONSTRAINT IF EXISTS donations_status_check;
+ALTER TABLE donations ADD CONSTRAINT donations_status_check
+    CHECK (status IN ('pledged', 'received', 'catalogued', 'cancelled', 'confirmed'));
+
+ALTER TABLE donations
+DROP COLUMN IF EXISTS refund_id,
+DROP COLUMN IF EXISTS refunded_at,
+DROP COLUMN IF EXISTS confirmed_at,
+DROP COLUMN IF EXISTS payment_ref,
+DROP COLUMN IF EXISTS payment_session_id,
+DROP COLUMN IF EXISTS payment_provider;
@@ -0,0 +1,51 @@
+-- +goose Up
+-- Donation drives with a money or book goal
+CREATE TABLE IF NOT EXISTS campaigns (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    goal_type VARCHAR(20) NOT NULL CHECK (goal_type IN ('book', 'money')),
+    goal_amount DECIMAL(12, 2),
+    goal_books INTEGER,
+    currency VARCHAR(10),
+    category VARCHAR(100),
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK (ends_at > starts_at),
+    CHECK (
+        (goal_type = 'money' AND goal_amount > 0 AND goal_books IS NULL AND currency IS NOT NULL) OR
+        (goal_type = 'book' AND goal_books > 0 AND goal_amount IS NULL)
+    )
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaigns_ends_at ON campaigns(ends_at);
+
+ALTER TABLE donations
+ADD COLUMN IF NOT EXISTS campaign_id UUID REFERENCES campaigns(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_donations_campaign ON donations(campaign_id) WHERE campaign_id IS NOT NULL;
+
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_type_check;
+ALTER TABLE notifications
+ADD CONSTRAINT notifications_type_check CHECK (type IN (
+    'idea_vote', 'review_received', 'review_dispute_resolved', 'book_available', 'request_approved',
+    'return_due', 'book_in_transit', 'book_delivered', 'handover_thread', 'handover_message', 'system',
+    'handover_cancelled', 'campaign_launched'
+));
+
+-- +goose Down
+DELETE FROM notifications WHERE type = 'campaign_launched';
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_type_check;
+ALTER TABLE notifications
+ADD CONSTRAINT notifications_type_check CHECK (type IN (
+    'idea_vote', 'review_received', 'review_dispute_resolved', 'book_available', 'request_approved',
+    'return_due', 'book_in_transit', 'book_delivered', 'handover_thread', 'handover_message', 'system',
+    'handover_cancelled'
+));
+
+DROP INDEX IF EXISTS idx_donations_campaign;
+ALTER TABLE donations DROP COLUMN IF EXISTS campaign_id;
+DROP TABLE IF EXISTS campaigns;
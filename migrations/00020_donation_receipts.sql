@@ -0,0 +1,62 @@
+-- +goose Up
+-- Gap-free counters. A number is taken by updating the row, which stays
+-- locked until the transaction ends, so a rollback gives it back.
+CREATE TABLE IF NOT EXISTS receipt_counters (
+    name VARCHAR(50) PRIMARY KEY,
+    last_value BIGINT NOT NULL DEFAULT 0
+);
+
+INSERT INTO receipt_counters (name, last_value) VALUES ('donation_receipt', 0)
+ON CONFLICT (name) DO NOTHING;
+
+-- Receipts keep a copy of the donation details as issued and outlive the
+-- donation and donor rows
+CREATE TABLE IF NOT EXISTS donation_receipts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    number BIGINT NOT NULL UNIQUE,
+    donation_id UUID UNIQUE REFERENCES donations(id) ON DELETE SET NULL,
+    donor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    donor_name VARCHAR(255) NOT NULL,
+    donation_type VARCHAR(20) NOT NULL CHECK (donation_type IN ('book', 'money')),
+    amount DECIMAL(10, 2),
+    currency VARCHAR(10),
+    book_title VARCHAR(500),
+    donated_at TIMESTAMP NOT NULL,
+    issued_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    regenerated_at TIMESTAMP,
+    voided_at TIMESTAMP,
+    void_reason TEXT,
+    pdf BYTEA
+);
+
+CREATE INDEX IF NOT EXISTS idx_donation_receipts_donor ON donation_receipts(donor_id, donated_at);
+
+CREATE TABLE IF NOT EXISTS donor_statements (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    donor_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    year INTEGER NOT NULL,
+    receipt_count INTEGER NOT NULL DEFAULT 0,
+    pdf BYTEA NOT NULL,
+    generated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (donor_id, year)
+);
+
+-- Fulfilled donations made before receipts get one, numbered in the order
+-- they were fulfilled; the PDF is rendered on first download
+INSERT INTO donation_receipts (number, donation_id, donor_id, donor_name, donation_type, amount, currency, book_title, donated_at)
+SELECT ROW_NUMBER() OVER (ORDER BY COALESCE(d.confirmed_at, d.catalogued_at, d.created_at), d.id),
+       d.id, d.donor_id, COALESCE(NULLIF(u.full_name, ''), u.username), d.donation_type, d.amount, d.currency,
+       COALESCE(b.title, d.pledge->>'title'), COALESCE(d.confirmed_at, d.catalogued_at, d.created_at)
+FROM donations d
+JOIN users u ON u.id = d.donor_id
+LEFT JOIN books b ON b.id = d.book_id
+WHERE d.status IN ('confirmed', 'catalogued');
+
+UPDATE receipt_counters
+SET last_value = (SELECT COALESCE(MAX(number), 0) FROM donation_receipts)
+WHERE name = 'donation_receipt';
+
+-- +goose Down
+DROP TABLE IF EXISTS donor_statements;
+DROP TABLE IF EXISTS donation_receipts;
+DROP TABLE IF EXISTS receipt_counters;
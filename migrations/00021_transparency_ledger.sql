@@ -0,0 +1,28 @@
+-- +goose Up
+-- Spending recorded by admins for the public ledger. Entries are voided,
+-- never deleted.
+CREATE TABLE IF NOT EXISTS ledger_outflows (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    amount DECIMAL(12, 2) NOT NULL CHECK (amount > 0),
+    currency VARCHAR(10) NOT NULL,
+    category VARCHAR(20) NOT NULL CHECK (category IN ('books', 'shipping', 'operations', 'events', 'other')),
+    description TEXT NOT NULL,
+    spent_on DATE NOT NULL,
+    campaign_id UUID REFERENCES campaigns(id) ON DELETE SET NULL,
+    recorded_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    voided_at TIMESTAMP,
+    voided_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    void_reason TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_outflows_spent_on ON ledger_outflows(spent_on);
+
+-- The wall and ledger read fulfilled donations by date
+CREATE INDEX IF NOT EXISTS idx_donations_fulfilled_at
+ON donations ((COALESCE(confirmed_at, catalogued_at, created_at)))
+WHERE status IN ('confirmed', 'catalogued');
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_donations_fulfilled_at;
+DROP TABLE IF EXISTS ledger_outflows;
@@ -0,0 +1,63 @@
+-- +goose Up
+-- The original CHECK allowed like/bookmark/priority while the API has
+-- always sent wishlist/favorite/reading, so inserts failed. Map the old
+-- values over. bookmark, priority and untyped rows all become wishlist,
+-- so they fold into one row per user and book first: the bookmark if
+-- there is one, carrying the highest priority of the group.
+ALTER TABLE user_bookmarks DROP CONSTRAINT IF EXISTS user_bookmarks_bookmark_type_check;
+
+UPDATE user_bookmarks b
+SET priority_level = m.priority_level
+FROM (
+    SELECT user_id, book_id, MAX(priority_level) AS priority_level
+    FROM user_bookmarks
+    WHERE bookmark_type IN ('bookmark', 'priority') OR bookmark_type IS NULL
+    GROUP BY user_id, book_id
+) m
+WHERE (b.bookmark_type IN ('bookmark', 'priority') OR b.bookmark_type IS NULL)
+  AND b.user_id = m.user_id AND b.book_id = m.book_id;
+
+DELETE FROM user_bookmarks b
+WHERE (b.bookmark_type IN ('bookmark', 'priority') OR b.bookmark_type IS NULL)
+  AND b.id <> (
+    SELECT k.id FROM user_bookmarks k
+    WHERE (k.bookmark_type IN ('bookmark', 'priority') OR k.bookmark_type IS NULL)
+      AND k.user_id = b.user_id AND k.book_id = b.book_id
+    ORDER BY CASE k.bookmark_type WHEN 'bookmark' THEN 0 WHEN 'priority' THEN 1 ELSE 2 END,
+             k.created_at, k.id
+    LIMIT 1
+  );
+
+UPDATE user_bookmarks SET bookmark_type = 'favorite' WHERE bookmark_type = 'like';
+UPDATE user_bookmarks SET bookmark_type = 'wishlist' WHERE bookmark_type IN ('bookmark', 'priority') OR bookmark_type IS NULL;
+UPDATE user_bookmarks SET priority_level = LEAST(GREATEST(COALESCE(priority_level, 0), 0), 5);
+
+ALTER TABLE user_bookmarks
+ALTER COLUMN bookmark_type SET NOT NULL,
+ALTER COLUMN priority_level SET NOT NULL,
+ADD CONSTRAINT user_bookmarks_bookmark_type_check CHECK (bookmark_type IN ('wishlist', 'favorite', 'reading')),
+ADD CONSTRAINT user_bookmarks_priority_level_check CHECK (priority_level BETWEEN 0 AND 5);
+
+-- Wishlist entries watch the book
+ALTER TABLE user_bookmarks
+ADD COLUMN IF NOT EXISTS auto_request BOOLEAN NOT NULL DEFAULT false,
+ADD COLUMN IF NOT EXISTS alerted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_user_bookmarks_watches ON user_bookmarks(book_id) WHERE bookmark_type = 'wishlist';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_user_bookmarks_watches;
+ALTER TABLE user_bookmarks
+DROP COLUMN IF EXISTS alerted_at,
+DROP COLUMN IF EXISTS auto_request,
+DROP CONSTRAINT IF EXISTS user_bookmarks_priority_level_check,
+DROP CONSTRAINT IF EXISTS user_bookmarks_bookmark_type_check,
+ALTER COLUMN priority_level DROP NOT NULL,
+ALTER COLUMN bookmark_type DROP NOT NULL;
+
+DELETE FROM user_bookmarks WHERE bookmark_type = 'reading';
+UPDATE user_bookmarks SET bookmark_type = 'like' WHERE bookmark_type = 'favorite';
+UPDATE user_bookmarks SET bookmark_type = 'bookmark' WHERE bookmark_type = 'wishlist';
+
+ALTER TABLE user_bookmarks
+ADD CONSTRAINT user_bookmarks_bookmark_type_check CHECK (bookmark_type IN ('like', 'bookmark', 'priority'));
@@ -0,0 +1,42 @@
+-- +goose Up
+-- Named reading lists
+CREATE TABLE IF NOT EXISTS shelves (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    visibility VARCHAR(10) NOT NULL DEFAULT 'private' CHECK (visibility IN ('private', 'link', 'public')),
+    share_token VARCHAR(64) NOT NULL UNIQUE,
+    cloned_from UUID REFERENCES shelves(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_shelves_owner ON shelves(owner_id);
+CREATE INDEX IF NOT EXISTS idx_shelves_public ON shelves(created_at) WHERE visibility = 'public';
+
+CREATE TABLE IF NOT EXISTS shelf_items (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    shelf_id UUID NOT NULL REFERENCES shelves(id) ON DELETE CASCADE,
+    book_id UUID NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    note TEXT,
+    added_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE(shelf_id, book_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_shelf_items_shelf ON shelf_items(shelf_id, position);
+
+CREATE TABLE IF NOT EXISTS shelf_follows (
+    shelf_id UUID NOT NULL REFERENCES shelves(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (shelf_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_shelf_follows_user ON shelf_follows(user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS shelf_follows;
+DROP TABLE IF EXISTS shelf_items;
+DROP TABLE IF EXISTS shelves;
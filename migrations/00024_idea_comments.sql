@@ -0,0 +1,38 @@
+-- +goose Up
+-- Threaded comments on reading ideas. Deleted comments are kept so their
+-- replies stay in place.
+CREATE TABLE IF NOT EXISTS idea_comments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    idea_id UUID NOT NULL REFERENCES reading_ideas(id) ON DELETE CASCADE,
+    parent_id UUID REFERENCES idea_comments(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    depth INTEGER NOT NULL DEFAULT 0 CHECK (depth >= 0),
+    content TEXT NOT NULL,
+    edited_at TIMESTAMP,
+    deleted_at TIMESTAMP,
+    deleted_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_idea_comments_idea ON idea_comments(idea_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_idea_comments_parent ON idea_comments(parent_id);
+
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_type_check;
+ALTER TABLE notifications
+ADD CONSTRAINT notifications_type_check CHECK (type IN (
+    'idea_vote', 'review_received', 'review_dispute_resolved', 'book_available', 'request_approved',
+    'return_due', 'book_in_transit', 'book_delivered', 'handover_thread', 'handover_message', 'system',
+    'handover_cancelled', 'campaign_launched', 'idea_comment'
+));
+
+-- +goose Down
+DELETE FROM notifications WHERE type = 'idea_comment';
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_type_check;
+ALTER TABLE notifications
+ADD CONSTRAINT notifications_type_check CHECK (type IN (
+    'idea_vote', 'review_received', 'review_dispute_resolved', 'book_available', 'request_approved',
+    'return_due', 'book_in_transit', 'book_delivered', 'handover_thread', 'handover_message', 'system',
+    'handover_cancelled', 'campaign_launched'
+));
+
+DROP TABLE IF EXISTS idea_comments;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- Earlier versions of edited ideas
+CREATE TABLE IF NOT EXISTS idea_revisions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    idea_id UUID NOT NULL REFERENCES reading_ideas(id) ON DELETE CASCADE,
+    title VARCHAR(500) NOT NULL,
+    content TEXT NOT NULL,
+    edited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_idea_revisions_idea ON idea_revisions(idea_id, created_at);
+
+ALTER TABLE reading_ideas ADD COLUMN IF NOT EXISTS edited_at TIMESTAMP;
+
+-- Global feed orderings
+CREATE INDEX IF NOT EXISTS idx_reading_ideas_created ON reading_ideas(created_at DESC);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_reading_ideas_created;
+ALTER TABLE reading_ideas DROP COLUMN IF EXISTS edited_at;
+DROP TABLE IF EXISTS idea_revisions;
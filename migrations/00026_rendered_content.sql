@@ -0,0 +1,50 @@
+-- +goose Up
+-- Sanitized HTML rendered from the Markdown source of user-written text.
+-- Rows written before this migration are rendered by a background job;
+-- the partial indexes keep its lookups cheap once they are done.
+ALTER TABLE reading_ideas ADD COLUMN IF NOT EXISTS content_html TEXT;
+ALTER TABLE idea_revisions ADD COLUMN IF NOT EXISTS content_html TEXT;
+ALTER TABLE idea_comments ADD COLUMN IF NOT EXISTS content_html TEXT;
+ALTER TABLE handover_messages ADD COLUMN IF NOT EXISTS message_html TEXT;
+ALTER TABLE user_reviews ADD COLUMN IF NOT EXISTS comment_html TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS bio_html TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_reading_ideas_unrendered ON reading_ideas(id) WHERE content_html IS NULL;
+CREATE INDEX IF NOT EXISTS idx_idea_revisions_unrendered ON idea_revisions(id) WHERE content_html IS NULL;
+CREATE INDEX IF NOT EXISTS idx_idea_comments_unrendered ON idea_comments(id) WHERE content_html IS NULL;
+CREATE INDEX IF NOT EXISTS idx_handover_messages_unrendered ON handover_messages(id)
+    WHERE message_html IS NULL AND is_system_message = false;
+CREATE INDEX IF NOT EXISTS idx_user_reviews_unrendered ON user_reviews(id) WHERE comment_html IS NULL;
+CREATE INDEX IF NOT EXISTS idx_users_unrendered ON users(id) WHERE bio_html IS NULL;
+
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_type_check;
+ALTER TABLE notifications
+ADD CONSTRAINT notifications_type_check CHECK (type IN (
+    'idea_vote', 'review_received', 'review_dispute_resolved', 'book_available', 'request_approved',
+    'return_due', 'book_in_transit', 'book_delivered', 'handover_thread', 'handover_message', 'system',
+    'handover_cancelled', 'campaign_launched', 'idea_comment', 'mention'
+));
+
+-- +goose Down
+DELETE FROM notifications WHERE type = 'mention';
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_type_check;
+ALTER TABLE notifications
+ADD CONSTRAINT notifications_type_check CHECK (type IN (
+    'idea_vote', 'review_received', 'review_dispute_resolved', 'book_available', 'request_approved',
+    'return_due', 'book_in_transit', 'book_delivered', 'handover_thread', 'handover_message', 'system',
+    'handover_cancelled', 'campaign_launched', 'idea_comment'
+));
+
+DROP INDEX IF EXISTS idx_users_unrendered;
+DROP INDEX IF EXISTS idx_user_reviews_unrendered;
+DROP INDEX IF EXISTS idx_handover_messages_unrendered;
+DROP INDEX IF EXISTS idx_idea_comments_unrendered;
+DROP INDEX IF EXISTS idx_idea_revisions_unrendered;
+DROP INDEX IF EXISTS idx_reading_ideas_unrendered;
+
+ALTER TABLE users DROP COLUMN IF EXISTS bio_html;
+ALTER TABLE user_reviews DROP COLUMN IF EXISTS comment_html;
+ALTER TABLE handover_messages DROP COLUMN IF EXISTS message_html;
+ALTER TABLE idea_comments DROP COLUMN IF EXISTS content_html;
+ALTER TABLE idea_revisions DROP COLUMN IF EXISTS content_html;
+ALTER TABLE reading_ideas DROP COLUMN IF EXISTS content_html;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- Vote reversals were recorded under the vote's own event type, where they
+-- counted towards the author's daily cap. They now have a type of their own.
+UPDATE success_score_history
+SET event_type = 'idea_vote_reverted'
+WHERE event_type IN ('idea_upvote', 'idea_downvote')
+  AND reason IN ('Upvote on idea withdrawn', 'Downvote on idea withdrawn');
+
+-- +goose Down
+UPDATE success_score_history
+SET event_type = CASE reason WHEN 'Upvote on idea withdrawn' THEN 'idea_upvote' ELSE 'idea_downvote' END
+WHERE event_type = 'idea_vote_reverted';
@@ -0,0 +1,18 @@
+-- +goose Up
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_type_check;
+ALTER TABLE notifications
+ADD CONSTRAINT notifications_type_check CHECK (type IN (
+    'idea_vote', 'review_received', 'review_dispute_resolved', 'book_available', 'request_approved',
+    'return_due', 'book_in_transit', 'book_delivered', 'handover_thread', 'handover_message', 'system',
+    'handover_cancelled', 'campaign_launched', 'idea_comment', 'mention', 'donation_received'
+));
+
+-- +goose Down
+DELETE FROM notifications WHERE type = 'donation_received';
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_type_check;
+ALTER TABLE notifications
+ADD CONSTRAINT notifications_type_check CHECK (type IN (
+    'idea_vote', 'review_received', 'review_dispute_resolved', 'book_available', 'request_approved',
+    'return_due', 'book_in_transit', 'book_delivered', 'handover_thread', 'handover_message', 'system',
+    'handover_cancelled', 'campaign_launched', 'idea_comment', 'mention'
+));